@@ -0,0 +1,38 @@
+package caches
+
+import (
+	"log"
+	"os"
+
+	"github.com/alvinmdj/mygram-api/helpers"
+)
+
+// Cache stores generated data (e.g. transformed images) by key, least recently used entries are evicted
+type Cache interface {
+	Get(key string) (data []byte, ok bool)
+	Set(key string, data []byte)
+}
+
+// NewCache creates the cache chosen by driver: "memory" (default) or "disk" (IMAGE_CACHE_DIR),
+// the size limit in bytes is read from IMAGE_CACHE_SIZE
+func NewCache(driver string) Cache {
+	maxBytes := int64(helpers.GetEnvInt("IMAGE_CACHE_SIZE", 64<<20)) // 64 MiB
+
+	switch driver {
+	case "disk":
+		dir := os.Getenv("IMAGE_CACHE_DIR")
+		if dir == "" {
+			dir = "cache/images"
+		}
+		cache, err := NewDiskCache(dir, maxBytes)
+		if err != nil {
+			log.Fatal("error creating image cache:", err.Error())
+		}
+		return cache
+	case "", "memory":
+		return NewMemoryCache(maxBytes)
+	default:
+		log.Fatalf("unknown image cache driver '%s'", driver)
+		return nil
+	}
+}
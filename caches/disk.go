@@ -0,0 +1,106 @@
+package caches
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DiskCache stores entries as files, the index of used entries is kept in memory
+// and rebuilt from the files (oldest first) on start
+type DiskCache struct {
+	mu  sync.Mutex
+	dir string
+	lru *lru
+}
+
+func NewDiskCache(dir string, maxBytes int64) (Cache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	d := &DiskCache{dir: dir}
+	d.lru = newLru(maxBytes, func(entry *lruEntry) {
+		os.Remove(filepath.Join(d.dir, entry.key))
+	})
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	infos := []os.FileInfo{}
+	for _, file := range files {
+		// skip temporary files of interrupted writes
+		if strings.HasPrefix(file.Name(), ".") {
+			os.Remove(filepath.Join(dir, file.Name()))
+			continue
+		}
+		if info, err := file.Info(); err == nil && info.Mode().IsRegular() {
+			infos = append(infos, info)
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].ModTime().Before(infos[j].ModTime())
+	})
+	for _, info := range infos {
+		d.lru.add(&lruEntry{key: info.Name(), size: info.Size()})
+	}
+
+	return d, nil
+}
+
+// fileName hashes keys so any key is a safe file name
+func fileName(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func (d *DiskCache) Get(key string) (data []byte, ok bool) {
+	name := fileName(key)
+
+	d.mu.Lock()
+	_, ok = d.lru.get(name)
+	d.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(filepath.Join(d.dir, name))
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+func (d *DiskCache) Set(key string, data []byte) {
+	name := fileName(key)
+
+	// write to a temporary file first so readers never see partial files
+	tmpFile, err := os.CreateTemp(d.dir, ".tmp-*")
+	if err != nil {
+		log.Printf("error writing image cache: %v", err)
+		return
+	}
+	defer os.Remove(tmpFile.Name())
+
+	_, err = tmpFile.Write(data)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpFile.Name(), filepath.Join(d.dir, name))
+	}
+	if err != nil {
+		log.Printf("error writing image cache: %v", err)
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.lru.add(&lruEntry{key: name, size: int64(len(data))})
+}
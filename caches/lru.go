@@ -0,0 +1,61 @@
+package caches
+
+import "container/list"
+
+type lruEntry struct {
+	key  string
+	size int64
+	data []byte // only kept by the memory cache
+}
+
+// lru keeps entries in order of use and evicts the least recently used ones above maxBytes,
+// it isn't safe for concurrent use
+type lru struct {
+	maxBytes int64
+	bytes    int64
+	order    *list.List // front is the most recently used
+	entries  map[string]*list.Element
+	onEvict  func(entry *lruEntry)
+}
+
+func newLru(maxBytes int64, onEvict func(entry *lruEntry)) *lru {
+	return &lru{
+		maxBytes: maxBytes,
+		order:    list.New(),
+		entries:  map[string]*list.Element{},
+		onEvict:  onEvict,
+	}
+}
+
+func (l *lru) get(key string) (*lruEntry, bool) {
+	element, ok := l.entries[key]
+	if !ok {
+		return nil, false
+	}
+	l.order.MoveToFront(element)
+	return element.Value.(*lruEntry), true
+}
+
+func (l *lru) add(entry *lruEntry) {
+	if element, ok := l.entries[entry.key]; ok {
+		l.remove(element)
+	}
+
+	l.entries[entry.key] = l.order.PushFront(entry)
+	l.bytes += entry.size
+
+	for l.bytes > l.maxBytes && l.order.Len() > 0 {
+		oldest := l.order.Back()
+		l.remove(oldest)
+		if l.onEvict != nil {
+			l.onEvict(oldest.Value.(*lruEntry))
+		}
+	}
+}
+
+func (l *lru) remove(element *list.Element) {
+	entry := element.Value.(*lruEntry)
+	l.order.Remove(element)
+	delete(l.entries, entry.key)
+	l.bytes -= entry.size
+}
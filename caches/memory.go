@@ -0,0 +1,32 @@
+package caches
+
+import "sync"
+
+type MemoryCache struct {
+	mu  sync.Mutex
+	lru *lru
+}
+
+func NewMemoryCache(maxBytes int64) Cache {
+	return &MemoryCache{
+		lru: newLru(maxBytes, nil),
+	}
+}
+
+func (m *MemoryCache) Get(key string) (data []byte, ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.lru.get(key)
+	if !ok {
+		return nil, false
+	}
+	return entry.data, true
+}
+
+func (m *MemoryCache) Set(key string, data []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.lru.add(&lruEntry{key: key, size: int64(len(data)), data: data})
+}
@@ -0,0 +1,43 @@
+// backfill-storage-usage stores the file sizes of photos uploaded before sizes were recorded and
+// recomputes the storage used by every user, run it while no photos are uploaded or deleted:
+//
+//	go run ./cmd/backfill-storage-usage
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/alvinmdj/mygram-api/database"
+	"github.com/alvinmdj/mygram-api/repositories"
+	"github.com/alvinmdj/mygram-api/services"
+	"github.com/alvinmdj/mygram-api/storages"
+	"github.com/joho/godotenv"
+)
+
+func init() {
+	if os.Getenv("APP_ENV") != "production" {
+		err := godotenv.Load()
+		if err != nil {
+			log.Fatal("Error loading .env file")
+		}
+	}
+}
+
+func main() {
+	database.StartDB()
+	storages.StartStorage()
+
+	db := database.GetDB()
+	storageUsageSvc := services.NewStorageUsageSvc(
+		repositories.NewPhotoRepo(db),
+		repositories.NewUserRepo(db),
+		storages.GetStorage(),
+	)
+
+	photos, users, err := storageUsageSvc.Backfill()
+	if err != nil {
+		log.Fatal("error backfilling storage usage:", err.Error())
+	}
+	log.Printf("stored the file sizes of %d photos, recomputed the storage used by %d users", photos, users)
+}
@@ -0,0 +1,50 @@
+// promote-user sets the role of a user, e.g. to create the first admin:
+//
+//	go run ./cmd/promote-user -email admin@example.com -role admin
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/alvinmdj/mygram-api/database"
+	"github.com/alvinmdj/mygram-api/models"
+	"github.com/alvinmdj/mygram-api/repositories"
+	"github.com/joho/godotenv"
+)
+
+func init() {
+	if os.Getenv("APP_ENV") != "production" {
+		err := godotenv.Load()
+		if err != nil {
+			log.Fatal("Error loading .env file")
+		}
+	}
+}
+
+func main() {
+	email := flag.String("email", "", "email of the user")
+	role := flag.String("role", models.RoleAdmin, "new role: user, premium, moderator or admin")
+	flag.Parse()
+
+	if *email == "" || !models.IsValidRole(*role) {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	database.StartDB()
+	userRepo := repositories.NewUserRepo(database.GetDB())
+
+	user, err := userRepo.FindByEmail(models.User{Email: *email})
+	if err != nil {
+		log.Fatalf("user with email %s doesn't exist", *email)
+	}
+
+	user.Role = *role
+	if err = userRepo.UpdateRole(user); err != nil {
+		log.Fatal("error updating role:", err.Error())
+	}
+
+	log.Printf("user %s is now %s, the new role applies on the next sign in", user.Username, user.Role)
+}
@@ -0,0 +1,62 @@
+// reconcile-storage deletes stored files no photo refers to anymore (e.g. left behind by a crash),
+// run it with -dry-run first to see what would be deleted:
+//
+//	go run ./cmd/reconcile-storage -dry-run
+//	go run ./cmd/reconcile-storage -older-than 48h
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/alvinmdj/mygram-api/database"
+	"github.com/alvinmdj/mygram-api/repositories"
+	"github.com/alvinmdj/mygram-api/services"
+	"github.com/alvinmdj/mygram-api/storages"
+	"github.com/joho/godotenv"
+)
+
+func init() {
+	if os.Getenv("APP_ENV") != "production" {
+		err := godotenv.Load()
+		if err != nil {
+			log.Fatal("Error loading .env file")
+		}
+	}
+}
+
+func main() {
+	prefixes := flag.String("prefixes", "photos/,staging/", "comma separated key prefixes to reconcile")
+	olderThan := flag.Duration("older-than", 24*time.Hour, "skip files younger than this, their photo may still be in the making")
+	dryRun := flag.Bool("dry-run", false, "only list the orphaned files")
+	flag.Parse()
+
+	database.StartDB()
+	storages.StartStorage()
+
+	db := database.GetDB()
+	storageCleanupSvc := services.NewStorageCleanupSvc(
+		repositories.NewPendingDeletionRepo(db),
+		repositories.NewPhotoRepo(db),
+		storages.GetStorage(),
+	)
+
+	orphans, err := storageCleanupSvc.Reconcile(strings.Split(*prefixes, ","), *olderThan, *dryRun)
+	if err != nil {
+		log.Fatal("error reconciling storage:", err.Error())
+	}
+
+	var size int64
+	for _, orphan := range orphans {
+		log.Printf("orphan: %v (%d bytes, %v)", orphan.Key, orphan.Size, orphan.LastModified.Format(time.RFC3339))
+		size += orphan.Size
+	}
+	if *dryRun {
+		log.Printf("found %d orphaned files (%d bytes), nothing deleted", len(orphans), size)
+		return
+	}
+	log.Printf("deleted %d orphaned files (%d bytes), failed deletions are retried by the app", len(orphans), size)
+}
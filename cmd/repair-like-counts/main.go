@@ -0,0 +1,36 @@
+// repair-like-counts recomputes the denormalized like counts of the photos from the likes,
+// e.g. after likes were changed by hand in the database:
+//
+//	go run ./cmd/repair-like-counts
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/alvinmdj/mygram-api/database"
+	"github.com/alvinmdj/mygram-api/repositories"
+	"github.com/alvinmdj/mygram-api/services"
+	"github.com/joho/godotenv"
+)
+
+func init() {
+	if os.Getenv("APP_ENV") != "production" {
+		err := godotenv.Load()
+		if err != nil {
+			log.Fatal("Error loading .env file")
+		}
+	}
+}
+
+func main() {
+	database.StartDB()
+
+	likeSvc := services.NewLikeSvc(repositories.NewLikeRepo(database.GetDB()))
+
+	repaired, err := likeSvc.RepairCounts()
+	if err != nil {
+		log.Fatal("error repairing like counts:", err.Error())
+	}
+	log.Printf("repaired the like count of %d photos", repaired)
+}
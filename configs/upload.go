@@ -0,0 +1,150 @@
+package configs
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/alvinmdj/mygram-api/helpers"
+	"github.com/alvinmdj/mygram-api/images"
+	"github.com/alvinmdj/mygram-api/models"
+)
+
+// UploadLimits are the limits of an uploaded photo file
+type UploadLimits struct {
+	MaxBytes     int64
+	MaxPixels    int
+	AllowedTypes []string // mime types, e.g. image/jpeg
+	StorageQuota int64    // default quota of all stored photos of a user, users may have their own quota
+}
+
+// UploadConfig holds the default upload limits and the overrides per role,
+// unset fields of an override fall back to the default limits
+type UploadConfig struct {
+	Default UploadLimits
+	Roles   map[string]UploadLimits
+}
+
+// supported mime types of uploaded photos
+var SupportedTypes = []string{"image/jpeg", "image/png"}
+
+// DefaultUploadConfig is used for every setting missing in the environment
+var DefaultUploadConfig = UploadConfig{
+	Default: UploadLimits{
+		MaxBytes:     2 << 20, // 2 MiB, larger uploads are opt-in per role or with UPLOAD_MAX_BYTES
+		MaxPixels:    images.DefaultLimits.MaxPixels,
+		AllowedTypes: SupportedTypes,
+		StorageQuota: 1 << 30, // 1 GiB
+	},
+	Roles: map[string]UploadLimits{
+		models.RolePremium: {MaxBytes: 20 << 20, StorageQuota: 10 << 30}, // 20 MiB, 10 GiB
+	},
+}
+
+// LoadUploadConfig reads the upload limits from the environment:
+// UPLOAD_MAX_BYTES, UPLOAD_MAX_PIXELS, UPLOAD_ALLOWED_TYPES & STORAGE_QUOTA, and per role
+// the same variables suffixed with the role, e.g. UPLOAD_MAX_BYTES_PREMIUM
+func LoadUploadConfig() (config UploadConfig, err error) {
+	config.Default, err = loadUploadLimits("", DefaultUploadConfig.Default)
+	if err != nil {
+		return
+	}
+
+	config.Roles = map[string]UploadLimits{}
+	for _, role := range models.Roles {
+		var limits UploadLimits
+		limits, err = loadUploadLimits("_"+strings.ToUpper(role), DefaultUploadConfig.Roles[role])
+		if err != nil {
+			return
+		}
+		if limits.MaxBytes != 0 || limits.MaxPixels != 0 || len(limits.AllowedTypes) > 0 || limits.StorageQuota != 0 {
+			config.Roles[role] = limits
+		}
+	}
+	return
+}
+
+func loadUploadLimits(suffix string, def UploadLimits) (limits UploadLimits, err error) {
+	limits.MaxBytes, err = helpers.GetEnvBytes("UPLOAD_MAX_BYTES"+suffix, def.MaxBytes)
+	if err != nil {
+		return
+	}
+	limits.MaxPixels = helpers.GetEnvInt("UPLOAD_MAX_PIXELS"+suffix, def.MaxPixels)
+	limits.StorageQuota, err = helpers.GetEnvBytes("STORAGE_QUOTA"+suffix, def.StorageQuota)
+	if err != nil {
+		return
+	}
+
+	limits.AllowedTypes = def.AllowedTypes
+	if value := os.Getenv("UPLOAD_ALLOWED_TYPES" + suffix); value != "" {
+		limits.AllowedTypes = nil
+		for _, contentType := range strings.Split(value, ",") {
+			contentType = strings.TrimSpace(contentType)
+			if !isSupportedType(contentType) {
+				return limits, fmt.Errorf("UPLOAD_ALLOWED_TYPES%v: unsupported type %q", suffix, contentType)
+			}
+			limits.AllowedTypes = append(limits.AllowedTypes, contentType)
+		}
+	}
+	return
+}
+
+// For returns the upload limits of a role
+func (u UploadConfig) For(role string) UploadLimits {
+	limits := u.Default
+	override, ok := u.Roles[role]
+	if !ok {
+		return limits
+	}
+	if override.MaxBytes != 0 {
+		limits.MaxBytes = override.MaxBytes
+	}
+	if override.MaxPixels != 0 {
+		limits.MaxPixels = override.MaxPixels
+	}
+	if len(override.AllowedTypes) > 0 {
+		limits.AllowedTypes = override.AllowedTypes
+	}
+	if override.StorageQuota != 0 {
+		limits.StorageQuota = override.StorageQuota
+	}
+	return limits
+}
+
+// MaxBytes returns the largest upload size of any role
+func (u UploadConfig) MaxBytes() int64 {
+	maxBytes := u.Default.MaxBytes
+	for role := range u.Roles {
+		if limits := u.For(role); limits.MaxBytes > maxBytes {
+			maxBytes = limits.MaxBytes
+		}
+	}
+	return maxBytes
+}
+
+// ImageLimits returns the limits to validate an image with
+func (u UploadLimits) ImageLimits() images.Limits {
+	limits := images.DefaultLimits
+	limits.MaxBytes = u.MaxBytes
+	limits.MaxPixels = u.MaxPixels
+	return limits
+}
+
+// Allows reports whether files of the mime type may be uploaded
+func (u UploadLimits) Allows(contentType string) bool {
+	for _, allowed := range u.AllowedTypes {
+		if allowed == contentType {
+			return true
+		}
+	}
+	return false
+}
+
+func isSupportedType(contentType string) bool {
+	for _, supported := range SupportedTypes {
+		if supported == contentType {
+			return true
+		}
+	}
+	return false
+}
@@ -36,7 +36,7 @@ func StartDB() {
 	log.Println("database connected successfully")
 
 	// auto migrate table schemas
-	db.Debug().AutoMigrate(models.User{}, models.Photo{}, models.Comment{}, models.SocialMedia{})
+	db.Debug().AutoMigrate(models.User{}, models.Photo{}, models.Comment{}, models.SocialMedia{}, models.RefreshToken{}, models.RevokedToken{}, models.UserTokenRevocation{}, models.UserToken{}, models.PhotoVariant{}, models.PendingDeletion{}, models.PhotoVersion{}, models.PhotoVersionVariant{}, models.Like{}, models.Follow{}, models.TimelineEntry{}, models.PhotoRanking{})
 }
 
 func GetDB() *gorm.DB {
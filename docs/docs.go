@@ -1,3 +1,5 @@
+// Code generated by swaggo/swag. DO NOT EDIT.
+
 package docs
 
 import "github.com/swaggo/swag"
@@ -23,16 +25,16 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/v1/photos": {
+        "/api/v1/admin/users": {
             "get": {
-                "description": "Get all photos",
+                "description": "Get all users (admin only)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "photos"
+                    "admin"
                 ],
-                "summary": "Get all photos",
+                "summary": "Get all users",
                 "parameters": [
                     {
                         "type": "string",
@@ -48,7 +50,7 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.PhotoGetOutput"
+                                "$ref": "#/definitions/models.UserAdminOutput"
                             }
                         }
                     },
@@ -57,37 +59,32 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
                     }
                 }
-            },
-            "post": {
-                "description": "Create photos",
-                "consumes": [
-                    "multipart/form-data"
-                ],
+            }
+        },
+        "/api/v1/admin/users/{userId}": {
+            "get": {
+                "description": "Get one user by id (admin only)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "photos"
+                    "admin"
                 ],
-                "summary": "Create photos",
+                "summary": "Get one user by id",
                 "parameters": [
                     {
                         "type": "string",
-                        "name": "caption",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "name": "title",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "file",
-                        "description": "upload photo",
-                        "name": "photo",
-                        "in": "formData",
+                        "description": "get user by id",
+                        "name": "userId",
+                        "in": "path",
                         "required": true
                     },
                     {
@@ -99,42 +96,40 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.PhotoCreateOutput"
+                            "$ref": "#/definitions/models.UserAdminOutput"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "413": {
-                        "description": "Request Entity Too Large",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/photos/{photoId}": {
-            "get": {
-                "description": "Get one photo by id",
+            },
+            "delete": {
+                "description": "Delete a user account with its photos, comments \u0026 social medias (admin only)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "photos"
+                    "admin"
                 ],
-                "summary": "Get one photo by id",
+                "summary": "Delete user",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "get photo by id",
-                        "name": "photoId",
+                        "description": "delete user by id",
+                        "name": "userId",
                         "in": "path",
                         "required": true
                     },
@@ -150,7 +145,13 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.PhotoGetOutput"
+                            "$ref": "#/definitions/models.DeleteResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "404": {
@@ -160,44 +161,26 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Update photo",
-                "consumes": [
-                    "application/json",
-                    "multipart/form-data"
-                ],
+            }
+        },
+        "/api/v1/admin/users/{userId}/block": {
+            "post": {
+                "description": "Block a user, the user is signed out and can't sign in anymore (admin only)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "photos"
+                    "admin"
                 ],
-                "summary": "Update photo",
+                "summary": "Block user",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "update photo by id",
-                        "name": "photoId",
+                        "description": "block user by id",
+                        "name": "userId",
                         "in": "path",
                         "required": true
                     },
-                    {
-                        "type": "string",
-                        "name": "caption",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "name": "title",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "file",
-                        "description": "upload photo",
-                        "name": "photo",
-                        "in": "formData"
-                    },
                     {
                         "type": "string",
                         "description": "format: Bearer token-here",
@@ -210,17 +193,17 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.PhotoUpdateOutput"
+                            "$ref": "#/definitions/models.UserAdminOutput"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -228,19 +211,19 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Delete photo",
+                "description": "Unblock a user (admin only)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "photos"
+                    "admin"
                 ],
-                "summary": "Delete photo",
+                "summary": "Unblock user",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "delete photo by id",
-                        "name": "photoId",
+                        "description": "unblock user by id",
+                        "name": "userId",
                         "in": "path",
                         "required": true
                     },
@@ -256,7 +239,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.DeleteResponse"
+                            "$ref": "#/definitions/models.UserAdminOutput"
                         }
                     },
                     "403": {
@@ -274,24 +257,36 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/photos/{photoId}/comments": {
-            "get": {
-                "description": "Get all comments associated with the photo id",
+        "/api/v1/admin/users/{userId}/quota": {
+            "put": {
+                "description": "Set the storage quota of a user in bytes, null resets it to the default quota of the role (admin only)",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "comments"
+                    "admin"
                 ],
-                "summary": "Get all comments associated with the photo id",
+                "summary": "Update user storage quota",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "get comment associated with the photo id",
-                        "name": "photoId",
+                        "description": "update quota of user by id",
+                        "name": "userId",
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "description": "update quota",
+                        "name": "models.UserQuotaUpdateInput",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserQuotaUpdateInput"
+                        }
+                    },
                     {
                         "type": "string",
                         "description": "format: Bearer token-here",
@@ -304,10 +299,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.CommentGetOutput"
-                            }
+                            "$ref": "#/definitions/models.UserAdminOutput"
                         }
                     },
                     "400": {
@@ -315,11 +307,25 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
                     }
                 }
-            },
-            "post": {
-                "description": "Create comment",
+            }
+        },
+        "/api/v1/admin/users/{userId}/role": {
+            "put": {
+                "description": "Update the role of a user, the user has to sign in again (admin only)",
                 "consumes": [
                     "application/json",
                     "multipart/form-data"
@@ -328,24 +334,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "comments"
+                    "admin"
                 ],
-                "summary": "Create comment",
+                "summary": "Update user role",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "create comment associated with the photo id",
-                        "name": "photoId",
+                        "description": "update role of user by id",
+                        "name": "userId",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "create comment",
-                        "name": "models.CommentCreateInput",
+                        "description": "update role",
+                        "name": "models.UserRoleUpdateInput",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.CommentCreateInputSwagger"
+                            "$ref": "#/definitions/models.UserRoleUpdateInput"
                         }
                     },
                     {
@@ -357,10 +363,10 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.CommentCreateOutput"
+                            "$ref": "#/definitions/models.UserAdminOutput"
                         }
                     },
                     "400": {
@@ -368,189 +374,270 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/api/v1/photos/{photoId}/comments/{commentId}": {
+        "/api/v1/config/uploads": {
             "get": {
-                "description": "Get one comment by id",
+                "description": "Get the photo upload limits (max file size, max pixels \u0026 allowed types) so clients can validate files before uploading,\nthe limits of a user are the ones of their role (role claim of the access token) or the default limits",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "comments"
+                    "config"
                 ],
-                "summary": "Get one comment by id",
+                "summary": "Get upload limits",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.UploadConfigOutput"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/explore": {
+            "get": {
+                "description": "Get the recent photos of public users ranked by their likes, comments \u0026 unique commenters, decayed by their age. the rankings are recomputed periodically",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "explore"
+                ],
+                "summary": "Get trending photos",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "get comment associated with the photo id",
-                        "name": "photoId",
-                        "in": "path",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "get comment by id",
-                        "name": "commentId",
-                        "in": "path",
-                        "required": true
+                        "type": "integer",
+                        "description": "page size (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "format: Bearer token-here",
-                        "name": "Authorization",
-                        "in": "header",
-                        "required": true
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.CommentGetOutput"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.PaginatedResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.ExplorePhotoOutput"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Update comment",
-                "consumes": [
-                    "application/json",
-                    "multipart/form-data"
-                ],
+            }
+        },
+        "/api/v1/feed": {
+            "get": {
+                "description": "Get the photos of the users the logged in user follows, newest first, with a preview of their latest comments",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "comments"
+                    "feed"
                 ],
-                "summary": "Update comment",
+                "summary": "Get my feed",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "update comment associated with the photo id",
-                        "name": "photoId",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "update comment by id",
-                        "name": "commentId",
-                        "in": "path",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
                         "required": true
                     },
                     {
-                        "description": "update comment",
-                        "name": "models.CommentUpdateInput",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.CommentUpdateInputSwagger"
-                        }
+                        "type": "integer",
+                        "description": "page size (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "format: Bearer token-here",
-                        "name": "Authorization",
-                        "in": "header",
-                        "required": true
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.CommentUpdateOutput"
-                        }
-                    },
-                    "403": {
-                        "description": "Forbidden",
-                        "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.PaginatedResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.FeedPhotoOutput"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete comment",
+            }
+        },
+        "/api/v1/photos": {
+            "get": {
+                "description": "Get all photos, photos of private accounts are only visible to their accepted followers",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "comments"
+                    "photos"
                 ],
-                "summary": "Delete comment",
+                "summary": "Get all photos",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "delete comment associated with the photo id",
-                        "name": "photoId",
-                        "in": "path",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
                         "required": true
                     },
+                    {
+                        "type": "integer",
+                        "description": "page size (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
-                        "description": "delete comment by id",
-                        "name": "commentId",
-                        "in": "path",
-                        "required": true
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "format: Bearer token-here",
-                        "name": "Authorization",
-                        "in": "header",
-                        "required": true
+                        "description": "id, created_at, updated_at or like_count, prefix with - for descending order (default -created_at)",
+                        "name": "sort",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.DeleteResponse"
-                        }
-                    },
-                    "403": {
-                        "description": "Forbidden",
-                        "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.PaginatedResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.PhotoGetOutput"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/social-medias": {
-            "get": {
-                "description": "Get all social media",
+            },
+            "post": {
+                "description": "Create photos from an uploaded file (multipart) or from a file uploaded with a presigned url (json with upload_key)",
+                "consumes": [
+                    "application/json",
+                    "multipart/form-data"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "socialMedias"
+                    "photos"
                 ],
-                "summary": "Get all social media",
+                "summary": "Create photos",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "name": "caption",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "name": "keep_metadata",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "name": "title",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "file",
+                        "description": "upload photo",
+                        "name": "photo",
+                        "in": "formData"
+                    },
+                    {
+                        "description": "create photo from upload",
+                        "name": "models.PhotoCreateFromUploadInput",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.PhotoCreateFromUploadInput"
+                        }
+                    },
                     {
                         "type": "string",
                         "description": "format: Bearer token-here",
@@ -560,13 +647,10 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.SocialMediaGetOutput"
-                            }
+                            "$ref": "#/definitions/models.PhotoCreateOutput"
                         }
                     },
                     "400": {
@@ -574,30 +658,61 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "507": {
+                        "description": "Insufficient Storage",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
                     }
                 }
-            },
+            }
+        },
+        "/api/v1/photos/uploads": {
             "post": {
-                "description": "Create social media",
+                "description": "Create a presigned url to upload a photo file directly to the storage, create the photo afterwards with the returned upload_key",
                 "consumes": [
-                    "application/json",
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "socialMedias"
+                    "photos"
                 ],
-                "summary": "Create social media",
+                "summary": "Create photo upload url",
                 "parameters": [
                     {
-                        "description": "create social media",
-                        "name": "models.SocialMediaCreateInput",
+                        "description": "photo file to upload",
+                        "name": "models.PhotoUploadInput",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.SocialMediaCreateInputSwagger"
+                            "$ref": "#/definitions/models.PhotoUploadInput"
                         }
                     },
                     {
@@ -612,7 +727,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.SocialMediaCreateOutput"
+                            "$ref": "#/definitions/models.PhotoUploadOutput"
                         }
                     },
                     "400": {
@@ -620,25 +735,37 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/api/v1/social-medias/{socialMediaId}": {
+        "/api/v1/photos/{photoId}": {
             "get": {
-                "description": "Get one social media by id",
+                "description": "Get one photo by id, photos of private accounts are only visible to their accepted followers",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "socialMedias"
+                    "photos"
                 ],
-                "summary": "Get one social media by id",
+                "summary": "Get one photo by id",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "get social media by id",
-                        "name": "socialMediaId",
+                        "description": "get photo by id",
+                        "name": "photoId",
                         "in": "path",
                         "required": true
                     },
@@ -654,7 +781,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.SocialMediaGetOutput"
+                            "$ref": "#/definitions/models.PhotoGetOutput"
                         }
                     },
                     "404": {
@@ -666,7 +793,7 @@ const docTemplate = `{
                 }
             },
             "put": {
-                "description": "Update social media",
+                "description": "Update photo, the previous title, caption \u0026 file are kept as a version which can be reverted to",
                 "consumes": [
                     "application/json",
                     "multipart/form-data"
@@ -675,25 +802,37 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "socialMedias"
+                    "photos"
                 ],
-                "summary": "Update social media",
+                "summary": "Update photo",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "update social media by id",
-                        "name": "socialMediaId",
+                        "description": "update photo by id",
+                        "name": "photoId",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "update social media",
-                        "name": "models.SocialMediaUpdateInput",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.SocialMediaUpdateInputSwagger"
-                        }
+                        "type": "string",
+                        "name": "caption",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "name": "keep_metadata",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "name": "title",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "file",
+                        "description": "upload photo",
+                        "name": "photo",
+                        "in": "formData"
                     },
                     {
                         "type": "string",
@@ -707,7 +846,13 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.SocialMediaUpdateOutput"
+                            "$ref": "#/definitions/models.PhotoUpdateOutput"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "403": {
@@ -716,8 +861,26 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "507": {
+                        "description": "Insufficient Storage",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -725,19 +888,19 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Delete social media",
+                "description": "Move photo to the trash, it can be restored until it is purged after the retention period",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "socialMedias"
+                    "photos"
                 ],
-                "summary": "Delete social media",
+                "summary": "Delete photo",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "delete social media by id",
-                        "name": "socialMediaId",
+                        "description": "delete photo by id",
+                        "name": "photoId",
                         "in": "path",
                         "required": true
                     },
@@ -771,50 +934,82 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/users/login": {
-            "post": {
-                "description": "User login",
-                "consumes": [
-                    "application/json",
-                    "multipart/form-data"
-                ],
+        "/api/v1/photos/{photoId}/comments": {
+            "get": {
+                "description": "Get all comments associated with the photo id",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "comments"
                 ],
-                "summary": "User login",
+                "summary": "Get all comments associated with the photo id",
                 "parameters": [
                     {
-                        "description": "login user",
-                        "name": "models.UserLoginInput",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.UserLoginInput"
-                        }
+                        "type": "string",
+                        "description": "get comment associated with the photo id",
+                        "name": "photoId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "id, created_at or updated_at, prefix with - for descending order (default created_at)",
+                        "name": "sort",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.UserLoginOutput"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.PaginatedResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.CommentGetOutput"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/users/register": {
+            },
             "post": {
-                "description": "Register new user",
+                "description": "Create comment",
                 "consumes": [
                     "application/json",
                     "multipart/form-data"
@@ -823,25 +1018,39 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "comments"
                 ],
-                "summary": "Register new user",
+                "summary": "Create comment",
                 "parameters": [
                     {
-                        "description": "register user",
-                        "name": "models.UserRegisterInput",
+                        "type": "string",
+                        "description": "create comment associated with the photo id",
+                        "name": "photoId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "create comment",
+                        "name": "models.CommentCreateInput",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.UserRegisterInput"
+                            "$ref": "#/definitions/models.CommentCreateInputSwagger"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.UserRegisterOutput"
+                            "$ref": "#/definitions/models.CommentCreateOutput"
                         }
                     },
                     "400": {
@@ -849,21 +1058,3156 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/photos/{photoId}/comments/{commentId}": {
+            "get": {
+                "description": "Get one comment by id",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Get one comment by id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "get comment associated with the photo id",
+                        "name": "photoId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "get comment by id",
+                        "name": "commentId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.CommentGetOutput"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update comment",
+                "consumes": [
+                    "application/json",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Update comment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "update comment associated with the photo id",
+                        "name": "photoId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "update comment by id",
+                        "name": "commentId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "update comment",
+                        "name": "models.CommentUpdateInput",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CommentUpdateInputSwagger"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.CommentUpdateOutput"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Move comment to the trash, it can be restored until it is purged after the retention period",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Delete comment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "delete comment associated with the photo id",
+                        "name": "photoId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "delete comment by id",
+                        "name": "commentId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.DeleteResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/photos/{photoId}/comments/{commentId}/hide": {
+            "post": {
+                "description": "Hide a comment, only the photo owner, moderators and admins can hide comments",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Hide comment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "hide comment associated with the photo id",
+                        "name": "photoId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "hide comment by id",
+                        "name": "commentId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Show a hidden comment again, only the photo owner, moderators and admins can unhide comments",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Unhide comment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "unhide comment associated with the photo id",
+                        "name": "photoId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "unhide comment by id",
+                        "name": "commentId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/photos/{photoId}/comments/{commentId}/restore": {
+            "post": {
+                "description": "Restore a comment from the trash, the author \u0026 the photo owner can only restore comments they deleted themselves (staff can restore any)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Restore comment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "restore comment associated with the photo id",
+                        "name": "photoId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "restore comment by id",
+                        "name": "commentId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/photos/{photoId}/image-url": {
+            "get": {
+                "description": "Get the signed url of a photo resized, cropped (fit=cover) and re-encoded on the fly, other users than the owner \u0026 staff can only request the presets (150x150 \u0026 320x320 cover, w=640, w=1080)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "images"
+                ],
+                "summary": "Get the signed url of a transformed photo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "photo id",
+                        "name": "photoId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "max width",
+                        "name": "w",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "max height",
+                        "name": "h",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "contain (default) or cover",
+                        "name": "fit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "jpeg or png, defaults to the format of the photo",
+                        "name": "fmt",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImageURLOutput"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/photos/{photoId}/like": {
+            "put": {
+                "description": "Like a photo, liking a photo which is already liked has no effect",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "likes"
+                ],
+                "summary": "Like photo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "like the photo id",
+                        "name": "photoId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.LikeOutput"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove the like of a photo, unliking a photo which isn't liked has no effect",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "likes"
+                ],
+                "summary": "Unlike photo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "unlike the photo id",
+                        "name": "photoId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.LikeOutput"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/photos/{photoId}/likes": {
+            "get": {
+                "description": "Get the users who liked the photo, the latest like first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "likes"
+                ],
+                "summary": "Get all likes of a photo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "get likes of the photo id",
+                        "name": "photoId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "id, created_at or updated_at, prefix with - for descending order (default -created_at)",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.PaginatedResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.LikeGetOutput"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/photos/{photoId}/restore": {
+            "post": {
+                "description": "Restore a photo from the trash, the owner can only restore photos they deleted themselves (staff can restore any)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "photos"
+                ],
+                "summary": "Restore photo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "restore photo by id",
+                        "name": "photoId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PhotoGetOutput"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/photos/{photoId}/settings": {
+            "put": {
+                "description": "Update the settings of a photo, e.g. who may comment on it (everyone, followers or off)",
+                "consumes": [
+                    "application/json",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "photos"
+                ],
+                "summary": "Update photo settings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "update photo settings by id",
+                        "name": "photoId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "update photo settings",
+                        "name": "models.PhotoSettingsInput",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PhotoSettingsInputSwagger"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PhotoUpdateOutput"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/photos/{photoId}/versions": {
+            "get": {
+                "description": "Get the previous versions of a photo, the latest version first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "photos"
+                ],
+                "summary": "Get photo versions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "get versions of photo by id",
+                        "name": "photoId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.PhotoVersionOutput"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/photos/{photoId}/versions/{version}/revert": {
+            "post": {
+                "description": "Revert a photo to a previous version, the current state is kept as a new version",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "photos"
+                ],
+                "summary": "Revert photo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "revert photo by id",
+                        "name": "photoId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "version to revert to",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PhotoGetOutput"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/social-medias": {
+            "get": {
+                "description": "Get all social media",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "socialMedias"
+                ],
+                "summary": "Get all social media",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "id, created_at or updated_at, prefix with - for descending order (default -created_at)",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.PaginatedResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.SocialMediaGetOutput"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create social media",
+                "consumes": [
+                    "application/json",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "socialMedias"
+                ],
+                "summary": "Create social media",
+                "parameters": [
+                    {
+                        "description": "create social media",
+                        "name": "models.SocialMediaCreateInput",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SocialMediaCreateInputSwagger"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.SocialMediaCreateOutput"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/social-medias/{socialMediaId}": {
+            "get": {
+                "description": "Get one social media by id",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "socialMedias"
+                ],
+                "summary": "Get one social media by id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "get social media by id",
+                        "name": "socialMediaId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SocialMediaGetOutput"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update social media",
+                "consumes": [
+                    "application/json",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "socialMedias"
+                ],
+                "summary": "Update social media",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "update social media by id",
+                        "name": "socialMediaId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "update social media",
+                        "name": "models.SocialMediaUpdateInput",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SocialMediaUpdateInputSwagger"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SocialMediaUpdateOutput"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete social media",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "socialMedias"
+                ],
+                "summary": "Delete social media",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "delete social media by id",
+                        "name": "socialMediaId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.DeleteResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/uploads": {
+            "post": {
+                "description": "Start a resumable photo upload (tus creation), the photo is created when the upload is complete.\nUpload-Metadata holds the photo input as base64 encoded values: title, caption \u0026 keep_metadata.",
+                "tags": [
+                    "uploads"
+                ],
+                "summary": "Start a resumable photo upload",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "1.0.0",
+                        "name": "Tus-Resumable",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "file size in bytes",
+                        "name": "Upload-Length",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "e.g. title dGl0bGU=,caption Y2FwdGlvbg==",
+                        "name": "Upload-Metadata",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "options": {
+                "description": "tus protocol discovery: supported version, extensions \u0026 max upload size",
+                "tags": [
+                    "uploads"
+                ],
+                "summary": "Resumable upload capabilities",
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/api/v1/uploads/{uploadId}": {
+            "delete": {
+                "description": "Cancel a resumable upload (tus termination)",
+                "tags": [
+                    "uploads"
+                ],
+                "summary": "Cancel a resumable upload",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "upload id",
+                        "name": "uploadId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "1.0.0",
+                        "name": "Tus-Resumable",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "head": {
+                "description": "Get the offset of a resumable upload to resume it, Upload-Photo-Id is set once the photo is created",
+                "tags": [
+                    "uploads"
+                ],
+                "summary": "Get the offset of a resumable upload",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "upload id",
+                        "name": "uploadId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "1.0.0",
+                        "name": "Tus-Resumable",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Append a chunk at Upload-Offset, the photo is validated and created when the last chunk is received",
+                "consumes": [
+                    "application/offset+octet-stream"
+                ],
+                "tags": [
+                    "uploads"
+                ],
+                "summary": "Upload a chunk of a resumable upload",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "upload id",
+                        "name": "uploadId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "1.0.0",
+                        "name": "Tus-Resumable",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "current offset",
+                        "name": "Upload-Offset",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "423": {
+                        "description": "Locked",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "507": {
+                        "description": "Insufficient Storage",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/login": {
+            "post": {
+                "description": "User login",
+                "consumes": [
+                    "application/json",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "User login",
+                "parameters": [
+                    {
+                        "description": "login user",
+                        "name": "models.UserLoginInput",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserLoginInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserLoginOutput"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/logout": {
+            "post": {
+                "description": "Revoke the current access token and, if given, the session's refresh token",
+                "consumes": [
+                    "application/json",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "User logout",
+                "parameters": [
+                    {
+                        "description": "refresh token of the session",
+                        "name": "models.UserLogoutInput",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserLogoutInput"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/logout-all": {
+            "post": {
+                "description": "Revoke every access token and refresh token of the current user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "User logout everywhere",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/me": {
+            "get": {
+                "description": "Get the profile of the logged in user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get my profile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserRegisterOutput"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update the profile of the logged in user",
+                "consumes": [
+                    "application/json",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update my profile",
+                "parameters": [
+                    {
+                        "description": "update user",
+                        "name": "models.UserUpdateInput",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserUpdateInputSwagger"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserUpdateOutput"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete the account of the logged in user with its photos, comments \u0026 social medias and revoke all of its tokens",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Delete my account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.DeleteResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/me/follow-requests": {
+            "get": {
+                "description": "Get the pending follow requests to the logged in user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "follows"
+                ],
+                "summary": "Get my follow requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "id, created_at or updated_at, prefix with - for descending order (default -created_at)",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.PaginatedResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.FollowGetOutput"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/me/follow-requests/{userId}": {
+            "delete": {
+                "description": "Decline a pending follow request to the logged in user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "follows"
+                ],
+                "summary": "Decline follow request",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id of the user who requested to follow",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/me/follow-requests/{userId}/accept": {
+            "post": {
+                "description": "Accept a pending follow request to the logged in user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "follows"
+                ],
+                "summary": "Accept follow request",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id of the user who requested to follow",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/me/password": {
+            "post": {
+                "description": "Change the password of the logged in user, requires the current password",
+                "consumes": [
+                    "application/json",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Change my password",
+                "parameters": [
+                    {
+                        "description": "change password",
+                        "name": "models.UserChangePasswordInput",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserChangePasswordInput"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/me/privacy": {
+            "put": {
+                "description": "Make the account of the logged in user private or public, follow requests to a private account need approval and making it public accepts the pending requests",
+                "consumes": [
+                    "application/json",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "follows"
+                ],
+                "summary": "Update my privacy",
+                "parameters": [
+                    {
+                        "description": "update privacy",
+                        "name": "models.UserPrivacyInput",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserPrivacyInput"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserProfileDetailOutput"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/me/trash": {
+            "get": {
+                "description": "Get the deleted photos \u0026 comments of the logged in user, they can be restored until purge_at",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get my trash",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TrashOutput"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/me/usage": {
+            "get": {
+                "description": "Get the storage used by the photos of the logged in user and their storage quota (bytes)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get my storage usage",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserUsageOutput"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/password/forgot": {
+            "post": {
+                "description": "Send a password reset link to the email, if it is registered",
+                "consumes": [
+                    "application/json",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Forgot password",
+                "parameters": [
+                    {
+                        "description": "forgot password",
+                        "name": "models.UserForgotPasswordInput",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserForgotPasswordInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/password/reset": {
+            "post": {
+                "description": "Set a new password using the token from the password reset email",
+                "consumes": [
+                    "application/json",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Reset password",
+                "parameters": [
+                    {
+                        "description": "reset password",
+                        "name": "models.UserResetPasswordInput",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserResetPasswordInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/refresh": {
+            "post": {
+                "description": "Exchange a refresh token for a new access token and a new (rotated) refresh token",
+                "consumes": [
+                    "application/json",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Refresh access token",
+                "parameters": [
+                    {
+                        "description": "refresh token",
+                        "name": "models.UserRefreshInput",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserRefreshInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserLoginOutput"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/register": {
+            "post": {
+                "description": "Register new user",
+                "consumes": [
+                    "application/json",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Register new user",
+                "parameters": [
+                    {
+                        "description": "register user",
+                        "name": "models.UserRegisterInput",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserRegisterInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserRegisterOutput"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/verify": {
+            "get": {
+                "description": "Verify the email of a user using the token from the verification email",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Verify email",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "verification token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/verify/resend": {
+            "post": {
+                "description": "Send a new verification link to the email, if it is registered and not verified yet",
+                "consumes": [
+                    "application/json",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Resend verification email",
+                "parameters": [
+                    {
+                        "description": "resend verification email",
+                        "name": "models.UserResendVerificationInput",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserResendVerificationInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/{username}": {
+            "get": {
+                "description": "Get the public profile of a user by username, with the follower \u0026 following counts",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get user profile by username",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "get user by username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserProfileDetailOutput"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/{username}/follow": {
+            "put": {
+                "description": "Follow a user, following a private user sends a follow request which the user has to accept",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "follows"
+                ],
+                "summary": "Follow user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "follow user by username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.FollowOutput"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Unfollow a user or withdraw a follow request",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "follows"
+                ],
+                "summary": "Unfollow user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "unfollow user by username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.FollowOutput"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/{username}/followers": {
+            "get": {
+                "description": "Get the followers of a user, the followers of a private user are only visible to the user and its followers",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "follows"
+                ],
+                "summary": "Get followers",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "get followers of user by username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "id, created_at or updated_at, prefix with - for descending order (default -created_at)",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.PaginatedResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.FollowGetOutput"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/{username}/following": {
+            "get": {
+                "description": "Get the users a user follows, with the same visibility as the followers",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "follows"
+                ],
+                "summary": "Get followed users",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "get users followed by user by username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "format: Bearer token-here",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "id, created_at or updated_at, prefix with - for descending order (default -created_at)",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.PaginatedResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.FollowGetOutput"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/img/{photoId}": {
+            "get": {
+                "description": "Get a photo resized, cropped (fit=cover) and re-encoded on the fly, the url must be signed (see the image-url endpoint)",
+                "produces": [
+                    "image/jpeg",
+                    "image/png"
+                ],
+                "tags": [
+                    "images"
+                ],
+                "summary": "Get a transformed photo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "photo id",
+                        "name": "photoId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "max width",
+                        "name": "w",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "max height",
+                        "name": "h",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "contain (default) or cover",
+                        "name": "fit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "jpeg or png, defaults to the format of the photo",
+                        "name": "fmt",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "signature",
+                        "name": "sig",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/uploads/{key}": {
+            "get": {
+                "description": "Get a stored photo (or variant) file, direct uploads aren't served before a photo is created from them",
+                "produces": [
+                    "image/jpeg",
+                    "image/png"
+                ],
+                "tags": [
+                    "uploads"
+                ],
+                "summary": "Get a file of the local storage",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "storage key",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Upload a file with a presigned url returned by POST /api/v1/photos/uploads",
+                "consumes": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "uploads"
+                ],
+                "summary": "Direct upload to the local storage",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "upload key",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "expiry (unix time)",
+                        "name": "expires",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "signature",
+                        "name": "sig",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.CommentCreateInputSwagger": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CommentCreateOutput": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "photo_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CommentGetOutput": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "hidden_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/models.UserRegisterOutput"
+                }
+            }
+        },
+        "models.CommentUpdateInputSwagger": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CommentUpdateOutput": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "photo_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.DeleteResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "machine readable reason, e.g. for rejected uploads",
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ExplorePhotoOutput": {
+            "type": "object",
+            "properties": {
+                "caption": {
+                    "type": "string"
+                },
+                "comment_policy": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "like_count": {
+                    "type": "integer"
+                },
+                "liked_by_me": {
+                    "type": "boolean"
+                },
+                "metadata": {
+                    "$ref": "#/definitions/models.PhotoMetadataOutput"
+                },
+                "photo_url": {
+                    "type": "string"
+                },
+                "score": {
+                    "type": "number"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/models.UserRegisterOutput"
+                },
+                "variants": {
+                    "description": "resized copies by size (longest side in px), e.g. \"640\"",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.FeedPhotoOutput": {
+            "type": "object",
+            "properties": {
+                "caption": {
+                    "type": "string"
+                },
+                "comment_policy": {
+                    "type": "string"
+                },
+                "comments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CommentGetOutput"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "like_count": {
+                    "type": "integer"
+                },
+                "liked_by_me": {
+                    "type": "boolean"
+                },
+                "metadata": {
+                    "$ref": "#/definitions/models.PhotoMetadataOutput"
+                },
+                "photo_url": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/models.UserRegisterOutput"
+                },
+                "variants": {
+                    "description": "resized copies by size (longest side in px), e.g. \"640\"",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.FollowGetOutput": {
+            "type": "object",
+            "properties": {
+                "accepted_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/models.UserProfileOutput"
+                }
+            }
+        },
+        "models.FollowOutput": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "description": "pending, accepted or none",
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ImageURLOutput": {
+            "type": "object",
+            "properties": {
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.LikeGetOutput": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/models.UserProfileOutput"
+                }
+            }
+        },
+        "models.LikeOutput": {
+            "type": "object",
+            "properties": {
+                "like_count": {
+                    "type": "integer"
+                },
+                "liked_by_me": {
+                    "type": "boolean"
+                },
+                "photo_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.MessageResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PaginatedResponse": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "next_cursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PhotoCreateFromUploadInput": {
+            "type": "object",
+            "properties": {
+                "caption": {
+                    "type": "string"
+                },
+                "keep_metadata": {
+                    "type": "boolean"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "upload_key": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PhotoCreateOutput": {
+            "type": "object",
+            "properties": {
+                "caption": {
+                    "type": "string"
+                },
+                "comment_policy": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "metadata": {
+                    "$ref": "#/definitions/models.PhotoMetadataOutput"
+                },
+                "photo_url": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "variants": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.PhotoGetOutput": {
+            "type": "object",
+            "properties": {
+                "caption": {
+                    "type": "string"
+                },
+                "comment_policy": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "like_count": {
+                    "type": "integer"
+                },
+                "liked_by_me": {
+                    "type": "boolean"
+                },
+                "metadata": {
+                    "$ref": "#/definitions/models.PhotoMetadataOutput"
+                },
+                "photo_url": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/models.UserRegisterOutput"
+                },
+                "variants": {
+                    "description": "resized copies by size (longest side in px), e.g. \"640\"",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.PhotoMetadataOutput": {
+            "type": "object",
+            "properties": {
+                "camera_model": {
+                    "type": "string"
+                },
+                "orientation": {
+                    "type": "integer"
+                },
+                "taken_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PhotoSettingsInputSwagger": {
+            "type": "object",
+            "properties": {
+                "comment_policy": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PhotoUpdateOutput": {
+            "type": "object",
+            "properties": {
+                "caption": {
+                    "type": "string"
+                },
+                "comment_policy": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "metadata": {
+                    "$ref": "#/definitions/models.PhotoMetadataOutput"
+                },
+                "photo_url": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "variants": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.PhotoUploadInput": {
+            "type": "object",
+            "properties": {
+                "content_type": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PhotoUploadOutput": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "headers": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "method": {
+                    "type": "string"
+                },
+                "upload_key": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PhotoVersionOutput": {
+            "type": "object",
+            "properties": {
+                "caption": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "description": "when the photo was changed from this version",
+                    "type": "string"
+                },
+                "metadata": {
+                    "$ref": "#/definitions/models.PhotoMetadataOutput"
+                },
+                "photo_url": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "variants": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
                     }
+                },
+                "version": {
+                    "type": "integer"
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.CommentCreateInputSwagger": {
+        },
+        "models.SocialMediaCreateInputSwagger": {
             "type": "object",
             "properties": {
-                "message": {
+                "name": {
+                    "type": "string"
+                },
+                "social_media_url": {
                     "type": "string"
                 }
             }
         },
-        "models.CommentCreateOutput": {
+        "models.SocialMediaCreateOutput": {
             "type": "object",
             "properties": {
                 "created_at": {
@@ -872,11 +4216,11 @@ const docTemplate = `{
                 "id": {
                     "type": "integer"
                 },
-                "message": {
+                "name": {
                     "type": "string"
                 },
-                "photo_id": {
-                    "type": "integer"
+                "social_media_url": {
+                    "type": "string"
                 },
                 "updated_at": {
                     "type": "string"
@@ -886,7 +4230,7 @@ const docTemplate = `{
                 }
             }
         },
-        "models.CommentGetOutput": {
+        "models.SocialMediaGetOutput": {
             "type": "object",
             "properties": {
                 "created_at": {
@@ -895,7 +4239,10 @@ const docTemplate = `{
                 "id": {
                     "type": "integer"
                 },
-                "message": {
+                "name": {
+                    "type": "string"
+                },
+                "social_media_url": {
                     "type": "string"
                 },
                 "updated_at": {
@@ -906,15 +4253,18 @@ const docTemplate = `{
                 }
             }
         },
-        "models.CommentUpdateInputSwagger": {
+        "models.SocialMediaUpdateInputSwagger": {
             "type": "object",
             "properties": {
-                "message": {
+                "name": {
+                    "type": "string"
+                },
+                "social_media_url": {
                     "type": "string"
                 }
             }
         },
-        "models.CommentUpdateOutput": {
+        "models.SocialMediaUpdateOutput": {
             "type": "object",
             "properties": {
                 "created_at": {
@@ -923,11 +4273,11 @@ const docTemplate = `{
                 "id": {
                     "type": "integer"
                 },
-                "message": {
+                "name": {
                     "type": "string"
                 },
-                "photo_id": {
-                    "type": "integer"
+                "social_media_url": {
+                    "type": "string"
                 },
                 "updated_at": {
                     "type": "string"
@@ -937,26 +4287,50 @@ const docTemplate = `{
                 }
             }
         },
-        "models.DeleteResponse": {
+        "models.TrashCommentOutput": {
             "type": "object",
             "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
                 "message": {
                     "type": "string"
+                },
+                "photo_id": {
+                    "type": "integer"
+                },
+                "purge_at": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
-        "models.ErrorResponse": {
+        "models.TrashOutput": {
             "type": "object",
             "properties": {
-                "error": {
-                    "type": "string"
+                "comments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TrashCommentOutput"
+                    }
                 },
-                "message": {
-                    "type": "string"
+                "photos": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TrashPhotoOutput"
+                    }
                 }
             }
         },
-        "models.PhotoCreateOutput": {
+        "models.TrashPhotoOutput": {
             "type": "object",
             "properties": {
                 "caption": {
@@ -965,186 +4339,300 @@ const docTemplate = `{
                 "created_at": {
                     "type": "string"
                 },
+                "deleted_at": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
                 "photo_url": {
                     "type": "string"
                 },
+                "purge_at": {
+                    "description": "deleted for good after this time",
+                    "type": "string"
+                },
                 "title": {
                     "type": "string"
                 },
                 "updated_at": {
                     "type": "string"
+                }
+            }
+        },
+        "models.UploadConfigOutput": {
+            "type": "object",
+            "properties": {
+                "default": {
+                    "$ref": "#/definitions/models.UploadLimitsOutput"
                 },
-                "user_id": {
+                "roles": {
+                    "description": "limits of the roles with overrides",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/models.UploadLimitsOutput"
+                    }
+                }
+            }
+        },
+        "models.UploadLimitsOutput": {
+            "type": "object",
+            "properties": {
+                "allowed_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "max_bytes": {
+                    "type": "integer"
+                },
+                "max_pixels": {
+                    "type": "integer"
+                },
+                "storage_quota": {
+                    "description": "default quota of all stored photos of a user",
                     "type": "integer"
                 }
             }
         },
-        "models.PhotoGetOutput": {
+        "models.UserAdminOutput": {
             "type": "object",
             "properties": {
-                "caption": {
+                "age": {
+                    "type": "integer"
+                },
+                "blocked_at": {
                     "type": "string"
                 },
                 "created_at": {
                     "type": "string"
                 },
+                "email": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
-                "photo_url": {
+                "role": {
                     "type": "string"
                 },
-                "title": {
-                    "type": "string"
+                "storage_quota": {
+                    "type": "integer"
+                },
+                "storage_used": {
+                    "description": "storage quota set for the user, null uses the default quota of the role",
+                    "type": "integer"
                 },
                 "updated_at": {
                     "type": "string"
                 },
-                "user": {
-                    "$ref": "#/definitions/models.UserRegisterOutput"
+                "username": {
+                    "type": "string"
+                },
+                "verified_at": {
+                    "type": "string"
                 }
             }
         },
-        "models.PhotoUpdateOutput": {
+        "models.UserChangePasswordInput": {
             "type": "object",
             "properties": {
-                "caption": {
+                "current_password": {
                     "type": "string"
                 },
-                "created_at": {
+                "new_password": {
                     "type": "string"
-                },
-                "id": {
-                    "type": "integer"
-                },
-                "photo_url": {
+                }
+            }
+        },
+        "models.UserForgotPasswordInput": {
+            "type": "object",
+            "properties": {
+                "email": {
                     "type": "string"
-                },
-                "title": {
+                }
+            }
+        },
+        "models.UserLoginInput": {
+            "type": "object",
+            "properties": {
+                "email": {
                     "type": "string"
                 },
-                "updated_at": {
+                "password": {
                     "type": "string"
-                },
-                "user_id": {
-                    "type": "integer"
                 }
             }
         },
-        "models.SocialMediaCreateInputSwagger": {
+        "models.UserLoginOutput": {
             "type": "object",
             "properties": {
-                "name": {
+                "expires_in": {
+                    "type": "integer"
+                },
+                "refresh_token": {
                     "type": "string"
                 },
-                "social_media_url": {
+                "token": {
                     "type": "string"
                 }
             }
         },
-        "models.SocialMediaCreateOutput": {
+        "models.UserLogoutInput": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.UserPrivacyInput": {
+            "type": "object",
+            "properties": {
+                "is_private": {
+                    "description": "follow requests need approval, making the account public accepts the pending requests",
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.UserProfileDetailOutput": {
             "type": "object",
             "properties": {
+                "age": {
+                    "type": "integer"
+                },
                 "created_at": {
                     "type": "string"
                 },
-                "id": {
+                "follower_count": {
                     "type": "integer"
                 },
-                "name": {
-                    "type": "string"
+                "following_count": {
+                    "type": "integer"
                 },
-                "social_media_url": {
-                    "type": "string"
+                "id": {
+                    "type": "integer"
+                },
+                "is_private": {
+                    "type": "boolean"
                 },
                 "updated_at": {
                     "type": "string"
                 },
-                "user_id": {
-                    "type": "integer"
+                "username": {
+                    "type": "string"
                 }
             }
         },
-        "models.SocialMediaGetOutput": {
+        "models.UserProfileOutput": {
             "type": "object",
             "properties": {
+                "age": {
+                    "type": "integer"
+                },
                 "created_at": {
                     "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "name": {
+                "updated_at": {
                     "type": "string"
                 },
-                "social_media_url": {
+                "username": {
                     "type": "string"
-                },
-                "updated_at": {
+                }
+            }
+        },
+        "models.UserQuotaUpdateInput": {
+            "type": "object",
+            "properties": {
+                "storage_quota": {
+                    "description": "bytes, null resets the quota to the default quota of the role",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.UserRefreshInput": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
                     "type": "string"
-                },
-                "user": {
-                    "$ref": "#/definitions/models.UserRegisterOutput"
                 }
             }
         },
-        "models.SocialMediaUpdateInputSwagger": {
+        "models.UserRegisterInput": {
             "type": "object",
             "properties": {
-                "name": {
+                "age": {
+                    "type": "integer"
+                },
+                "email": {
                     "type": "string"
                 },
-                "social_media_url": {
+                "password": {
+                    "type": "string"
+                },
+                "username": {
                     "type": "string"
                 }
             }
         },
-        "models.SocialMediaUpdateOutput": {
+        "models.UserRegisterOutput": {
             "type": "object",
             "properties": {
+                "age": {
+                    "type": "integer"
+                },
                 "created_at": {
                     "type": "string"
                 },
+                "email": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
-                "name": {
+                "updated_at": {
                     "type": "string"
                 },
-                "social_media_url": {
+                "username": {
                     "type": "string"
                 },
-                "updated_at": {
+                "verified_at": {
                     "type": "string"
-                },
-                "user_id": {
-                    "type": "integer"
                 }
             }
         },
-        "models.UserLoginInput": {
+        "models.UserResendVerificationInput": {
             "type": "object",
             "properties": {
                 "email": {
                     "type": "string"
+                }
+            }
+        },
+        "models.UserResetPasswordInput": {
+            "type": "object",
+            "properties": {
+                "new_password": {
+                    "type": "string"
                 },
-                "password": {
+                "token": {
                     "type": "string"
                 }
             }
         },
-        "models.UserLoginOutput": {
+        "models.UserRoleUpdateInput": {
             "type": "object",
             "properties": {
-                "token": {
+                "role": {
                     "type": "string"
                 }
             }
         },
-        "models.UserRegisterInput": {
+        "models.UserUpdateInputSwagger": {
             "type": "object",
             "properties": {
                 "age": {
@@ -1153,15 +4641,12 @@ const docTemplate = `{
                 "email": {
                     "type": "string"
                 },
-                "password": {
-                    "type": "string"
-                },
                 "username": {
                     "type": "string"
                 }
             }
         },
-        "models.UserRegisterOutput": {
+        "models.UserUpdateOutput": {
             "type": "object",
             "properties": {
                 "age": {
@@ -1181,6 +4666,23 @@ const docTemplate = `{
                 },
                 "username": {
                     "type": "string"
+                },
+                "verified_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.UserUsageOutput": {
+            "type": "object",
+            "properties": {
+                "storage_available": {
+                    "type": "integer"
+                },
+                "storage_quota": {
+                    "type": "integer"
+                },
+                "storage_used": {
+                    "type": "integer"
                 }
             }
         }
@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/alvinmdj/mygram-api/helpers"
+	"github.com/alvinmdj/mygram-api/models"
+	"github.com/alvinmdj/mygram-api/services"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+type AdminHdlInterface interface {
+	GetAllUsers(c *gin.Context)
+	GetUserById(c *gin.Context)
+	UpdateUserRole(c *gin.Context)
+	UpdateUserQuota(c *gin.Context)
+	BlockUser(c *gin.Context)
+	UnblockUser(c *gin.Context)
+	DeleteUser(c *gin.Context)
+}
+
+type AdminHandler struct {
+	userSvc services.UserSvcInterface
+}
+
+func NewAdminHdl(userSvc services.UserSvcInterface) AdminHdlInterface {
+	return &AdminHandler{
+		userSvc: userSvc,
+	}
+}
+
+// Admin GetAllUsers godoc
+// @Summary Get all users
+// @Description Get all users (admin only)
+// @Tags admin
+// @Param Authorization header string true "format: Bearer token-here"
+// @Produce json
+// @Success 200 {object} []models.UserAdminOutput{}
+// @Failure 400 {object} models.ErrorResponse{}
+// @Failure 403 {object} models.ErrorResponse{}
+// @Router /api/v1/admin/users [get]
+func (a *AdminHandler) GetAllUsers(c *gin.Context) {
+	users, err := a.userSvc.GetAll()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "BAD REQUEST",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	usersResponse := []models.UserAdminOutput{}
+	for _, user := range users {
+		usersResponse = append(usersResponse, userAdminOutput(user))
+	}
+	c.JSON(http.StatusOK, usersResponse)
+}
+
+// Admin GetUserById godoc
+// @Summary Get one user by id
+// @Description Get one user by id (admin only)
+// @Tags admin
+// @Param userId path string true "get user by id"
+// @Param Authorization header string true "format: Bearer token-here"
+// @Produce json
+// @Success 200 {object} models.UserAdminOutput{}
+// @Failure 403 {object} models.ErrorResponse{}
+// @Failure 404 {object} models.ErrorResponse{}
+// @Router /api/v1/admin/users/{userId} [get]
+func (a *AdminHandler) GetUserById(c *gin.Context) {
+	userId, _ := strconv.Atoi(c.Param("userId"))
+
+	user, err := a.userSvc.GetById(uint(userId))
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "NOT FOUND",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, userAdminOutput(user))
+}
+
+// Admin UpdateUserRole godoc
+// @Summary Update user role
+// @Description Update the role of a user, the user has to sign in again (admin only)
+// @Tags admin
+// @Accept json,mpfd
+// @Produce json
+// @Param userId path string true "update role of user by id"
+// @Param models.UserRoleUpdateInput body models.UserRoleUpdateInput{} true "update role"
+// @Param Authorization header string true "format: Bearer token-here"
+// @Success 200 {object} models.UserAdminOutput{}
+// @Failure 400 {object} models.ErrorResponse{}
+// @Failure 403 {object} models.ErrorResponse{}
+// @Router /api/v1/admin/users/{userId}/role [put]
+func (a *AdminHandler) UpdateUserRole(c *gin.Context) {
+	userId, _ := strconv.Atoi(c.Param("userId"))
+	contentType := helpers.GetContentType(c)
+	roleInput := models.UserRoleUpdateInput{}
+
+	// get token claims in userData context from authentication middleware
+	// and cast the data type from any to jwt.MapClaims
+	userData := c.MustGet("userData").(jwt.MapClaims)
+	adminId := uint(userData["id"].(float64))
+
+	if contentType == helpers.AppJson {
+		c.ShouldBindJSON(&roleInput)
+	} else {
+		c.ShouldBind(&roleInput)
+	}
+
+	user, err := a.userSvc.UpdateRole(adminId, uint(userId), roleInput)
+	if errors.Is(err, services.ErrSelfManagement) {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "FORBIDDEN",
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "BAD REQUEST",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, userAdminOutput(user))
+}
+
+// Admin UpdateUserQuota godoc
+// @Summary Update user storage quota
+// @Description Set the storage quota of a user in bytes, null resets it to the default quota of the role (admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param userId path string true "update quota of user by id"
+// @Param models.UserQuotaUpdateInput body models.UserQuotaUpdateInput{} true "update quota"
+// @Param Authorization header string true "format: Bearer token-here"
+// @Success 200 {object} models.UserAdminOutput{}
+// @Failure 400 {object} models.ErrorResponse{}
+// @Failure 403 {object} models.ErrorResponse{}
+// @Failure 404 {object} models.ErrorResponse{}
+// @Router /api/v1/admin/users/{userId}/quota [put]
+func (a *AdminHandler) UpdateUserQuota(c *gin.Context) {
+	userId, _ := strconv.Atoi(c.Param("userId"))
+	quotaInput := models.UserQuotaUpdateInput{}
+
+	// json only, a null quota resets it to the default quota of the role
+	if err := c.ShouldBindJSON(&quotaInput); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "BAD REQUEST",
+			Message: "invalid request body",
+		})
+		return
+	}
+
+	user, err := a.userSvc.UpdateStorageQuota(uint(userId), quotaInput)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "NOT FOUND",
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "BAD REQUEST",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, userAdminOutput(user))
+}
+
+// Admin BlockUser godoc
+// @Summary Block user
+// @Description Block a user, the user is signed out and can't sign in anymore (admin only)
+// @Tags admin
+// @Produce json
+// @Param userId path string true "block user by id"
+// @Param Authorization header string true "format: Bearer token-here"
+// @Success 200 {object} models.UserAdminOutput{}
+// @Failure 403 {object} models.ErrorResponse{}
+// @Failure 404 {object} models.ErrorResponse{}
+// @Router /api/v1/admin/users/{userId}/block [post]
+func (a *AdminHandler) BlockUser(c *gin.Context) {
+	userId, _ := strconv.Atoi(c.Param("userId"))
+
+	// get token claims in userData context from authentication middleware
+	// and cast the data type from any to jwt.MapClaims
+	userData := c.MustGet("userData").(jwt.MapClaims)
+	adminId := uint(userData["id"].(float64))
+
+	user, err := a.userSvc.Block(adminId, uint(userId))
+	if errors.Is(err, services.ErrSelfManagement) {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "FORBIDDEN",
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "NOT FOUND",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, userAdminOutput(user))
+}
+
+// Admin UnblockUser godoc
+// @Summary Unblock user
+// @Description Unblock a user (admin only)
+// @Tags admin
+// @Produce json
+// @Param userId path string true "unblock user by id"
+// @Param Authorization header string true "format: Bearer token-here"
+// @Success 200 {object} models.UserAdminOutput{}
+// @Failure 403 {object} models.ErrorResponse{}
+// @Failure 404 {object} models.ErrorResponse{}
+// @Router /api/v1/admin/users/{userId}/block [delete]
+func (a *AdminHandler) UnblockUser(c *gin.Context) {
+	userId, _ := strconv.Atoi(c.Param("userId"))
+
+	user, err := a.userSvc.Unblock(uint(userId))
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "NOT FOUND",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, userAdminOutput(user))
+}
+
+// Admin DeleteUser godoc
+// @Summary Delete user
+// @Description Delete a user account with its photos, comments & social medias (admin only)
+// @Tags admin
+// @Produce json
+// @Param userId path string true "delete user by id"
+// @Param Authorization header string true "format: Bearer token-here"
+// @Success 200 {object} models.DeleteResponse{}
+// @Failure 403 {object} models.ErrorResponse{}
+// @Failure 404 {object} models.ErrorResponse{}
+// @Router /api/v1/admin/users/{userId} [delete]
+func (a *AdminHandler) DeleteUser(c *gin.Context) {
+	userId, _ := strconv.Atoi(c.Param("userId"))
+
+	if err := a.userSvc.Delete(uint(userId)); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "NOT FOUND",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.DeleteResponse{
+		Message: fmt.Sprintf("user data with id %d has been deleted", userId),
+	})
+}
+
+func userAdminOutput(user models.User) models.UserAdminOutput {
+	return models.UserAdminOutput{
+		Base:         user.Base,
+		Username:     user.Username,
+		Email:        user.Email,
+		Age:          user.Age,
+		Role:         user.Role,
+		VerifiedAt:   user.VerifiedAt,
+		BlockedAt:    user.BlockedAt,
+		StorageUsed:  user.StorageUsed,
+		StorageQuota: user.StorageQuota,
+	}
+}
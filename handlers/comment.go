@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -18,6 +19,9 @@ type CommentHdlInterface interface {
 	Create(c *gin.Context)
 	Update(c *gin.Context)
 	Delete(c *gin.Context)
+	Restore(c *gin.Context)
+	Hide(c *gin.Context)
+	Unhide(c *gin.Context)
 }
 
 type CommentHandler struct {
@@ -36,14 +40,25 @@ func NewCommentHdl(commentSvc services.CommentSvcInterface) CommentHdlInterface
 // @Tags comments
 // @Param photoId path string true "get comment associated with the photo id"
 // @Param Authorization header string true "format: Bearer token-here"
+// @Param limit query int false "page size (default 20, max 100)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "id, created_at or updated_at, prefix with - for descending order (default created_at)"
 // @Produce json
-// @Success 200 {object} []models.CommentGetOutput{}
+// @Success 200 {object} models.PaginatedResponse{data=[]models.CommentGetOutput}
 // @Failure 400 {object} models.ErrorResponse{}
 // @Router /api/v1/photos/{photoId}/comments [get]
 func (co *CommentHandler) GetAll(c *gin.Context) {
 	photoId, _ := strconv.Atoi(c.Param("photoId"))
 
-	comments, err := co.commentSvc.GetAll(photoId)
+	// get token claims in userData context from authentication middleware
+	// and cast the data type from any to jwt.MapClaims
+	userData := c.MustGet("userData").(jwt.MapClaims)
+	userId := uint(userData["id"].(float64))
+
+	pageInput := models.PageInput{}
+	c.ShouldBindQuery(&pageInput)
+
+	comments, nextCursor, err := co.commentSvc.GetAll(photoId, userId, canModerateComments(c, userData), pageInput)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
 			Error:   "BAD REQUEST",
@@ -55,8 +70,9 @@ func (co *CommentHandler) GetAll(c *gin.Context) {
 	commentsResponse := []models.CommentGetOutput{}
 	for _, comment := range comments {
 		commentOutput := models.CommentGetOutput{
-			Base:    comment.Base,
-			Message: comment.Message,
+			Base:     comment.Base,
+			Message:  comment.Message,
+			HiddenAt: comment.HiddenAt,
 			User: models.UserRegisterOutput{
 				Base:     comment.User.Base,
 				Username: comment.User.Username,
@@ -66,7 +82,10 @@ func (co *CommentHandler) GetAll(c *gin.Context) {
 		}
 		commentsResponse = append(commentsResponse, commentOutput)
 	}
-	c.JSON(http.StatusOK, commentsResponse)
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Data:       commentsResponse,
+		NextCursor: nextCursor,
+	})
 }
 
 // Comment GetOneById godoc
@@ -84,7 +103,12 @@ func (co *CommentHandler) GetOneById(c *gin.Context) {
 	photoId, _ := strconv.Atoi(c.Param("photoId"))
 	commentId, _ := strconv.Atoi(c.Param("commentId"))
 
-	comment, err := co.commentSvc.GetOneById(photoId, commentId)
+	// get token claims in userData context from authentication middleware
+	// and cast the data type from any to jwt.MapClaims
+	userData := c.MustGet("userData").(jwt.MapClaims)
+	userId := uint(userData["id"].(float64))
+
+	comment, err := co.commentSvc.GetOneById(photoId, commentId, userId, canModerateComments(c, userData))
 	if err != nil {
 		c.JSON(http.StatusNotFound, models.ErrorResponse{
 			Error:   "NOT FOUND",
@@ -94,8 +118,9 @@ func (co *CommentHandler) GetOneById(c *gin.Context) {
 	}
 
 	commentResponse := models.CommentGetOutput{
-		Base:    comment.Base,
-		Message: comment.Message,
+		Base:     comment.Base,
+		Message:  comment.Message,
+		HiddenAt: comment.HiddenAt,
 		User: models.UserRegisterOutput{
 			Base:     comment.User.Base,
 			Username: comment.User.Username,
@@ -117,6 +142,7 @@ func (co *CommentHandler) GetOneById(c *gin.Context) {
 // @Param Authorization header string true "format: Bearer token-here"
 // @Success 201 {object} models.CommentCreateOutput{}
 // @Failure 400 {object} models.ErrorResponse{}
+// @Failure 403 {object} models.ErrorResponse{}
 // @Router /api/v1/photos/{photoId}/comments [post]
 func (co *CommentHandler) Create(c *gin.Context) {
 	contentType := helpers.GetContentType(c)
@@ -140,6 +166,13 @@ func (co *CommentHandler) Create(c *gin.Context) {
 	}
 
 	comment, err := co.commentSvc.Create(commentInput)
+	if errors.Is(err, services.ErrCommentsDisabled) || errors.Is(err, services.ErrCommentsFollowersOnly) {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "FORBIDDEN",
+			Message: err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
 			Error:   "BAD REQUEST",
@@ -213,7 +246,7 @@ func (co *CommentHandler) Update(c *gin.Context) {
 
 // Comment Delete godoc
 // @Summary Delete comment
-// @Description Delete comment
+// @Description Move comment to the trash, it can be restored until it is purged after the retention period
 // @Tags comments
 // @Produce json
 // @Param photoId path string true "delete comment associated with the photo id"
@@ -224,9 +257,11 @@ func (co *CommentHandler) Update(c *gin.Context) {
 // @Failure 404 {object} models.ErrorResponse{}
 // @Router /api/v1/photos/{photoId}/comments/{commentId} [delete]
 func (co *CommentHandler) Delete(c *gin.Context) {
+	userData := c.MustGet("userData").(jwt.MapClaims)
+	userId := uint(userData["id"].(float64))
 	commentId, _ := strconv.Atoi(c.Param("commentId"))
 
-	if err := co.commentSvc.Delete(commentId); err != nil {
+	if err := co.commentSvc.Delete(commentId, userId); err != nil {
 		c.JSON(http.StatusNotFound, models.ErrorResponse{
 			Error:   "NOT FOUND",
 			Message: err.Error(),
@@ -238,3 +273,99 @@ func (co *CommentHandler) Delete(c *gin.Context) {
 		Message: fmt.Sprintf("comment data with id %d has been deleted", commentId),
 	})
 }
+
+// Comment Restore godoc
+// @Summary Restore comment
+// @Description Restore a comment from the trash, the author & the photo owner can only restore comments they deleted themselves (staff can restore any)
+// @Tags comments
+// @Produce json
+// @Param photoId path string true "restore comment associated with the photo id"
+// @Param commentId path string true "restore comment by id"
+// @Param Authorization header string true "format: Bearer token-here"
+// @Success 200 {object} models.MessageResponse{}
+// @Failure 403 {object} models.ErrorResponse{}
+// @Failure 404 {object} models.ErrorResponse{}
+// @Router /api/v1/photos/{photoId}/comments/{commentId}/restore [post]
+func (co *CommentHandler) Restore(c *gin.Context) {
+	commentId, _ := strconv.Atoi(c.Param("commentId"))
+
+	if err := co.commentSvc.Restore(commentId); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "NOT FOUND",
+			Message: "comment data isn't in the trash",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{
+		Message: fmt.Sprintf("comment data with id %d has been restored", commentId),
+	})
+}
+
+// Comment Hide godoc
+// @Summary Hide comment
+// @Description Hide a comment, only the photo owner, moderators and admins can hide comments
+// @Tags comments
+// @Produce json
+// @Param photoId path string true "hide comment associated with the photo id"
+// @Param commentId path string true "hide comment by id"
+// @Param Authorization header string true "format: Bearer token-here"
+// @Success 200 {object} models.MessageResponse{}
+// @Failure 403 {object} models.ErrorResponse{}
+// @Failure 404 {object} models.ErrorResponse{}
+// @Router /api/v1/photos/{photoId}/comments/{commentId}/hide [post]
+func (co *CommentHandler) Hide(c *gin.Context) {
+	commentId, _ := strconv.Atoi(c.Param("commentId"))
+
+	if err := co.commentSvc.Hide(commentId); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "NOT FOUND",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{
+		Message: fmt.Sprintf("comment data with id %d has been hidden", commentId),
+	})
+}
+
+// Comment Unhide godoc
+// @Summary Unhide comment
+// @Description Show a hidden comment again, only the photo owner, moderators and admins can unhide comments
+// @Tags comments
+// @Produce json
+// @Param photoId path string true "unhide comment associated with the photo id"
+// @Param commentId path string true "unhide comment by id"
+// @Param Authorization header string true "format: Bearer token-here"
+// @Success 200 {object} models.MessageResponse{}
+// @Failure 403 {object} models.ErrorResponse{}
+// @Failure 404 {object} models.ErrorResponse{}
+// @Router /api/v1/photos/{photoId}/comments/{commentId}/hide [delete]
+func (co *CommentHandler) Unhide(c *gin.Context) {
+	commentId, _ := strconv.Atoi(c.Param("commentId"))
+
+	if err := co.commentSvc.Unhide(commentId); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "NOT FOUND",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{
+		Message: fmt.Sprintf("comment data with id %d is visible again", commentId),
+	})
+}
+
+// canModerateComments checks if the user can see hidden comments of the photo
+// loaded by the find photo middleware: the photo owner, moderators and admins
+func canModerateComments(c *gin.Context, userData jwt.MapClaims) bool {
+	photo := c.MustGet("photo").(models.Photo)
+	if photo.UserID == uint(userData["id"].(float64)) {
+		return true
+	}
+
+	role, _ := userData["role"].(string)
+	return models.IsStaff(role)
+}
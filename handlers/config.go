@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/alvinmdj/mygram-api/configs"
+	"github.com/alvinmdj/mygram-api/models"
+	"github.com/gin-gonic/gin"
+)
+
+type ConfigHdlInterface interface {
+	GetUploads(c *gin.Context)
+}
+
+type ConfigHandler struct {
+	uploadConfig configs.UploadConfig
+}
+
+func NewConfigHdl(uploadConfig configs.UploadConfig) ConfigHdlInterface {
+	return &ConfigHandler{
+		uploadConfig: uploadConfig,
+	}
+}
+
+// Config GetUploads godoc
+// @Summary Get upload limits
+// @Description Get the photo upload limits (max file size, max pixels & allowed types) so clients can validate files before uploading,
+// @Description the limits of a user are the ones of their role (role claim of the access token) or the default limits
+// @Tags config
+// @Produce json
+// @Success 200 {object} models.UploadConfigOutput{}
+// @Router /api/v1/config/uploads [get]
+func (cf *ConfigHandler) GetUploads(c *gin.Context) {
+	configResponse := models.UploadConfigOutput{
+		Default: uploadLimitsOutput(cf.uploadConfig.Default),
+		Roles:   map[string]models.UploadLimitsOutput{},
+	}
+	for role := range cf.uploadConfig.Roles {
+		configResponse.Roles[role] = uploadLimitsOutput(cf.uploadConfig.For(role))
+	}
+	c.JSON(http.StatusOK, configResponse)
+}
+
+func uploadLimitsOutput(limits configs.UploadLimits) models.UploadLimitsOutput {
+	return models.UploadLimitsOutput{
+		MaxBytes:     limits.MaxBytes,
+		MaxPixels:    limits.MaxPixels,
+		AllowedTypes: limits.AllowedTypes,
+		StorageQuota: limits.StorageQuota,
+	}
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/alvinmdj/mygram-api/models"
+	"github.com/alvinmdj/mygram-api/services"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type ExploreHdlInterface interface {
+	Get(c *gin.Context)
+}
+
+type ExploreHandler struct {
+	exploreSvc services.ExploreSvcInterface
+}
+
+func NewExploreHdl(exploreSvc services.ExploreSvcInterface) ExploreHdlInterface {
+	return &ExploreHandler{
+		exploreSvc: exploreSvc,
+	}
+}
+
+// Explore Get godoc
+// @Summary Get trending photos
+// @Description Get the recent photos of public users ranked by their likes, comments & unique commenters, decayed by their age. the rankings are recomputed periodically
+// @Tags explore
+// @Param Authorization header string true "format: Bearer token-here"
+// @Param limit query int false "page size (default 20, max 100)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Produce json
+// @Success 200 {object} models.PaginatedResponse{data=[]models.ExplorePhotoOutput}
+// @Failure 400 {object} models.ErrorResponse{}
+// @Router /api/v1/explore [get]
+func (e *ExploreHandler) Get(c *gin.Context) {
+	// get token claims in userData context from authentication middleware
+	// and cast the data type from any to jwt.MapClaims
+	userData := c.MustGet("userData").(jwt.MapClaims)
+	userId := uint(userData["id"].(float64))
+
+	pageInput := models.PageInput{}
+	c.ShouldBindQuery(&pageInput)
+	// explore is always ranked by score
+	pageInput.Sort = ""
+
+	photos, nextCursor, err := e.exploreSvc.GetExplore(userId, pageInput)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "BAD REQUEST",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	exploreResponse := []models.ExplorePhotoOutput{}
+	for _, photo := range photos {
+		explorePhoto := models.ExplorePhotoOutput{PhotoGetOutput: photoGetOutput(photo)}
+		if photo.Ranking != nil {
+			explorePhoto.Score = photo.Ranking.Score
+		}
+		exploreResponse = append(exploreResponse, explorePhoto)
+	}
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Data:       exploreResponse,
+		NextCursor: nextCursor,
+	})
+}
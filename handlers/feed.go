@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/alvinmdj/mygram-api/models"
+	"github.com/alvinmdj/mygram-api/services"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type FeedHdlInterface interface {
+	Get(c *gin.Context)
+}
+
+type FeedHandler struct {
+	feedSvc services.FeedSvcInterface
+}
+
+func NewFeedHdl(feedSvc services.FeedSvcInterface) FeedHdlInterface {
+	return &FeedHandler{
+		feedSvc: feedSvc,
+	}
+}
+
+// Feed Get godoc
+// @Summary Get my feed
+// @Description Get the photos of the users the logged in user follows, newest first, with a preview of their latest comments
+// @Tags feed
+// @Param Authorization header string true "format: Bearer token-here"
+// @Param limit query int false "page size (default 20, max 100)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Produce json
+// @Success 200 {object} models.PaginatedResponse{data=[]models.FeedPhotoOutput}
+// @Failure 400 {object} models.ErrorResponse{}
+// @Router /api/v1/feed [get]
+func (f *FeedHandler) Get(c *gin.Context) {
+	// get token claims in userData context from authentication middleware
+	// and cast the data type from any to jwt.MapClaims
+	userData := c.MustGet("userData").(jwt.MapClaims)
+	userId := uint(userData["id"].(float64))
+
+	pageInput := models.PageInput{}
+	c.ShouldBindQuery(&pageInput)
+	// the feed is always newest first
+	pageInput.Sort = ""
+
+	photos, nextCursor, err := f.feedSvc.GetFeed(userId, pageInput)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "BAD REQUEST",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	feedResponse := []models.FeedPhotoOutput{}
+	for _, photo := range photos {
+		feedPhoto := models.FeedPhotoOutput{
+			PhotoGetOutput: photoGetOutput(photo),
+			Comments:       []models.CommentGetOutput{},
+		}
+		for _, comment := range photo.Comments {
+			feedPhoto.Comments = append(feedPhoto.Comments, models.CommentGetOutput{
+				Base:     comment.Base,
+				Message:  comment.Message,
+				HiddenAt: comment.HiddenAt,
+				User: models.UserRegisterOutput{
+					Base:     comment.User.Base,
+					Username: comment.User.Username,
+					Email:    comment.User.Email,
+					Age:      comment.User.Age,
+				},
+			})
+		}
+		feedResponse = append(feedResponse, feedPhoto)
+	}
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Data:       feedResponse,
+		NextCursor: nextCursor,
+	})
+}
@@ -0,0 +1,305 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/alvinmdj/mygram-api/helpers"
+	"github.com/alvinmdj/mygram-api/models"
+	"github.com/alvinmdj/mygram-api/services"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+type FollowHdlInterface interface {
+	Follow(c *gin.Context)
+	Unfollow(c *gin.Context)
+	GetFollowers(c *gin.Context)
+	GetFollowing(c *gin.Context)
+	GetRequests(c *gin.Context)
+	AcceptRequest(c *gin.Context)
+	DeclineRequest(c *gin.Context)
+	UpdatePrivacy(c *gin.Context)
+}
+
+type FollowHandler struct {
+	followSvc services.FollowSvcInterface
+}
+
+func NewFollowHdl(followSvc services.FollowSvcInterface) FollowHdlInterface {
+	return &FollowHandler{
+		followSvc: followSvc,
+	}
+}
+
+// Follow Follow godoc
+// @Summary Follow user
+// @Description Follow a user, following a private user sends a follow request which the user has to accept
+// @Tags follows
+// @Produce json
+// @Param username path string true "follow user by username"
+// @Param Authorization header string true "format: Bearer token-here"
+// @Success 200 {object} models.FollowOutput{}
+// @Failure 400 {object} models.ErrorResponse{}
+// @Failure 404 {object} models.ErrorResponse{}
+// @Router /api/v1/users/{username}/follow [put]
+func (f *FollowHandler) Follow(c *gin.Context) {
+	// get token claims in userData context from authentication middleware
+	// and cast the data type from any to jwt.MapClaims
+	userData := c.MustGet("userData").(jwt.MapClaims)
+	userId := uint(userData["id"].(float64))
+
+	follow, err := f.followSvc.Follow(userId, c.Param("username"))
+	if followErrorResponse(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, follow)
+}
+
+// Follow Unfollow godoc
+// @Summary Unfollow user
+// @Description Unfollow a user or withdraw a follow request
+// @Tags follows
+// @Produce json
+// @Param username path string true "unfollow user by username"
+// @Param Authorization header string true "format: Bearer token-here"
+// @Success 200 {object} models.FollowOutput{}
+// @Failure 400 {object} models.ErrorResponse{}
+// @Failure 404 {object} models.ErrorResponse{}
+// @Router /api/v1/users/{username}/follow [delete]
+func (f *FollowHandler) Unfollow(c *gin.Context) {
+	userData := c.MustGet("userData").(jwt.MapClaims)
+	userId := uint(userData["id"].(float64))
+
+	follow, err := f.followSvc.Unfollow(userId, c.Param("username"))
+	if followErrorResponse(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, follow)
+}
+
+// Follow GetFollowers godoc
+// @Summary Get followers
+// @Description Get the followers of a user, the followers of a private user are only visible to the user and its followers
+// @Tags follows
+// @Produce json
+// @Param username path string true "get followers of user by username"
+// @Param Authorization header string true "format: Bearer token-here"
+// @Param limit query int false "page size (default 20, max 100)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "id, created_at or updated_at, prefix with - for descending order (default -created_at)"
+// @Success 200 {object} models.PaginatedResponse{data=[]models.FollowGetOutput}
+// @Failure 400 {object} models.ErrorResponse{}
+// @Failure 403 {object} models.ErrorResponse{}
+// @Failure 404 {object} models.ErrorResponse{}
+// @Router /api/v1/users/{username}/followers [get]
+func (f *FollowHandler) GetFollowers(c *gin.Context) {
+	userData := c.MustGet("userData").(jwt.MapClaims)
+	userId := uint(userData["id"].(float64))
+
+	pageInput := models.PageInput{}
+	c.ShouldBindQuery(&pageInput)
+
+	follows, nextCursor, err := f.followSvc.GetFollowers(c.Param("username"), userId, pageInput)
+	if followErrorResponse(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Data:       followsOutput(follows, func(follow models.Follow) models.User { return follow.Follower }),
+		NextCursor: nextCursor,
+	})
+}
+
+// Follow GetFollowing godoc
+// @Summary Get followed users
+// @Description Get the users a user follows, with the same visibility as the followers
+// @Tags follows
+// @Produce json
+// @Param username path string true "get users followed by user by username"
+// @Param Authorization header string true "format: Bearer token-here"
+// @Param limit query int false "page size (default 20, max 100)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "id, created_at or updated_at, prefix with - for descending order (default -created_at)"
+// @Success 200 {object} models.PaginatedResponse{data=[]models.FollowGetOutput}
+// @Failure 400 {object} models.ErrorResponse{}
+// @Failure 403 {object} models.ErrorResponse{}
+// @Failure 404 {object} models.ErrorResponse{}
+// @Router /api/v1/users/{username}/following [get]
+func (f *FollowHandler) GetFollowing(c *gin.Context) {
+	userData := c.MustGet("userData").(jwt.MapClaims)
+	userId := uint(userData["id"].(float64))
+
+	pageInput := models.PageInput{}
+	c.ShouldBindQuery(&pageInput)
+
+	follows, nextCursor, err := f.followSvc.GetFollowing(c.Param("username"), userId, pageInput)
+	if followErrorResponse(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Data:       followsOutput(follows, func(follow models.Follow) models.User { return follow.Following }),
+		NextCursor: nextCursor,
+	})
+}
+
+// Follow GetRequests godoc
+// @Summary Get my follow requests
+// @Description Get the pending follow requests to the logged in user
+// @Tags follows
+// @Produce json
+// @Param Authorization header string true "format: Bearer token-here"
+// @Param limit query int false "page size (default 20, max 100)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "id, created_at or updated_at, prefix with - for descending order (default -created_at)"
+// @Success 200 {object} models.PaginatedResponse{data=[]models.FollowGetOutput}
+// @Failure 400 {object} models.ErrorResponse{}
+// @Router /api/v1/users/me/follow-requests [get]
+func (f *FollowHandler) GetRequests(c *gin.Context) {
+	userData := c.MustGet("userData").(jwt.MapClaims)
+	userId := uint(userData["id"].(float64))
+
+	pageInput := models.PageInput{}
+	c.ShouldBindQuery(&pageInput)
+
+	follows, nextCursor, err := f.followSvc.GetRequests(userId, pageInput)
+	if followErrorResponse(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Data:       followsOutput(follows, func(follow models.Follow) models.User { return follow.Follower }),
+		NextCursor: nextCursor,
+	})
+}
+
+// Follow AcceptRequest godoc
+// @Summary Accept follow request
+// @Description Accept a pending follow request to the logged in user
+// @Tags follows
+// @Produce json
+// @Param userId path int true "id of the user who requested to follow"
+// @Param Authorization header string true "format: Bearer token-here"
+// @Success 200 {object} models.MessageResponse{}
+// @Failure 404 {object} models.ErrorResponse{}
+// @Router /api/v1/users/me/follow-requests/{userId}/accept [post]
+func (f *FollowHandler) AcceptRequest(c *gin.Context) {
+	userData := c.MustGet("userData").(jwt.MapClaims)
+	userId := uint(userData["id"].(float64))
+	followerId, _ := strconv.Atoi(c.Param("userId"))
+
+	err := f.followSvc.AcceptRequest(userId, uint(followerId))
+	if followErrorResponse(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{
+		Message: fmt.Sprintf("follow request of user with id %d has been accepted", followerId),
+	})
+}
+
+// Follow DeclineRequest godoc
+// @Summary Decline follow request
+// @Description Decline a pending follow request to the logged in user
+// @Tags follows
+// @Produce json
+// @Param userId path int true "id of the user who requested to follow"
+// @Param Authorization header string true "format: Bearer token-here"
+// @Success 200 {object} models.MessageResponse{}
+// @Failure 404 {object} models.ErrorResponse{}
+// @Router /api/v1/users/me/follow-requests/{userId} [delete]
+func (f *FollowHandler) DeclineRequest(c *gin.Context) {
+	userData := c.MustGet("userData").(jwt.MapClaims)
+	userId := uint(userData["id"].(float64))
+	followerId, _ := strconv.Atoi(c.Param("userId"))
+
+	err := f.followSvc.DeclineRequest(userId, uint(followerId))
+	if followErrorResponse(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{
+		Message: fmt.Sprintf("follow request of user with id %d has been declined", followerId),
+	})
+}
+
+// Follow UpdatePrivacy godoc
+// @Summary Update my privacy
+// @Description Make the account of the logged in user private or public, follow requests to a private account need approval and making it public accepts the pending requests
+// @Tags follows
+// @Accept json,mpfd
+// @Produce json
+// @Param models.UserPrivacyInput body models.UserPrivacyInput{} true "update privacy"
+// @Param Authorization header string true "format: Bearer token-here"
+// @Success 200 {object} models.UserProfileDetailOutput{}
+// @Failure 400 {object} models.ErrorResponse{}
+// @Router /api/v1/users/me/privacy [put]
+func (f *FollowHandler) UpdatePrivacy(c *gin.Context) {
+	contentType := helpers.GetContentType(c)
+	privacyInput := models.UserPrivacyInput{}
+
+	userData := c.MustGet("userData").(jwt.MapClaims)
+	userId := uint(userData["id"].(float64))
+
+	if contentType == helpers.AppJson {
+		c.ShouldBindJSON(&privacyInput)
+	} else {
+		c.ShouldBind(&privacyInput)
+	}
+
+	user, err := f.followSvc.UpdatePrivacy(userId, privacyInput)
+	if followErrorResponse(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, userProfileDetailOutput(user))
+}
+
+// followErrorResponse writes the response of a follow error, returns false if there is no error
+func followErrorResponse(c *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "NOT FOUND",
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrPrivateAccount):
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "FORBIDDEN",
+			Message: err.Error(),
+		})
+	default:
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "BAD REQUEST",
+			Message: err.Error(),
+		})
+	}
+	return true
+}
+
+// followsOutput returns the follows with the user given by user, i.e. the follower or the followed user
+func followsOutput(follows []models.Follow, user func(follow models.Follow) models.User) []models.FollowGetOutput {
+	followsResponse := []models.FollowGetOutput{}
+	for _, follow := range follows {
+		followUser := user(follow)
+		followsResponse = append(followsResponse, models.FollowGetOutput{
+			Base:       follow.Base,
+			Status:     follow.Status,
+			AcceptedAt: follow.AcceptedAt,
+			User: models.UserProfileOutput{
+				Base:     followUser.Base,
+				Username: followUser.Username,
+				Age:      followUser.Age,
+			},
+		})
+	}
+	return followsResponse
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/alvinmdj/mygram-api/models"
+	"github.com/alvinmdj/mygram-api/services"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+type ImageHdlInterface interface {
+	Get(c *gin.Context)
+	GetSignedURL(c *gin.Context)
+}
+
+type ImageHandler struct {
+	imageSvc services.ImageSvcInterface
+}
+
+func NewImageHdl(imageSvc services.ImageSvcInterface) ImageHdlInterface {
+	return &ImageHandler{
+		imageSvc: imageSvc,
+	}
+}
+
+// Image Get godoc
+// @Summary Get a transformed photo
+// @Description Get a photo resized, cropped (fit=cover) and re-encoded on the fly, the url must be signed (see the image-url endpoint)
+// @Tags images
+// @Produce jpeg,png
+// @Param photoId path string true "photo id"
+// @Param w query int false "max width"
+// @Param h query int false "max height"
+// @Param fit query string false "contain (default) or cover"
+// @Param fmt query string false "jpeg or png, defaults to the format of the photo"
+// @Param sig query string true "signature"
+// @Success 200 {file} binary
+// @Success 304
+// @Failure 400 {object} models.ErrorResponse{}
+// @Failure 403 {object} models.ErrorResponse{}
+// @Failure 404 {object} models.ErrorResponse{}
+// @Failure 415 {object} models.ErrorResponse{}
+// @Router /img/{photoId} [get]
+func (i *ImageHandler) Get(c *gin.Context) {
+	photoId, _ := strconv.Atoi(c.Param("photoId"))
+	transformInput := models.ImageTransformInput{}
+	c.ShouldBindQuery(&transformInput)
+
+	image, err := i.imageSvc.Transform(photoId, transformInput, c.Request.URL.Query(), c.GetHeader("If-None-Match"))
+	if err != nil {
+		imageErrorJSON(c, err)
+		return
+	}
+
+	c.Header("ETag", image.ETag)
+	c.Header("Cache-Control", "public, max-age=86400")
+	if image.NotModified {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, image.ContentType, image.Data)
+}
+
+// Image GetSignedURL godoc
+// @Summary Get the signed url of a transformed photo
+// @Description Get the signed url of a photo resized, cropped (fit=cover) and re-encoded on the fly, other users than the owner & staff can only request the presets (150x150 & 320x320 cover, w=640, w=1080)
+// @Tags images
+// @Produce json
+// @Param photoId path string true "photo id"
+// @Param w query int false "max width"
+// @Param h query int false "max height"
+// @Param fit query string false "contain (default) or cover"
+// @Param fmt query string false "jpeg or png, defaults to the format of the photo"
+// @Param Authorization header string true "format: Bearer token-here"
+// @Success 200 {object} models.ImageURLOutput{}
+// @Failure 400 {object} models.ErrorResponse{}
+// @Failure 403 {object} models.ErrorResponse{}
+// @Failure 404 {object} models.ErrorResponse{}
+// @Router /api/v1/photos/{photoId}/image-url [get]
+func (i *ImageHandler) GetSignedURL(c *gin.Context) {
+	userData := c.MustGet("userData").(jwt.MapClaims)
+	userId := uint(userData["id"].(float64))
+	role, _ := userData["role"].(string)
+
+	photoId, _ := strconv.Atoi(c.Param("photoId"))
+	transformInput := models.ImageTransformInput{}
+	c.ShouldBindQuery(&transformInput)
+
+	imageUrl, err := i.imageSvc.SignedURL(userId, role, photoId, transformInput)
+	if err != nil {
+		imageErrorJSON(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ImageURLOutput{
+		URL: imageUrl,
+	})
+}
+
+func imageErrorJSON(c *gin.Context, err error) {
+	if imageErrorResponse(c, err) {
+		return
+	}
+
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "NOT FOUND",
+			Message: "photo not found",
+		})
+	case errors.Is(err, services.ErrInvalidImageSignature), errors.Is(err, services.ErrImagePresetRequired):
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "FORBIDDEN",
+			Message: err.Error(),
+		})
+	default:
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "BAD REQUEST",
+			Message: err.Error(),
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/alvinmdj/mygram-api/models"
+	"github.com/alvinmdj/mygram-api/services"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type LikeHdlInterface interface {
+	GetAll(c *gin.Context)
+	Like(c *gin.Context)
+	Unlike(c *gin.Context)
+}
+
+type LikeHandler struct {
+	likeSvc services.LikeSvcInterface
+}
+
+func NewLikeHdl(likeSvc services.LikeSvcInterface) LikeHdlInterface {
+	return &LikeHandler{
+		likeSvc: likeSvc,
+	}
+}
+
+// Likes GetAll godoc
+// @Summary Get all likes of a photo
+// @Description Get the users who liked the photo, the latest like first
+// @Tags likes
+// @Param photoId path string true "get likes of the photo id"
+// @Param Authorization header string true "format: Bearer token-here"
+// @Param limit query int false "page size (default 20, max 100)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "id, created_at or updated_at, prefix with - for descending order (default -created_at)"
+// @Produce json
+// @Success 200 {object} models.PaginatedResponse{data=[]models.LikeGetOutput}
+// @Failure 400 {object} models.ErrorResponse{}
+// @Failure 404 {object} models.ErrorResponse{}
+// @Router /api/v1/photos/{photoId}/likes [get]
+func (l *LikeHandler) GetAll(c *gin.Context) {
+	photoId, _ := strconv.Atoi(c.Param("photoId"))
+
+	pageInput := models.PageInput{}
+	c.ShouldBindQuery(&pageInput)
+
+	likes, nextCursor, err := l.likeSvc.GetAll(photoId, pageInput)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "BAD REQUEST",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	likesResponse := []models.LikeGetOutput{}
+	for _, like := range likes {
+		likesResponse = append(likesResponse, models.LikeGetOutput{
+			Base: like.Base,
+			User: models.UserProfileOutput{
+				Base:     like.User.Base,
+				Username: like.User.Username,
+				Age:      like.User.Age,
+			},
+		})
+	}
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Data:       likesResponse,
+		NextCursor: nextCursor,
+	})
+}
+
+// Like Like godoc
+// @Summary Like photo
+// @Description Like a photo, liking a photo which is already liked has no effect
+// @Tags likes
+// @Param photoId path string true "like the photo id"
+// @Param Authorization header string true "format: Bearer token-here"
+// @Produce json
+// @Success 200 {object} models.LikeOutput{}
+// @Failure 400 {object} models.ErrorResponse{}
+// @Failure 404 {object} models.ErrorResponse{}
+// @Router /api/v1/photos/{photoId}/like [put]
+func (l *LikeHandler) Like(c *gin.Context) {
+	photoId, _ := strconv.Atoi(c.Param("photoId"))
+
+	// get token claims in userData context from authentication middleware
+	// and cast the data type from any to jwt.MapClaims
+	userData := c.MustGet("userData").(jwt.MapClaims)
+	userId := uint(userData["id"].(float64))
+
+	like, err := l.likeSvc.Like(photoId, userId)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "BAD REQUEST",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, like)
+}
+
+// Like Unlike godoc
+// @Summary Unlike photo
+// @Description Remove the like of a photo, unliking a photo which isn't liked has no effect
+// @Tags likes
+// @Param photoId path string true "unlike the photo id"
+// @Param Authorization header string true "format: Bearer token-here"
+// @Produce json
+// @Success 200 {object} models.LikeOutput{}
+// @Failure 400 {object} models.ErrorResponse{}
+// @Failure 404 {object} models.ErrorResponse{}
+// @Router /api/v1/photos/{photoId}/like [delete]
+func (l *LikeHandler) Unlike(c *gin.Context) {
+	photoId, _ := strconv.Atoi(c.Param("photoId"))
+
+	// get token claims in userData context from authentication middleware
+	// and cast the data type from any to jwt.MapClaims
+	userData := c.MustGet("userData").(jwt.MapClaims)
+	userId := uint(userData["id"].(float64))
+
+	like, err := l.likeSvc.Unlike(photoId, userId)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "BAD REQUEST",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, like)
+}
@@ -1,25 +1,34 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
-	"path/filepath"
 	"strconv"
 
 	"github.com/alvinmdj/mygram-api/helpers"
+	"github.com/alvinmdj/mygram-api/images"
 	"github.com/alvinmdj/mygram-api/models"
 	"github.com/alvinmdj/mygram-api/services"
+	"github.com/alvinmdj/mygram-api/storages"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
 )
 
 type PhotoHdlInterface interface {
 	GetAll(c *gin.Context)
 	GetOneById(c *gin.Context)
 	Create(c *gin.Context)
+	CreateUpload(c *gin.Context)
 	Update(c *gin.Context)
 	Delete(c *gin.Context)
+	Restore(c *gin.Context)
+	GetVersions(c *gin.Context)
+	Revert(c *gin.Context)
+	UpdateSettings(c *gin.Context)
 }
 
 type PhotoHandler struct {
@@ -34,15 +43,27 @@ func NewPhotoHdl(photoSvc services.PhotoSvcInterface) PhotoHdlInterface {
 
 // Photo GetAll godoc
 // @Summary Get all photos
-// @Description Get all photos
+// @Description Get all photos, photos of private accounts are only visible to their accepted followers
 // @Tags photos
 // @Param Authorization header string true "format: Bearer token-here"
+// @Param limit query int false "page size (default 20, max 100)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "id, created_at, updated_at or like_count, prefix with - for descending order (default -created_at)"
 // @Produce json
-// @Success 200 {object} []models.PhotoGetOutput{}
+// @Success 200 {object} models.PaginatedResponse{data=[]models.PhotoGetOutput}
 // @Failure 400 {object} models.ErrorResponse{}
 // @Router /api/v1/photos [get]
 func (p *PhotoHandler) GetAll(c *gin.Context) {
-	photos, err := p.photoSvc.GetAll()
+	// get token claims in userData context from authentication middleware
+	// and cast the data type from any to jwt.MapClaims
+	userData := c.MustGet("userData").(jwt.MapClaims)
+	userId := uint(userData["id"].(float64))
+	role, _ := userData["role"].(string)
+
+	pageInput := models.PageInput{}
+	c.ShouldBindQuery(&pageInput)
+
+	photos, nextCursor, err := p.photoSvc.GetAll(pageInput, userId, models.IsStaff(role))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
 			Error:   "BAD REQUEST",
@@ -53,26 +74,17 @@ func (p *PhotoHandler) GetAll(c *gin.Context) {
 
 	photosResponse := []models.PhotoGetOutput{}
 	for _, photo := range photos {
-		photoOutput := models.PhotoGetOutput{
-			Base:     photo.Base,
-			Title:    photo.Title,
-			Caption:  photo.Caption,
-			PhotoURL: photo.PhotoURL,
-			User: models.UserRegisterOutput{
-				Base:     photo.User.Base,
-				Username: photo.User.Username,
-				Email:    photo.User.Email,
-				Age:      photo.User.Age,
-			},
-		}
-		photosResponse = append(photosResponse, photoOutput)
+		photosResponse = append(photosResponse, photoGetOutput(photo))
 	}
-	c.JSON(http.StatusOK, photosResponse)
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Data:       photosResponse,
+		NextCursor: nextCursor,
+	})
 }
 
 // Photo GetOneById godoc
 // @Summary Get one photo by id
-// @Description Get one photo by id
+// @Description Get one photo by id, photos of private accounts are only visible to their accepted followers
 // @Tags photos
 // @Param photoId path string true "get photo by id"
 // @Param Authorization header string true "format: Bearer token-here"
@@ -83,7 +95,13 @@ func (p *PhotoHandler) GetAll(c *gin.Context) {
 func (p *PhotoHandler) GetOneById(c *gin.Context) {
 	photoId, _ := strconv.Atoi(c.Param("photoId"))
 
-	photo, err := p.photoSvc.GetOneById(photoId)
+	// get token claims in userData context from authentication middleware
+	// and cast the data type from any to jwt.MapClaims
+	userData := c.MustGet("userData").(jwt.MapClaims)
+	userId := uint(userData["id"].(float64))
+	role, _ := userData["role"].(string)
+
+	photo, err := p.photoSvc.GetOneById(photoId, userId, models.IsStaff(role))
 	if err != nil {
 		c.JSON(http.StatusNotFound, models.ErrorResponse{
 			Error:   "NOT FOUND",
@@ -92,33 +110,26 @@ func (p *PhotoHandler) GetOneById(c *gin.Context) {
 		return
 	}
 
-	photoResponse := models.PhotoGetOutput{
-		Base:     photo.Base,
-		Title:    photo.Title,
-		Caption:  photo.Caption,
-		PhotoURL: photo.PhotoURL,
-		User: models.UserRegisterOutput{
-			Base:     photo.User.Base,
-			Username: photo.User.Username,
-			Email:    photo.User.Email,
-			Age:      photo.User.Age,
-		},
-	}
-	c.JSON(http.StatusOK, photoResponse)
+	c.JSON(http.StatusOK, photoGetOutput(photo))
 }
 
 // Photo Create godoc
 // @Summary Create photos
-// @Description Create photos
+// @Description Create photos from an uploaded file (multipart) or from a file uploaded with a presigned url (json with upload_key)
 // @Tags photos
-// @Accept mpfd
+// @Accept json,mpfd
 // @Produce json
-// @Param models.PhotoCreateInput formData models.PhotoCreateInputSwagger true "create photo"
-// @Param photo formData file true "upload photo"
+// @Param models.PhotoCreateInput formData models.PhotoCreateInputSwagger false "create photo"
+// @Param photo formData file false "upload photo"
+// @Param models.PhotoCreateFromUploadInput body models.PhotoCreateFromUploadInput false "create photo from upload"
 // @Param Authorization header string true "format: Bearer token-here"
 // @Success 201 {object} models.PhotoCreateOutput{}
 // @Failure 400 {object} models.ErrorResponse{}
+// @Failure 404 {object} models.ErrorResponse{}
 // @Failure 413 {object} models.ErrorResponse{}
+// @Failure 415 {object} models.ErrorResponse{}
+// @Failure 422 {object} models.ErrorResponse{}
+// @Failure 507 {object} models.ErrorResponse{}
 // @Router /api/v1/photos [post]
 func (p *PhotoHandler) Create(c *gin.Context) {
 	contentType := helpers.GetContentType(c)
@@ -129,17 +140,14 @@ func (p *PhotoHandler) Create(c *gin.Context) {
 	userData := c.MustGet("userData").(jwt.MapClaims)
 	userId := uint(userData["id"].(float64))
 	photoInput.UserID = userId
+	photoInput.Role, _ = userData["role"].(string) // selects the upload limits
 
-	// only accept multipart/form-data
+	// json creates the photo from a file uploaded with a presigned url
 	if contentType == helpers.AppJson {
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Error:   "BAD REQUEST",
-			Message: "invalid content type",
-		})
+		p.createFromUpload(c, photoInput)
 		return
-	} else {
-		c.ShouldBind(&photoInput)
 	}
+	c.ShouldBind(&photoInput)
 
 	// photo source, check if photo is uploaded
 	photoFileHeader, err := c.FormFile("photo")
@@ -152,18 +160,72 @@ func (p *PhotoHandler) Create(c *gin.Context) {
 		return
 	}
 
-	// Check if the file is an image
-	ext := filepath.Ext(photoFileHeader.Filename)
-	if ext != ".jpg" && ext != ".jpeg" && ext != ".png" && ext != ".webp" {
+	// open the file, its content is validated by the photo service
+	photoFile, err := photoFileHeader.Open()
+	if err != nil {
+		log.Printf("error opening file: %v", err)
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
 			Error:   "BAD REQUEST",
-			Message: "invalid file type",
+			Message: "cannot read photo file",
 		})
 		return
 	}
+	defer photoFile.Close()
+	photoInput.FileSize = photoFileHeader.Size
 
-	photo, err := p.photoSvc.Create(photoInput, photoFileHeader)
+	photo, err := p.photoSvc.Create(photoInput, photoFile)
 	if err != nil {
+		if imageErrorResponse(c, err) {
+			return
+		}
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "BAD REQUEST",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	photoResponse := models.PhotoCreateOutput{
+		Base:          photo.Base,
+		Title:         photo.Title,
+		Caption:       photo.Caption,
+		PhotoURL:      photo.PhotoURL,
+		CommentPolicy: photo.CommentPolicy,
+		Metadata:      photoMetadataOutput(photo),
+		Variants:      photoVariantsOutput(photo),
+		UserID:        photo.UserID,
+	}
+	c.JSON(http.StatusCreated, photoResponse)
+}
+
+// createFromUpload creates a photo from the upload_key of a json request
+func (p *PhotoHandler) createFromUpload(c *gin.Context, photoInput models.PhotoCreateInput) {
+	uploadInput := models.PhotoCreateFromUploadInput{}
+	c.ShouldBindJSON(&uploadInput)
+	if uploadInput.UploadKey == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "BAD REQUEST",
+			Message: "upload key is required",
+		})
+		return
+	}
+
+	photoInput.Title = uploadInput.Title
+	photoInput.Caption = uploadInput.Caption
+	photoInput.KeepMetadata = uploadInput.KeepMetadata
+
+	photo, err := p.photoSvc.CreateFromUpload(photoInput, uploadInput.UploadKey)
+	if err != nil {
+		if imageErrorResponse(c, err) {
+			return
+		}
+		if errors.Is(err, services.ErrUploadNotFound) {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "NOT FOUND",
+				Message: err.Error(),
+			})
+			return
+		}
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
 			Error:   "BAD REQUEST",
 			Message: err.Error(),
@@ -172,18 +234,71 @@ func (p *PhotoHandler) Create(c *gin.Context) {
 	}
 
 	photoResponse := models.PhotoCreateOutput{
-		Base:     photo.Base,
-		Title:    photo.Title,
-		Caption:  photo.Caption,
-		PhotoURL: photo.PhotoURL,
-		UserID:   photo.UserID,
+		Base:          photo.Base,
+		Title:         photo.Title,
+		Caption:       photo.Caption,
+		PhotoURL:      photo.PhotoURL,
+		CommentPolicy: photo.CommentPolicy,
+		Metadata:      photoMetadataOutput(photo),
+		Variants:      photoVariantsOutput(photo),
+		UserID:        photo.UserID,
 	}
 	c.JSON(http.StatusCreated, photoResponse)
 }
 
+// Photo CreateUpload godoc
+// @Summary Create photo upload url
+// @Description Create a presigned url to upload a photo file directly to the storage, create the photo afterwards with the returned upload_key
+// @Tags photos
+// @Accept json
+// @Produce json
+// @Param models.PhotoUploadInput body models.PhotoUploadInput true "photo file to upload"
+// @Param Authorization header string true "format: Bearer token-here"
+// @Success 201 {object} models.PhotoUploadOutput{}
+// @Failure 400 {object} models.ErrorResponse{}
+// @Failure 413 {object} models.ErrorResponse{}
+// @Failure 501 {object} models.ErrorResponse{}
+// @Router /api/v1/photos/uploads [post]
+func (p *PhotoHandler) CreateUpload(c *gin.Context) {
+	uploadInput := models.PhotoUploadInput{}
+
+	userData := c.MustGet("userData").(jwt.MapClaims)
+	userId := uint(userData["id"].(float64))
+
+	if err := c.ShouldBindJSON(&uploadInput); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "BAD REQUEST",
+			Message: "invalid request body",
+		})
+		return
+	}
+	uploadInput.Role, _ = userData["role"].(string)
+
+	upload, err := p.photoSvc.PresignUpload(userId, uploadInput)
+	if err != nil {
+		if imageErrorResponse(c, err) {
+			return
+		}
+		if errors.Is(err, storages.ErrPresignNotSupported) {
+			c.JSON(http.StatusNotImplemented, models.ErrorResponse{
+				Error:   "NOT IMPLEMENTED",
+				Message: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "BAD REQUEST",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, upload)
+}
+
 // Photo Update godoc
 // @Summary Update photo
-// @Description Update photo
+// @Description Update photo, the previous title, caption & file are kept as a version which can be reverted to
 // @Tags photos
 // @Accept json,mpfd
 // @Produce json
@@ -194,6 +309,10 @@ func (p *PhotoHandler) Create(c *gin.Context) {
 // @Success 200 {object} models.PhotoUpdateOutput{}
 // @Failure 400 {object} models.ErrorResponse{}
 // @Failure 403 {object} models.ErrorResponse{}
+// @Failure 413 {object} models.ErrorResponse{}
+// @Failure 415 {object} models.ErrorResponse{}
+// @Failure 422 {object} models.ErrorResponse{}
+// @Failure 507 {object} models.ErrorResponse{}
 // @Router /api/v1/photos/{photoId} [put]
 func (p *PhotoHandler) Update(c *gin.Context) {
 	photoId, _ := strconv.Atoi(c.Param("photoId"))
@@ -205,9 +324,10 @@ func (p *PhotoHandler) Update(c *gin.Context) {
 	userData := c.MustGet("userData").(jwt.MapClaims)
 	userId := uint(userData["id"].(float64))
 
-	// store id and user id to input struct
+	// store id, the id & role of the user to input struct, the photo keeps its owner when staff update it
 	photoInput.ID = uint(photoId)
 	photoInput.UserID = userId
+	photoInput.Role, _ = userData["role"].(string)
 
 	// only accept multipart/form-data
 	if contentType == helpers.AppJson {
@@ -222,21 +342,28 @@ func (p *PhotoHandler) Update(c *gin.Context) {
 
 	// photo source, check if photo is uploaded
 	// new photo is not mandatory for update
+	var photoFile io.Reader
 	photoFileHeader, _ := c.FormFile("photo")
 	if photoFileHeader != nil {
-		// Check if the file is an image
-		ext := filepath.Ext(photoFileHeader.Filename)
-		if ext != ".jpg" && ext != ".jpeg" && ext != ".png" && ext != ".webp" {
+		file, err := photoFileHeader.Open()
+		if err != nil {
+			log.Printf("error opening file: %v", err)
 			c.JSON(http.StatusBadRequest, models.ErrorResponse{
 				Error:   "BAD REQUEST",
-				Message: "invalid file type",
+				Message: "cannot read photo file",
 			})
 			return
 		}
+		defer file.Close()
+		photoFile = file
+		photoInput.FileSize = photoFileHeader.Size
 	}
 
-	photo, err := p.photoSvc.Update(photoInput, photoFileHeader)
+	photo, err := p.photoSvc.Update(photoInput, photoFile)
 	if err != nil {
+		if imageErrorResponse(c, err) {
+			return
+		}
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
 			Error:   "BAD REQUEST",
 			Message: err.Error(),
@@ -245,18 +372,21 @@ func (p *PhotoHandler) Update(c *gin.Context) {
 	}
 
 	photoResponse := models.PhotoUpdateOutput{
-		Base:     photo.Base,
-		Title:    photo.Title,
-		Caption:  photo.Caption,
-		PhotoURL: photo.PhotoURL,
-		UserID:   photo.UserID,
+		Base:          photo.Base,
+		Title:         photo.Title,
+		Caption:       photo.Caption,
+		PhotoURL:      photo.PhotoURL,
+		CommentPolicy: photo.CommentPolicy,
+		Metadata:      photoMetadataOutput(photo),
+		Variants:      photoVariantsOutput(photo),
+		UserID:        photo.UserID,
 	}
 	c.JSON(http.StatusOK, photoResponse)
 }
 
 // Photo Delete godoc
 // @Summary Delete photo
-// @Description Delete photo
+// @Description Move photo to the trash, it can be restored until it is purged after the retention period
 // @Tags photos
 // @Produce json
 // @Param photoId path string true "delete photo by id"
@@ -266,9 +396,11 @@ func (p *PhotoHandler) Update(c *gin.Context) {
 // @Failure 404 {object} models.ErrorResponse{}
 // @Router /api/v1/photos/{photoId} [delete]
 func (p *PhotoHandler) Delete(c *gin.Context) {
+	userData := c.MustGet("userData").(jwt.MapClaims)
+	userId := uint(userData["id"].(float64))
 	photoId, _ := strconv.Atoi(c.Param("photoId"))
 
-	if err := p.photoSvc.Delete(photoId); err != nil {
+	if err := p.photoSvc.Delete(photoId, userId); err != nil {
 		c.JSON(http.StatusNotFound, models.ErrorResponse{
 			Error:   "NOT FOUND",
 			Message: err.Error(),
@@ -280,3 +412,269 @@ func (p *PhotoHandler) Delete(c *gin.Context) {
 		Message: fmt.Sprintf("photo data with id %d has been deleted", photoId),
 	})
 }
+
+// Photo Restore godoc
+// @Summary Restore photo
+// @Description Restore a photo from the trash, the owner can only restore photos they deleted themselves (staff can restore any)
+// @Tags photos
+// @Produce json
+// @Param photoId path string true "restore photo by id"
+// @Param Authorization header string true "format: Bearer token-here"
+// @Success 200 {object} models.PhotoGetOutput{}
+// @Failure 403 {object} models.ErrorResponse{}
+// @Failure 404 {object} models.ErrorResponse{}
+// @Router /api/v1/photos/{photoId}/restore [post]
+func (p *PhotoHandler) Restore(c *gin.Context) {
+	photoId, _ := strconv.Atoi(c.Param("photoId"))
+
+	// get token claims in userData context from authentication middleware
+	// and cast the data type from any to jwt.MapClaims
+	userData := c.MustGet("userData").(jwt.MapClaims)
+	userId := uint(userData["id"].(float64))
+
+	photo, err := p.photoSvc.Restore(photoId, userId)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "NOT FOUND",
+			Message: "photo data isn't in the trash",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, photoGetOutput(photo))
+}
+
+// Photo GetVersions godoc
+// @Summary Get photo versions
+// @Description Get the previous versions of a photo, the latest version first
+// @Tags photos
+// @Produce json
+// @Param photoId path string true "get versions of photo by id"
+// @Param Authorization header string true "format: Bearer token-here"
+// @Success 200 {object} []models.PhotoVersionOutput{}
+// @Failure 403 {object} models.ErrorResponse{}
+// @Failure 404 {object} models.ErrorResponse{}
+// @Router /api/v1/photos/{photoId}/versions [get]
+func (p *PhotoHandler) GetVersions(c *gin.Context) {
+	photoId, _ := strconv.Atoi(c.Param("photoId"))
+
+	versions, err := p.photoSvc.GetVersions(photoId)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "NOT FOUND",
+			Message: "photo data not found",
+		})
+		return
+	}
+
+	versionsResponse := []models.PhotoVersionOutput{}
+	for _, version := range versions {
+		versionsResponse = append(versionsResponse, photoVersionOutput(version))
+	}
+
+	c.JSON(http.StatusOK, versionsResponse)
+}
+
+// Photo Revert godoc
+// @Summary Revert photo
+// @Description Revert a photo to a previous version, the current state is kept as a new version
+// @Tags photos
+// @Produce json
+// @Param photoId path string true "revert photo by id"
+// @Param version path int true "version to revert to"
+// @Param Authorization header string true "format: Bearer token-here"
+// @Success 200 {object} models.PhotoGetOutput{}
+// @Failure 400 {object} models.ErrorResponse{}
+// @Failure 403 {object} models.ErrorResponse{}
+// @Failure 404 {object} models.ErrorResponse{}
+// @Router /api/v1/photos/{photoId}/versions/{version}/revert [post]
+func (p *PhotoHandler) Revert(c *gin.Context) {
+	photoId, _ := strconv.Atoi(c.Param("photoId"))
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "BAD REQUEST",
+			Message: "invalid version",
+		})
+		return
+	}
+
+	// get token claims in userData context from authentication middleware
+	// and cast the data type from any to jwt.MapClaims
+	userData := c.MustGet("userData").(jwt.MapClaims)
+	userId := uint(userData["id"].(float64))
+
+	photo, err := p.photoSvc.Revert(photoId, version, userId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "NOT FOUND",
+			Message: fmt.Sprintf("version %d of photo data with id %d not found", version, photoId),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "BAD REQUEST",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, photoGetOutput(photo))
+}
+
+// Photo UpdateSettings godoc
+// @Summary Update photo settings
+// @Description Update the settings of a photo, e.g. who may comment on it (everyone, followers or off)
+// @Tags photos
+// @Accept json,mpfd
+// @Produce json
+// @Param photoId path string true "update photo settings by id"
+// @Param models.PhotoSettingsInput body models.PhotoSettingsInputSwagger{} true "update photo settings"
+// @Param Authorization header string true "format: Bearer token-here"
+// @Success 200 {object} models.PhotoUpdateOutput{}
+// @Failure 400 {object} models.ErrorResponse{}
+// @Failure 403 {object} models.ErrorResponse{}
+// @Router /api/v1/photos/{photoId}/settings [put]
+func (p *PhotoHandler) UpdateSettings(c *gin.Context) {
+	photoId, _ := strconv.Atoi(c.Param("photoId"))
+	contentType := helpers.GetContentType(c)
+	settingsInput := models.PhotoSettingsInput{}
+
+	if contentType == helpers.AppJson {
+		c.ShouldBindJSON(&settingsInput)
+	} else {
+		c.ShouldBind(&settingsInput)
+	}
+
+	// store id to input struct
+	settingsInput.ID = uint(photoId)
+
+	photo, err := p.photoSvc.UpdateSettings(settingsInput)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "BAD REQUEST",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	photoResponse := models.PhotoUpdateOutput{
+		Base:          photo.Base,
+		Title:         photo.Title,
+		Caption:       photo.Caption,
+		PhotoURL:      photo.PhotoURL,
+		CommentPolicy: photo.CommentPolicy,
+		Metadata:      photoMetadataOutput(photo),
+		Variants:      photoVariantsOutput(photo),
+		UserID:        photo.UserID,
+	}
+	c.JSON(http.StatusOK, photoResponse)
+}
+
+// imageErrorResponse responds to a rejected photo file (invalid or over quota), returns false if err isn't caused by the file
+func imageErrorResponse(c *gin.Context, err error) bool {
+	if errors.Is(err, services.ErrQuotaExceeded) {
+		c.JSON(http.StatusInsufficientStorage, models.ErrorResponse{
+			Error:   "INSUFFICIENT STORAGE",
+			Message: err.Error(),
+			Code:    "quota_exceeded",
+		})
+		return true
+	}
+
+	var imageErr *images.Error
+	if !errors.As(err, &imageErr) {
+		return false
+	}
+
+	switch imageErr.Code {
+	case images.CodeTooLarge:
+		c.JSON(http.StatusRequestEntityTooLarge, models.ErrorResponse{
+			Error:   "REQUEST ENTITY TOO LARGE",
+			Message: imageErr.Message,
+			Code:    imageErr.Code,
+		})
+	case images.CodeUnsupportedType:
+		c.JSON(http.StatusUnsupportedMediaType, models.ErrorResponse{
+			Error:   "UNSUPPORTED MEDIA TYPE",
+			Message: imageErr.Message,
+			Code:    imageErr.Code,
+		})
+	default:
+		c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse{
+			Error:   "UNPROCESSABLE ENTITY",
+			Message: imageErr.Message,
+			Code:    imageErr.Code,
+		})
+	}
+	return true
+}
+
+func photoGetOutput(photo models.Photo) models.PhotoGetOutput {
+	return models.PhotoGetOutput{
+		Base:          photo.Base,
+		Title:         photo.Title,
+		Caption:       photo.Caption,
+		PhotoURL:      photo.PhotoURL,
+		CommentPolicy: photo.CommentPolicy,
+		Metadata:      photoMetadataOutput(photo),
+		Variants:      photoVariantsOutput(photo),
+		LikeCount:     photo.LikeCount,
+		LikedByMe:     photo.LikedByMe,
+		User: models.UserRegisterOutput{
+			Base:     photo.User.Base,
+			Username: photo.User.Username,
+			Email:    photo.User.Email,
+			Age:      photo.User.Age,
+		},
+	}
+}
+
+// photoMetadataOutput returns the kept metadata of a photo, nil if nothing was kept
+func photoMetadataOutput(photo models.Photo) *models.PhotoMetadataOutput {
+	if photo.TakenAt == nil && photo.Orientation == 0 && photo.CameraModel == "" {
+		return nil
+	}
+	return &models.PhotoMetadataOutput{
+		TakenAt:     photo.TakenAt,
+		Orientation: photo.Orientation,
+		CameraModel: photo.CameraModel,
+	}
+}
+
+func photoVersionOutput(version models.PhotoVersion) models.PhotoVersionOutput {
+	output := models.PhotoVersionOutput{
+		Version:   version.Version,
+		Title:     version.Title,
+		Caption:   version.Caption,
+		PhotoURL:  version.PhotoURL,
+		CreatedAt: version.CreatedAt,
+	}
+	if version.TakenAt != nil || version.Orientation != 0 || version.CameraModel != "" {
+		output.Metadata = &models.PhotoMetadataOutput{
+			TakenAt:     version.TakenAt,
+			Orientation: version.Orientation,
+			CameraModel: version.CameraModel,
+		}
+	}
+	if len(version.Variants) > 0 {
+		output.Variants = map[string]string{}
+		for _, variant := range version.Variants {
+			output.Variants[strconv.Itoa(variant.Size)] = variant.URL
+		}
+	}
+	return output
+}
+
+// photoVariantsOutput returns the urls of the photo variants by size
+func photoVariantsOutput(photo models.Photo) map[string]string {
+	if len(photo.Variants) == 0 {
+		return nil
+	}
+	variants := map[string]string{}
+	for _, variant := range photo.Variants {
+		variants[strconv.Itoa(variant.Size)] = variant.URL
+	}
+	return variants
+}
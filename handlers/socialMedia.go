@@ -35,12 +35,18 @@ func NewSocialMediaHdl(socialMediaSvc services.SocialMediaSvcInterface) SocialMe
 // @Description Get all social media
 // @Tags socialMedias
 // @Param Authorization header string true "format: Bearer token-here"
+// @Param limit query int false "page size (default 20, max 100)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "id, created_at or updated_at, prefix with - for descending order (default -created_at)"
 // @Produce json
-// @Success 200 {object} []models.SocialMediaGetOutput{}
+// @Success 200 {object} models.PaginatedResponse{data=[]models.SocialMediaGetOutput}
 // @Failure 400 {object} models.ErrorResponse{}
 // @Router /api/v1/social-medias [get]
 func (s *SocialMediaHandler) GetAll(c *gin.Context) {
-	socialMedias, err := s.socialMediaSvc.GetAll()
+	pageInput := models.PageInput{}
+	c.ShouldBindQuery(&pageInput)
+
+	socialMedias, nextCursor, err := s.socialMediaSvc.GetAll(pageInput)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
 			Error:   "BAD REQUEST",
@@ -64,7 +70,10 @@ func (s *SocialMediaHandler) GetAll(c *gin.Context) {
 		}
 		socialMediasResponse = append(socialMediasResponse, socialMediaOutput)
 	}
-	c.JSON(http.StatusOK, socialMediasResponse)
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Data:       socialMediasResponse,
+		NextCursor: nextCursor,
+	})
 }
 
 // Social Media GetOneById godoc
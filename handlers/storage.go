@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/alvinmdj/mygram-api/models"
+	"github.com/alvinmdj/mygram-api/storages"
+	"github.com/gin-gonic/gin"
+)
+
+type LocalStorageHdlInterface interface {
+	Get(c *gin.Context)
+	Put(c *gin.Context)
+}
+
+// LocalStorageHandler serves the files of the local storage and receives direct uploads to it,
+// S3 compatible storages do both themselves
+type LocalStorageHandler struct {
+	localStorage *storages.LocalStorage
+}
+
+func NewLocalStorageHdl(localStorage *storages.LocalStorage) LocalStorageHdlInterface {
+	return &LocalStorageHandler{
+		localStorage: localStorage,
+	}
+}
+
+// LocalStorage Get godoc
+// @Summary Get a file of the local storage
+// @Description Get a stored photo (or variant) file, direct uploads aren't served before a photo is created from them
+// @Tags uploads
+// @Produce jpeg,png
+// @Param key path string true "storage key"
+// @Success 200 {file} binary
+// @Failure 404 {object} models.ErrorResponse{}
+// @Router /uploads/{key} [get]
+func (l *LocalStorageHandler) Get(c *gin.Context) {
+	// cleaned like the storage does, so e.g. "//staging/..." can't get around the check below
+	key := strings.TrimPrefix(path.Clean("/"+c.Param("key")), "/")
+
+	// staged uploads aren't validated yet and still have their metadata (e.g. GPS coordinates),
+	// compared case insensitive for case insensitive file systems
+	if strings.HasPrefix(strings.ToLower(key), storages.StagingPrefix) {
+		localStorageNotFound(c)
+		return
+	}
+
+	info, err := l.localStorage.Stat(c.Request.Context(), key)
+	if err != nil {
+		localStorageNotFound(c)
+		return
+	}
+	file, err := l.localStorage.Get(c.Request.Context(), key)
+	if err != nil {
+		localStorageNotFound(c)
+		return
+	}
+	defer file.Close()
+
+	// the type comes from the (validated) extension, browsers must not guess another one from the content
+	contentType := info.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("X-Content-Type-Options", "nosniff")
+
+	// local storage files are *os.File, ServeContent handles ranges & conditional requests
+	http.ServeContent(c.Writer, c.Request, "", info.LastModified, file.(io.ReadSeeker))
+}
+
+func localStorageNotFound(c *gin.Context) {
+	c.JSON(http.StatusNotFound, models.ErrorResponse{
+		Error:   "NOT FOUND",
+		Message: "file not found",
+	})
+}
+
+// LocalStorage Put godoc
+// @Summary Direct upload to the local storage
+// @Description Upload a file with a presigned url returned by POST /api/v1/photos/uploads
+// @Tags uploads
+// @Accept octet-stream
+// @Param key path string true "upload key"
+// @Param expires query int true "expiry (unix time)"
+// @Param sig query string true "signature"
+// @Success 200
+// @Failure 403 {object} models.ErrorResponse{}
+// @Router /uploads/{key} [put]
+func (l *LocalStorageHandler) Put(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+
+	// the signature covers the content type & length, the body can't be longer than the signed length
+	err := l.localStorage.VerifyPresignedPut(key, c.Request.URL.Query(), c.ContentType(), c.Request.ContentLength)
+	if err != nil {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "FORBIDDEN",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	body := io.LimitReader(c.Request.Body, c.Request.ContentLength)
+	if err := l.localStorage.Put(c.Request.Context(), key, body, c.Request.ContentLength, c.ContentType()); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "INTERNAL SERVER ERROR",
+			Message: "error storing the uploaded file",
+		})
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
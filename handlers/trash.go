@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/alvinmdj/mygram-api/models"
+	"github.com/alvinmdj/mygram-api/services"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type TrashHdlInterface interface {
+	GetMine(c *gin.Context)
+}
+
+type TrashHandler struct {
+	trashSvc services.TrashSvcInterface
+}
+
+func NewTrashHdl(trashSvc services.TrashSvcInterface) TrashHdlInterface {
+	return &TrashHandler{
+		trashSvc: trashSvc,
+	}
+}
+
+// Trash GetMine godoc
+// @Summary Get my trash
+// @Description Get the deleted photos & comments of the logged in user, they can be restored until purge_at
+// @Tags users
+// @Produce json
+// @Param Authorization header string true "format: Bearer token-here"
+// @Success 200 {object} models.TrashOutput{}
+// @Failure 400 {object} models.ErrorResponse{}
+// @Router /api/v1/users/me/trash [get]
+func (t *TrashHandler) GetMine(c *gin.Context) {
+	userData := c.MustGet("userData").(jwt.MapClaims)
+	userId := uint(userData["id"].(float64))
+
+	trash, err := t.trashSvc.GetTrash(userId)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "BAD REQUEST",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, trash)
+}
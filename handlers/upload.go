@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/alvinmdj/mygram-api/models"
+	"github.com/alvinmdj/mygram-api/services"
+	"github.com/alvinmdj/mygram-api/uploads"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// tus resumable upload protocol, see https://tus.io/protocols/resumable-upload
+const (
+	tusVersion     = "1.0.0"
+	tusExtensions  = "creation,termination,expiration"
+	tusContentType = "application/offset+octet-stream"
+)
+
+type UploadHdlInterface interface {
+	Options(c *gin.Context)
+	Create(c *gin.Context)
+	Head(c *gin.Context)
+	Patch(c *gin.Context)
+	Delete(c *gin.Context)
+}
+
+type UploadHandler struct {
+	uploadSvc services.UploadSvcInterface
+}
+
+func NewUploadHdl(uploadSvc services.UploadSvcInterface) UploadHdlInterface {
+	return &UploadHandler{
+		uploadSvc: uploadSvc,
+	}
+}
+
+// Upload Options godoc
+// @Summary Resumable upload capabilities
+// @Description tus protocol discovery: supported version, extensions & max upload size
+// @Tags uploads
+// @Success 204
+// @Router /api/v1/uploads [options]
+func (u *UploadHandler) Options(c *gin.Context) {
+	c.Header("Tus-Resumable", tusVersion)
+	c.Header("Tus-Version", tusVersion)
+	c.Header("Tus-Extension", tusExtensions)
+	c.Header("Tus-Max-Size", strconv.FormatInt(u.uploadSvc.MaxSize(), 10))
+	c.Status(http.StatusNoContent)
+}
+
+// Upload Create godoc
+// @Summary Start a resumable photo upload
+// @Description Start a resumable photo upload (tus creation), the photo is created when the upload is complete.
+// @Description Upload-Metadata holds the photo input as base64 encoded values: title, caption & keep_metadata.
+// @Tags uploads
+// @Param Tus-Resumable header string true "1.0.0"
+// @Param Upload-Length header int true "file size in bytes"
+// @Param Upload-Metadata header string true "e.g. title dGl0bGU=,caption Y2FwdGlvbg=="
+// @Param Authorization header string true "format: Bearer token-here"
+// @Success 201
+// @Failure 400 {object} models.ErrorResponse{}
+// @Failure 412 {object} models.ErrorResponse{}
+// @Failure 413 {object} models.ErrorResponse{}
+// @Router /api/v1/uploads [post]
+func (u *UploadHandler) Create(c *gin.Context) {
+	if !tusResumable(c) {
+		return
+	}
+
+	userData := c.MustGet("userData").(jwt.MapClaims)
+	userId := uint(userData["id"].(float64))
+	role, _ := userData["role"].(string)
+
+	length, err := strconv.ParseInt(c.GetHeader("Upload-Length"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "BAD REQUEST",
+			Message: "invalid Upload-Length header",
+		})
+		return
+	}
+
+	metadata, err := parseUploadMetadata(c.GetHeader("Upload-Metadata"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "BAD REQUEST",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	upload, err := u.uploadSvc.Create(userId, role, length, metadata)
+	if err != nil {
+		uploadErrorResponse(c, err)
+		return
+	}
+
+	c.Header("Location", fmt.Sprintf("%v/%v", strings.TrimSuffix(c.Request.URL.Path, "/"), upload.ID))
+	c.Header("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	c.Status(http.StatusCreated)
+}
+
+// Upload Head godoc
+// @Summary Get the offset of a resumable upload
+// @Description Get the offset of a resumable upload to resume it, Upload-Photo-Id is set once the photo is created
+// @Tags uploads
+// @Param uploadId path string true "upload id"
+// @Param Tus-Resumable header string true "1.0.0"
+// @Param Authorization header string true "format: Bearer token-here"
+// @Success 200
+// @Failure 404 {object} models.ErrorResponse{}
+// @Router /api/v1/uploads/{uploadId} [head]
+func (u *UploadHandler) Head(c *gin.Context) {
+	if !tusResumable(c) {
+		return
+	}
+
+	userData := c.MustGet("userData").(jwt.MapClaims)
+	userId := uint(userData["id"].(float64))
+
+	upload, err := u.uploadSvc.Get(userId, c.Param("uploadId"))
+	if err != nil {
+		uploadErrorResponse(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Upload-Length", strconv.FormatInt(upload.Length, 10))
+	setUploadHeaders(c, upload)
+	c.Status(http.StatusOK)
+}
+
+// Upload Patch godoc
+// @Summary Upload a chunk of a resumable upload
+// @Description Append a chunk at Upload-Offset, the photo is validated and created when the last chunk is received
+// @Tags uploads
+// @Accept application/offset+octet-stream
+// @Param uploadId path string true "upload id"
+// @Param Tus-Resumable header string true "1.0.0"
+// @Param Upload-Offset header int true "current offset"
+// @Param Authorization header string true "format: Bearer token-here"
+// @Success 204
+// @Failure 404 {object} models.ErrorResponse{}
+// @Failure 409 {object} models.ErrorResponse{}
+// @Failure 413 {object} models.ErrorResponse{}
+// @Failure 415 {object} models.ErrorResponse{}
+// @Failure 422 {object} models.ErrorResponse{}
+// @Failure 423 {object} models.ErrorResponse{}
+// @Failure 507 {object} models.ErrorResponse{}
+// @Router /api/v1/uploads/{uploadId} [patch]
+func (u *UploadHandler) Patch(c *gin.Context) {
+	if !tusResumable(c) {
+		return
+	}
+
+	userData := c.MustGet("userData").(jwt.MapClaims)
+	userId := uint(userData["id"].(float64))
+	role, _ := userData["role"].(string)
+
+	if c.ContentType() != tusContentType {
+		c.JSON(http.StatusUnsupportedMediaType, models.ErrorResponse{
+			Error:   "UNSUPPORTED MEDIA TYPE",
+			Message: "content type must be " + tusContentType,
+		})
+		return
+	}
+
+	offset, err := strconv.ParseInt(c.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "BAD REQUEST",
+			Message: "invalid Upload-Offset header",
+		})
+		return
+	}
+
+	upload, err := u.uploadSvc.Write(userId, role, c.Param("uploadId"), offset, c.Request.Body)
+	if err != nil {
+		uploadErrorResponse(c, err)
+		return
+	}
+
+	setUploadHeaders(c, upload)
+	c.Status(http.StatusNoContent)
+}
+
+// Upload Delete godoc
+// @Summary Cancel a resumable upload
+// @Description Cancel a resumable upload (tus termination)
+// @Tags uploads
+// @Param uploadId path string true "upload id"
+// @Param Tus-Resumable header string true "1.0.0"
+// @Param Authorization header string true "format: Bearer token-here"
+// @Success 204
+// @Failure 404 {object} models.ErrorResponse{}
+// @Router /api/v1/uploads/{uploadId} [delete]
+func (u *UploadHandler) Delete(c *gin.Context) {
+	if !tusResumable(c) {
+		return
+	}
+
+	userData := c.MustGet("userData").(jwt.MapClaims)
+	userId := uint(userData["id"].(float64))
+
+	if err := u.uploadSvc.Delete(userId, c.Param("uploadId")); err != nil {
+		uploadErrorResponse(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// tusResumable sets the protocol version header and rejects requests of other versions
+func tusResumable(c *gin.Context) bool {
+	c.Header("Tus-Resumable", tusVersion)
+
+	if c.GetHeader("Tus-Resumable") != tusVersion {
+		c.Header("Tus-Version", tusVersion)
+		c.JSON(http.StatusPreconditionFailed, models.ErrorResponse{
+			Error:   "PRECONDITION FAILED",
+			Message: "unsupported tus version, use " + tusVersion,
+		})
+		return false
+	}
+	return true
+}
+
+func setUploadHeaders(c *gin.Context, upload uploads.Upload) {
+	c.Header("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	c.Header("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	if upload.PhotoID != 0 {
+		c.Header("Upload-Photo-Id", strconv.FormatUint(uint64(upload.PhotoID), 10))
+	}
+}
+
+// parseUploadMetadata parses the Upload-Metadata header: comma separated "key base64-value" pairs
+func parseUploadMetadata(header string) (metadata map[string]string, err error) {
+	metadata = map[string]string{}
+	for _, pair := range strings.Split(header, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		key, encoded, _ := strings.Cut(pair, " ")
+		value, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Upload-Metadata value of '%v'", key)
+		}
+		metadata[key] = string(value)
+	}
+	return
+}
+
+func uploadErrorResponse(c *gin.Context, err error) {
+	if imageErrorResponse(c, err) {
+		return
+	}
+
+	switch {
+	case errors.Is(err, uploads.ErrNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "NOT FOUND",
+			Message: err.Error(),
+		})
+	case errors.Is(err, uploads.ErrOffsetMismatch):
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "CONFLICT",
+			Message: err.Error(),
+		})
+	case errors.Is(err, uploads.ErrLocked):
+		c.JSON(http.StatusLocked, models.ErrorResponse{
+			Error:   "LOCKED",
+			Message: err.Error(),
+		})
+	case errors.Is(err, uploads.ErrTooLarge), errors.Is(err, services.ErrUploadTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, models.ErrorResponse{
+			Error:   "REQUEST ENTITY TOO LARGE",
+			Message: err.Error(),
+		})
+	default:
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "BAD REQUEST",
+			Message: err.Error(),
+		})
+	}
+}
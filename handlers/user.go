@@ -1,17 +1,32 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/alvinmdj/mygram-api/helpers"
 	"github.com/alvinmdj/mygram-api/models"
 	"github.com/alvinmdj/mygram-api/services"
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 )
 
 type UserHdlInterface interface {
 	Register(c *gin.Context)
 	Login(c *gin.Context)
+	Refresh(c *gin.Context)
+	Logout(c *gin.Context)
+	LogoutAll(c *gin.Context)
+	GetMe(c *gin.Context)
+	GetMyUsage(c *gin.Context)
+	UpdateMe(c *gin.Context)
+	DeleteMe(c *gin.Context)
+	GetByUsername(c *gin.Context)
+	ChangePassword(c *gin.Context)
+	ForgotPassword(c *gin.Context)
+	ResetPassword(c *gin.Context)
+	VerifyEmail(c *gin.Context)
+	ResendVerification(c *gin.Context)
 }
 
 type UserHandler struct {
@@ -33,6 +48,7 @@ func NewUserHdl(userSvc services.UserSvcInterface) UserHdlInterface {
 // @Param models.UserRegisterInput body models.UserRegisterInput{} true "register user"
 // @Success 201 {object} models.UserRegisterOutput{}
 // @Failure 400 {object} models.ErrorResponse{}
+// @Failure 409 {object} models.ErrorResponse{}
 // @Router /api/v1/users/register [post]
 func (u *UserHandler) Register(c *gin.Context) {
 	contentType := helpers.GetContentType(c)
@@ -45,6 +61,13 @@ func (u *UserHandler) Register(c *gin.Context) {
 	}
 
 	user, err := u.userSvc.Register(userInput)
+	if errors.Is(err, services.ErrUsernameTaken) || errors.Is(err, services.ErrEmailTaken) {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "CONFLICT",
+			Message: err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
 			Error:   "BAD REQUEST",
@@ -71,6 +94,7 @@ func (u *UserHandler) Register(c *gin.Context) {
 // @Param models.UserLoginInput body models.UserLoginInput{} true "login user"
 // @Success 201 {object} models.UserLoginOutput{}
 // @Failure 401 {object} models.ErrorResponse{}
+// @Failure 403 {object} models.ErrorResponse{}
 // @Router /api/v1/users/login [post]
 func (u *UserHandler) Login(c *gin.Context) {
 	contentType := helpers.GetContentType(c)
@@ -82,7 +106,54 @@ func (u *UserHandler) Login(c *gin.Context) {
 		c.ShouldBind(&userInput)
 	}
 
-	token, err := u.userSvc.Login(userInput)
+	tokens, err := u.userSvc.Login(userInput)
+	if errors.Is(err, services.ErrEmailNotVerified) || errors.Is(err, services.ErrUserBlocked) {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "FORBIDDEN",
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "UNAUTHORIZED",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, tokens)
+}
+
+// User Refresh godoc
+// @Summary Refresh access token
+// @Description Exchange a refresh token for a new access token and a new (rotated) refresh token
+// @Tags users
+// @Accept json,mpfd
+// @Produce json
+// @Param models.UserRefreshInput body models.UserRefreshInput{} true "refresh token"
+// @Success 201 {object} models.UserLoginOutput{}
+// @Failure 401 {object} models.ErrorResponse{}
+// @Failure 403 {object} models.ErrorResponse{}
+// @Router /api/v1/users/refresh [post]
+func (u *UserHandler) Refresh(c *gin.Context) {
+	contentType := helpers.GetContentType(c)
+	refreshInput := models.UserRefreshInput{}
+
+	if contentType == helpers.AppJson {
+		c.ShouldBindJSON(&refreshInput)
+	} else {
+		c.ShouldBind(&refreshInput)
+	}
+
+	tokens, err := u.userSvc.Refresh(refreshInput)
+	if errors.Is(err, services.ErrUserBlocked) {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "FORBIDDEN",
+			Message: err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
 			Error:   "UNAUTHORIZED",
@@ -91,7 +162,412 @@ func (u *UserHandler) Login(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, models.UserLoginOutput{
-		Token: token,
+	c.JSON(http.StatusCreated, tokens)
+}
+
+// User Logout godoc
+// @Summary User logout
+// @Description Revoke the current access token and, if given, the session's refresh token
+// @Tags users
+// @Accept json,mpfd
+// @Produce json
+// @Param models.UserLogoutInput body models.UserLogoutInput{} false "refresh token of the session"
+// @Param Authorization header string true "format: Bearer token-here"
+// @Success 200 {object} models.MessageResponse{}
+// @Failure 401 {object} models.ErrorResponse{}
+// @Router /api/v1/users/logout [post]
+func (u *UserHandler) Logout(c *gin.Context) {
+	contentType := helpers.GetContentType(c)
+	logoutInput := models.UserLogoutInput{}
+
+	// get token claims in userData context from authentication middleware
+	// and cast the data type from any to jwt.MapClaims
+	userData := c.MustGet("userData").(jwt.MapClaims)
+	userId := uint(userData["id"].(float64))
+	jti := userData["jti"].(string)
+	expiresAt, _ := userData.GetExpirationTime()
+
+	if contentType == helpers.AppJson {
+		c.ShouldBindJSON(&logoutInput)
+	} else {
+		c.ShouldBind(&logoutInput)
+	}
+
+	if err := u.userSvc.Logout(userId, jti, expiresAt.Time, logoutInput); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "INTERNAL SERVER ERROR",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{
+		Message: "logged out successfully",
+	})
+}
+
+// User LogoutAll godoc
+// @Summary User logout everywhere
+// @Description Revoke every access token and refresh token of the current user
+// @Tags users
+// @Produce json
+// @Param Authorization header string true "format: Bearer token-here"
+// @Success 200 {object} models.MessageResponse{}
+// @Failure 401 {object} models.ErrorResponse{}
+// @Router /api/v1/users/logout-all [post]
+func (u *UserHandler) LogoutAll(c *gin.Context) {
+	// get token claims in userData context from authentication middleware
+	// and cast the data type from any to jwt.MapClaims
+	userData := c.MustGet("userData").(jwt.MapClaims)
+	userId := uint(userData["id"].(float64))
+
+	if err := u.userSvc.LogoutAll(userId); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "INTERNAL SERVER ERROR",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{
+		Message: "logged out from all devices successfully",
+	})
+}
+
+// User GetMe godoc
+// @Summary Get my profile
+// @Description Get the profile of the logged in user
+// @Tags users
+// @Produce json
+// @Param Authorization header string true "format: Bearer token-here"
+// @Success 200 {object} models.UserRegisterOutput{}
+// @Failure 404 {object} models.ErrorResponse{}
+// @Router /api/v1/users/me [get]
+func (u *UserHandler) GetMe(c *gin.Context) {
+	// get token claims in userData context from authentication middleware
+	// and cast the data type from any to jwt.MapClaims
+	userData := c.MustGet("userData").(jwt.MapClaims)
+	userId := uint(userData["id"].(float64))
+
+	user, err := u.userSvc.GetById(userId)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "NOT FOUND",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	userResponse := models.UserRegisterOutput{
+		Base:       user.Base,
+		Username:   user.Username,
+		Email:      user.Email,
+		Age:        user.Age,
+		VerifiedAt: user.VerifiedAt,
+	}
+	c.JSON(http.StatusOK, userResponse)
+}
+
+// User GetMyUsage godoc
+// @Summary Get my storage usage
+// @Description Get the storage used by the photos of the logged in user and their storage quota (bytes)
+// @Tags users
+// @Produce json
+// @Param Authorization header string true "format: Bearer token-here"
+// @Success 200 {object} models.UserUsageOutput{}
+// @Failure 404 {object} models.ErrorResponse{}
+// @Router /api/v1/users/me/usage [get]
+func (u *UserHandler) GetMyUsage(c *gin.Context) {
+	userData := c.MustGet("userData").(jwt.MapClaims)
+	userId := uint(userData["id"].(float64))
+
+	usage, err := u.userSvc.GetUsage(userId)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "NOT FOUND",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, usage)
+}
+
+// User UpdateMe godoc
+// @Summary Update my profile
+// @Description Update the profile of the logged in user
+// @Tags users
+// @Accept json,mpfd
+// @Produce json
+// @Param models.UserUpdateInput body models.UserUpdateInputSwagger{} true "update user"
+// @Param Authorization header string true "format: Bearer token-here"
+// @Success 200 {object} models.UserUpdateOutput{}
+// @Failure 400 {object} models.ErrorResponse{}
+// @Failure 409 {object} models.ErrorResponse{}
+// @Router /api/v1/users/me [put]
+func (u *UserHandler) UpdateMe(c *gin.Context) {
+	contentType := helpers.GetContentType(c)
+	userInput := models.UserUpdateInput{}
+
+	// get token claims in userData context from authentication middleware
+	// and cast the data type from any to jwt.MapClaims
+	userData := c.MustGet("userData").(jwt.MapClaims)
+	userId := uint(userData["id"].(float64))
+
+	if contentType == helpers.AppJson {
+		c.ShouldBindJSON(&userInput)
+	} else {
+		c.ShouldBind(&userInput)
+	}
+
+	// store id to input struct after binding so it can't be overwritten
+	userInput.ID = userId
+
+	user, err := u.userSvc.Update(userInput)
+	if errors.Is(err, services.ErrUsernameTaken) || errors.Is(err, services.ErrEmailTaken) {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "CONFLICT",
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "BAD REQUEST",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	userResponse := models.UserUpdateOutput{
+		Base:     user.Base,
+		Username: user.Username,
+		Email:    user.Email,
+		Age:      user.Age,
+	}
+	c.JSON(http.StatusOK, userResponse)
+}
+
+// User DeleteMe godoc
+// @Summary Delete my account
+// @Description Delete the account of the logged in user with its photos, comments & social medias and revoke all of its tokens
+// @Tags users
+// @Produce json
+// @Param Authorization header string true "format: Bearer token-here"
+// @Success 200 {object} models.DeleteResponse{}
+// @Failure 404 {object} models.ErrorResponse{}
+// @Router /api/v1/users/me [delete]
+func (u *UserHandler) DeleteMe(c *gin.Context) {
+	// get token claims in userData context from authentication middleware
+	// and cast the data type from any to jwt.MapClaims
+	userData := c.MustGet("userData").(jwt.MapClaims)
+	userId := uint(userData["id"].(float64))
+
+	if err := u.userSvc.Delete(userId); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "NOT FOUND",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.DeleteResponse{
+		Message: "your account has been deleted",
+	})
+}
+
+// User GetByUsername godoc
+// @Summary Get user profile by username
+// @Description Get the public profile of a user by username, with the follower & following counts
+// @Tags users
+// @Produce json
+// @Param username path string true "get user by username"
+// @Success 200 {object} models.UserProfileDetailOutput{}
+// @Failure 404 {object} models.ErrorResponse{}
+// @Router /api/v1/users/{username} [get]
+func (u *UserHandler) GetByUsername(c *gin.Context) {
+	user, err := u.userSvc.GetByUsername(c.Param("username"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "NOT FOUND",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, userProfileDetailOutput(user))
+}
+
+func userProfileDetailOutput(user models.User) models.UserProfileDetailOutput {
+	return models.UserProfileDetailOutput{
+		UserProfileOutput: models.UserProfileOutput{
+			Base:     user.Base,
+			Username: user.Username,
+			Age:      user.Age,
+		},
+		IsPrivate:      user.IsPrivate,
+		FollowerCount:  user.FollowerCount,
+		FollowingCount: user.FollowingCount,
+	}
+}
+
+// User ChangePassword godoc
+// @Summary Change my password
+// @Description Change the password of the logged in user, requires the current password
+// @Tags users
+// @Accept json,mpfd
+// @Produce json
+// @Param models.UserChangePasswordInput body models.UserChangePasswordInput{} true "change password"
+// @Param Authorization header string true "format: Bearer token-here"
+// @Success 200 {object} models.MessageResponse{}
+// @Failure 400 {object} models.ErrorResponse{}
+// @Router /api/v1/users/me/password [post]
+func (u *UserHandler) ChangePassword(c *gin.Context) {
+	contentType := helpers.GetContentType(c)
+	passwordInput := models.UserChangePasswordInput{}
+
+	// get token claims in userData context from authentication middleware
+	// and cast the data type from any to jwt.MapClaims
+	userData := c.MustGet("userData").(jwt.MapClaims)
+	userId := uint(userData["id"].(float64))
+
+	if contentType == helpers.AppJson {
+		c.ShouldBindJSON(&passwordInput)
+	} else {
+		c.ShouldBind(&passwordInput)
+	}
+
+	if err := u.userSvc.ChangePassword(userId, passwordInput); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "BAD REQUEST",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{
+		Message: "password has been changed",
+	})
+}
+
+// User ForgotPassword godoc
+// @Summary Forgot password
+// @Description Send a password reset link to the email, if it is registered
+// @Tags users
+// @Accept json,mpfd
+// @Produce json
+// @Param models.UserForgotPasswordInput body models.UserForgotPasswordInput{} true "forgot password"
+// @Success 200 {object} models.MessageResponse{}
+// @Failure 400 {object} models.ErrorResponse{}
+// @Router /api/v1/users/password/forgot [post]
+func (u *UserHandler) ForgotPassword(c *gin.Context) {
+	contentType := helpers.GetContentType(c)
+	forgotInput := models.UserForgotPasswordInput{}
+
+	if contentType == helpers.AppJson {
+		c.ShouldBindJSON(&forgotInput)
+	} else {
+		c.ShouldBind(&forgotInput)
+	}
+
+	if err := u.userSvc.ForgotPassword(forgotInput); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "BAD REQUEST",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{
+		Message: "if the email is registered, a password reset link has been sent",
+	})
+}
+
+// User ResetPassword godoc
+// @Summary Reset password
+// @Description Set a new password using the token from the password reset email
+// @Tags users
+// @Accept json,mpfd
+// @Produce json
+// @Param models.UserResetPasswordInput body models.UserResetPasswordInput{} true "reset password"
+// @Success 200 {object} models.MessageResponse{}
+// @Failure 400 {object} models.ErrorResponse{}
+// @Router /api/v1/users/password/reset [post]
+func (u *UserHandler) ResetPassword(c *gin.Context) {
+	contentType := helpers.GetContentType(c)
+	resetInput := models.UserResetPasswordInput{}
+
+	if contentType == helpers.AppJson {
+		c.ShouldBindJSON(&resetInput)
+	} else {
+		c.ShouldBind(&resetInput)
+	}
+
+	if err := u.userSvc.ResetPassword(resetInput); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "BAD REQUEST",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{
+		Message: "password has been reset, sign in with your new password",
+	})
+}
+
+// User VerifyEmail godoc
+// @Summary Verify email
+// @Description Verify the email of a user using the token from the verification email
+// @Tags users
+// @Produce json
+// @Param token query string true "verification token"
+// @Success 200 {object} models.MessageResponse{}
+// @Failure 400 {object} models.ErrorResponse{}
+// @Router /api/v1/users/verify [get]
+func (u *UserHandler) VerifyEmail(c *gin.Context) {
+	if err := u.userSvc.VerifyEmail(c.Query("token")); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "BAD REQUEST",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{
+		Message: "email has been verified",
+	})
+}
+
+// User ResendVerification godoc
+// @Summary Resend verification email
+// @Description Send a new verification link to the email, if it is registered and not verified yet
+// @Tags users
+// @Accept json,mpfd
+// @Produce json
+// @Param models.UserResendVerificationInput body models.UserResendVerificationInput{} true "resend verification email"
+// @Success 200 {object} models.MessageResponse{}
+// @Failure 400 {object} models.ErrorResponse{}
+// @Router /api/v1/users/verify/resend [post]
+func (u *UserHandler) ResendVerification(c *gin.Context) {
+	contentType := helpers.GetContentType(c)
+	resendInput := models.UserResendVerificationInput{}
+
+	if contentType == helpers.AppJson {
+		c.ShouldBindJSON(&resendInput)
+	} else {
+		c.ShouldBind(&resendInput)
+	}
+
+	if err := u.userSvc.ResendVerification(resendInput); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "BAD REQUEST",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{
+		Message: "if the email is registered and not verified yet, a verification link has been sent",
 	})
 }
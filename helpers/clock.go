@@ -0,0 +1,22 @@
+package helpers
+
+import "time"
+
+// Clock tells the current time, jobs which depend on the time take a Clock so they can run at a fixed time
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the wall clock
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// ClockFunc adapts a function to a Clock, e.g. ClockFunc(func() time.Time { return fixed })
+type ClockFunc func() time.Time
+
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
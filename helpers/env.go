@@ -0,0 +1,62 @@
+package helpers
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GetEnvDuration reads a duration (e.g. "15m", "720h") from the environment,
+// falling back to def when the variable is empty or invalid.
+func GetEnvDuration(key string, def time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return def
+	}
+	return value
+}
+
+// GetEnvInt reads an integer from the environment,
+// falling back to def when the variable is empty or invalid.
+func GetEnvInt(key string, def int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return def
+	}
+	return value
+}
+
+// GetEnvBytes reads a size in bytes (e.g. "1048576", "512KiB", "20MiB", "1GiB") from the environment,
+// falling back to def when the variable is empty.
+func GetEnvBytes(key string, def int64) (int64, error) {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return def, nil
+	}
+
+	unit := int64(1)
+	for suffix, size := range map[string]int64{"KiB": 1 << 10, "MiB": 1 << 20, "GiB": 1 << 30} {
+		if number, ok := strings.CutSuffix(value, suffix); ok {
+			value, unit = strings.TrimSpace(number), size
+			break
+		}
+	}
+
+	bytes, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || bytes <= 0 {
+		return def, fmt.Errorf("%v: invalid size %q", key, os.Getenv(key))
+	}
+	return bytes * unit, nil
+}
+
+// GetEnvBool reads a boolean (e.g. "true", "0") from the environment,
+// falling back to def when the variable is empty or invalid.
+func GetEnvBool(key string, def bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return value
+}
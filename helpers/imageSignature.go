@@ -0,0 +1,31 @@
+package helpers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+)
+
+// SignImageParams returns the HMAC signature of the transformation parameters of a photo,
+// the "sig" parameter itself is never signed
+func SignImageParams(photoId uint, params url.Values) string {
+	unsigned := url.Values{}
+	for key, values := range params {
+		if key != "sig" {
+			unsigned[key] = values
+		}
+	}
+
+	// Encode sorts the parameters by key
+	mac := hmac.New(sha256.New, SigningKey("IMAGE_SIGNING_KEY", "image signature"))
+	mac.Write([]byte(fmt.Sprintf("%d?%s", photoId, unsigned.Encode())))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyImageParams checks the "sig" parameter of the transformation parameters of a photo
+func VerifyImageParams(photoId uint, params url.Values) bool {
+	expected := SignImageParams(photoId, params)
+	return hmac.Equal([]byte(params.Get("sig")), []byte(expected))
+}
@@ -4,26 +4,48 @@ import (
 	"errors"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
-var secretKey = os.Getenv("JWT_SECRET")
+// AccessTokenTTL returns how long an access token is valid (default 15 minutes)
+func AccessTokenTTL() time.Duration {
+	return GetEnvDuration("ACCESS_TOKEN_TTL", 15*time.Minute)
+}
+
+// RefreshTokenTTL returns how long a refresh token is valid (default 30 days)
+func RefreshTokenTTL() time.Duration {
+	return GetEnvDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour)
+}
+
+// read the secret on every call, the .env file is loaded after package initialization
+func secretKey() []byte {
+	return []byte(os.Getenv("JWT_SECRET"))
+}
+
+func GenerateToken(id uint, email string, role string) (string, time.Time) {
+	now := time.Now()
+	expiresAt := now.Add(AccessTokenTTL())
 
-func GenerateToken(id uint, email string) string {
 	claims := jwt.MapClaims{
 		"id":    id,
 		"email": email,
+		"role":  role,
+		"jti":   uuid.New().String(), // unique token id, used to revoke this token on logout
+		"iat":   now.Unix(),
+		"exp":   expiresAt.Unix(),
 	}
 
 	// creates a new token with the specified signing method and claims.
 	parseToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
 	// creates and returns a complete, signed JWT.
-	signedToken, _ := parseToken.SignedString([]byte(secretKey))
+	signedToken, _ := parseToken.SignedString(secretKey())
 
-	return signedToken
+	return signedToken, expiresAt
 }
 
 func VerifyToken(c *gin.Context) (interface{}, error) {
@@ -32,7 +54,7 @@ func VerifyToken(c *gin.Context) (interface{}, error) {
 	// get Authorization header value
 	headerToken := c.Request.Header.Get("Authorization")
 	// check if Authorization header contains Bearer as a suffix
-	if bearer := strings.HasPrefix(headerToken, "Bearer"); !bearer {
+	if bearer := strings.HasPrefix(headerToken, "Bearer "); !bearer {
 		return nil, errResponse
 	}
 
@@ -40,20 +62,39 @@ func VerifyToken(c *gin.Context) (interface{}, error) {
 	// get the <token-here> value after splitting inside index 1
 	stringToken := strings.Split(headerToken, " ")[1]
 
-	// parse token into a pointer of struct jwt.Token
-	token, _ := jwt.Parse(stringToken, func(t *jwt.Token) (interface{}, error) {
+	// parse token into a pointer of struct jwt.Token,
+	// expired tokens (exp claim) are rejected by the parser
+	token, err := jwt.Parse(stringToken, func(t *jwt.Token) (interface{}, error) {
 		// check if signing method is HS256 by casting the method into pointer of struct jwt.SigningMethodHMAC
 		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, errResponse
 		}
-		return []byte(secretKey), nil
+		return secretKey(), nil
 	})
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		return nil, errors.New("token has expired, refresh your token or sign in again")
+	}
+	if err != nil || !token.Valid {
+		return nil, errResponse
+	}
 
 	// check if token still valid after casting into type of jwt.MapClaims
-	if _, ok := token.Claims.(jwt.MapClaims); !ok || !token.Valid {
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errResponse
+	}
+
+	// tokens without exp, iat or jti claims can't be expired or revoked and are never accepted
+	if exp, err := claims.GetExpirationTime(); err != nil || exp == nil {
+		return nil, errResponse
+	}
+	if iat, err := claims.GetIssuedAt(); err != nil || iat == nil {
+		return nil, errResponse
+	}
+	if jti, ok := claims["jti"].(string); !ok || jti == "" {
 		return nil, errResponse
 	}
 
 	// return claims (contains id & email of the successfully logged in user),
-	return token.Claims.(jwt.MapClaims), nil
+	return claims, nil
 }
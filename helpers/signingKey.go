@@ -0,0 +1,19 @@
+package helpers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"os"
+)
+
+// SigningKey reads the key of the env variable lazily, without one a key is derived from JWT_SECRET
+// for the purpose, so a signature of one kind (e.g. an image url) is never valid as another (e.g. a token)
+func SigningKey(env string, purpose string) []byte {
+	if key := os.Getenv(env); key != "" {
+		return []byte(key)
+	}
+
+	mac := hmac.New(sha256.New, secretKey())
+	mac.Write([]byte("mygram " + purpose))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,158 @@
+package images
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"time"
+)
+
+// Metadata holds the EXIF fields users may choose to keep, everything else (GPS, serial numbers, ...) is dropped
+type Metadata struct {
+	TakenAt     *time.Time
+	Orientation int
+	CameraModel string
+}
+
+// exif tags
+const (
+	tagModel              = 0x0110
+	tagOrientation        = 0x0112
+	tagDateTime           = 0x0132
+	tagExifIFD            = 0x8769
+	tagDateTimeOriginal   = 0x9003
+	tagOffsetTimeOriginal = 0x9011
+)
+
+// exif field types
+const (
+	typeASCII = 2
+	typeShort = 3
+	typeLong  = 4
+)
+
+var exifHeader = []byte("Exif\x00\x00")
+
+type exifEntry struct {
+	typ   uint16
+	count uint32
+	value []byte // the 4 byte value/offset field
+}
+
+type exifReader struct {
+	tiff  []byte
+	order binary.ByteOrder
+}
+
+// parseExif reads the kept fields from an EXIF (TIFF) block, malformed fields are ignored
+func parseExif(tiff []byte) (metadata Metadata) {
+	tiff = bytes.TrimPrefix(tiff, exifHeader)
+	if len(tiff) < 8 {
+		return
+	}
+
+	r := exifReader{tiff: tiff}
+	switch string(tiff[0:4]) {
+	case "II*\x00":
+		r.order = binary.LittleEndian
+	case "MM\x00*":
+		r.order = binary.BigEndian
+	default:
+		return
+	}
+
+	ifd0 := r.readIFD(r.order.Uint32(tiff[4:]))
+	if entry, ok := ifd0[tagOrientation]; ok {
+		if orientation, ok := r.short(entry); ok && orientation >= 1 && orientation <= 8 {
+			metadata.Orientation = orientation
+		}
+	}
+	if entry, ok := ifd0[tagModel]; ok {
+		metadata.CameraModel = r.ascii(entry)
+	}
+
+	// the capture time lives in the exif sub IFD, fall back to the modification time
+	takenAt, offset := r.ascii(ifd0[tagDateTime]), ""
+	if entry, ok := ifd0[tagExifIFD]; ok {
+		if exifOffset, ok := r.long(entry); ok {
+			exifIFD := r.readIFD(exifOffset)
+			if dateTime := r.ascii(exifIFD[tagDateTimeOriginal]); dateTime != "" {
+				takenAt = dateTime
+			}
+			offset = r.ascii(exifIFD[tagOffsetTimeOriginal])
+		}
+	}
+	metadata.TakenAt = parseExifTime(takenAt, offset)
+	return
+}
+
+func (r exifReader) readIFD(offset uint32) map[uint16]exifEntry {
+	entries := map[uint16]exifEntry{}
+	if offset < 8 || int64(offset)+2 > int64(len(r.tiff)) {
+		return entries
+	}
+
+	count := int(r.order.Uint16(r.tiff[offset:]))
+	pos := int(offset) + 2
+	for i := 0; i < count && pos+12 <= len(r.tiff); i++ {
+		entries[r.order.Uint16(r.tiff[pos:])] = exifEntry{
+			typ:   r.order.Uint16(r.tiff[pos+2:]),
+			count: r.order.Uint32(r.tiff[pos+4:]),
+			value: r.tiff[pos+8 : pos+12],
+		}
+		pos += 12
+	}
+	return entries
+}
+
+func (r exifReader) short(entry exifEntry) (int, bool) {
+	if entry.typ != typeShort || entry.count < 1 {
+		return 0, false
+	}
+	return int(r.order.Uint16(entry.value)), true
+}
+
+func (r exifReader) long(entry exifEntry) (uint32, bool) {
+	if entry.typ != typeLong || entry.count < 1 {
+		return 0, false
+	}
+	return r.order.Uint32(entry.value), true
+}
+
+func (r exifReader) ascii(entry exifEntry) string {
+	if entry.typ != typeASCII || entry.count == 0 || entry.count > 256 {
+		return ""
+	}
+
+	// values up to 4 bytes are stored inline, longer ones at an offset
+	value := entry.value
+	if entry.count > 4 {
+		offset := r.order.Uint32(entry.value)
+		if int64(offset)+int64(entry.count) > int64(len(r.tiff)) {
+			return ""
+		}
+		value = r.tiff[offset : offset+entry.count]
+	} else {
+		value = value[:entry.count]
+	}
+	return strings.TrimSpace(strings.TrimRight(string(value), "\x00"))
+}
+
+// parseExifTime parses an exif date ("2006:01:02 15:04:05") with an optional offset ("+07:00"),
+// times without an offset are stored as UTC
+func parseExifTime(dateTime string, offset string) *time.Time {
+	if dateTime == "" {
+		return nil
+	}
+
+	location := time.UTC
+	if zone, err := time.Parse("-07:00", offset); err == nil {
+		location = zone.Location()
+	}
+
+	takenAt, err := time.ParseInLocation("2006:01:02 15:04:05", dateTime, location)
+	if err != nil {
+		return nil
+	}
+	return &takenAt
+}
@@ -0,0 +1,236 @@
+package images
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/jpeg"
+	"image/png"
+)
+
+const jpegQuality = 90
+
+// Process removes the metadata of a validated image and returns the cleaned image with the kept metadata fields.
+// Images with an exif orientation are rotated into place (jpeg & png are re-encoded), other images are stripped
+// without re-encoding. Webp can't be decoded with the stdlib, so webp images which would have to be rotated are refused.
+func Process(data []byte, info Info) (out []byte, outInfo Info, metadata Metadata, err error) {
+	outInfo = info
+
+	switch info.Format {
+	case FormatJPEG:
+		metadata = parseExif(jpegExif(data))
+	case FormatPNG:
+		metadata = parseExif(pngChunk(data, "eXIf"))
+	case FormatWebP:
+		metadata = parseExif(webpChunk(data, "EXIF"))
+		if metadata.Orientation > 1 {
+			return nil, info, metadata, newError(CodeUnsupportedType, "rotated webp photos aren't supported, save the photo upright or as jpeg")
+		}
+		out = stripWebp(data)
+		return
+	}
+
+	if metadata.Orientation > 1 {
+		out, outInfo, err = reorient(data, info, metadata.Orientation)
+		return
+	}
+
+	if info.Format == FormatJPEG {
+		out = stripJpeg(data)
+	} else {
+		out = stripPng(data)
+	}
+	return
+}
+
+// reorient decodes the image, applies the exif orientation and encodes it again without metadata
+func reorient(data []byte, info Info, orientation int) (out []byte, outInfo Info, err error) {
+	img, err := decode(info.Format, data)
+	if err != nil {
+		return nil, info, newError(CodeCorrupt, "invalid image: %v", err)
+	}
+
+	img = applyOrientation(img, orientation)
+	outInfo = info
+	outInfo.Width, outInfo.Height = img.Bounds().Dx(), img.Bounds().Dy()
+
+	var buf bytes.Buffer
+	if info.Format == FormatJPEG {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	} else {
+		err = png.Encode(&buf, img)
+	}
+	return buf.Bytes(), outInfo, err
+}
+
+// applyOrientation transforms an image as described by the exif orientation tag (1-8)
+func applyOrientation(img image.Image, orientation int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	// orientations 5-8 swap width & height
+	dstWidth, dstHeight := width, height
+	if orientation >= 5 {
+		dstWidth, dstHeight = height, width
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // mirrored horizontally
+				dx, dy = width-1-x, y
+			case 3: // rotated 180
+				dx, dy = width-1-x, height-1-y
+			case 4: // mirrored vertically
+				dx, dy = x, height-1-y
+			case 5: // transposed
+				dx, dy = y, x
+			case 6: // rotated 90 clockwise
+				dx, dy = height-1-y, x
+			case 7: // transversed
+				dx, dy = height-1-y, width-1-x
+			case 8: // rotated 90 counter clockwise
+				dx, dy = y, width-1-x
+			default:
+				dx, dy = x, y
+			}
+			dst.Set(dx, dy, img.At(bounds.Min.X+x, bounds.Min.Y+y))
+		}
+	}
+	return dst
+}
+
+func jpegExif(data []byte) (exif []byte) {
+	walkJpeg(data, func(marker byte, segment []byte) {
+		if marker == 0xe1 && exif == nil && bytes.HasPrefix(segment[4:], exifHeader) {
+			exif = segment[4:]
+		}
+	})
+	return
+}
+
+// stripJpeg removes the exif, xmp, iptc & comment segments,
+// JFIF, the ICC color profile and the Adobe color transform are kept
+func stripJpeg(data []byte) []byte {
+	out := []byte{0xff, 0xd8}
+	walkJpeg(data, func(marker byte, segment []byte) {
+		keep := true
+		switch {
+		case marker == 0xfe: // comment
+			keep = false
+		case marker == 0xe2:
+			keep = bytes.HasPrefix(segment[4:], []byte("ICC_PROFILE\x00"))
+		case marker == 0xee:
+			keep = bytes.HasPrefix(segment[4:], []byte("Adobe"))
+		case marker > 0xe0 && marker <= 0xef:
+			keep = false
+		}
+		if keep {
+			out = append(out, segment...)
+		}
+	})
+	return out
+}
+
+// pngChunks calls fn for every chunk (type, whole chunk including length & crc)
+func pngChunks(data []byte, fn func(chunkType string, chunk []byte)) {
+	pos := 8
+	for pos+12 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		if length < 0 || pos+12+length > len(data) {
+			return
+		}
+		chunkType := string(data[pos+4 : pos+8])
+		fn(chunkType, data[pos:pos+12+length])
+		pos += 12 + length
+		if chunkType == "IEND" {
+			return
+		}
+	}
+}
+
+func pngChunk(data []byte, wantedType string) (chunkData []byte) {
+	pngChunks(data, func(chunkType string, chunk []byte) {
+		if chunkType == wantedType && chunkData == nil {
+			chunkData = chunk[8 : len(chunk)-4]
+		}
+	})
+	return
+}
+
+// stripPng removes the text, exif & time chunks
+func stripPng(data []byte) []byte {
+	out := append([]byte{}, data[:8]...)
+	pngChunks(data, func(chunkType string, chunk []byte) {
+		switch chunkType {
+		case "tEXt", "zTXt", "iTXt", "eXIf", "tIME":
+			return
+		}
+		out = append(out, chunk...)
+	})
+	return out
+}
+
+// webpChunks calls fn for every chunk in the RIFF container (fourCC, chunk data)
+func webpChunks(data []byte, fn func(fourCC string, chunkData []byte)) {
+	end := 8 + int(binary.LittleEndian.Uint32(data[4:]))
+	if end > len(data) {
+		end = len(data)
+	}
+
+	pos := 12
+	for pos+8 <= end {
+		size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		if size < 0 || pos+8+size > end {
+			return
+		}
+		fn(string(data[pos:pos+4]), data[pos+8:pos+8+size])
+		pos += 8 + size + size%2
+	}
+}
+
+func webpChunk(data []byte, wantedFourCC string) (chunkData []byte) {
+	webpChunks(data, func(fourCC string, chunk []byte) {
+		if fourCC == wantedFourCC && chunkData == nil {
+			chunkData = chunk
+		}
+	})
+	return
+}
+
+// VP8X flags
+const (
+	webpFlagExif = 0x08
+	webpFlagXmp  = 0x04
+)
+
+// stripWebp removes the EXIF & XMP chunks
+func stripWebp(data []byte) []byte {
+	body := []byte("WEBP")
+	webpChunks(data, func(fourCC string, chunk []byte) {
+		switch fourCC {
+		case "EXIF", "XMP ":
+			return
+		case "VP8X":
+			chunk = append([]byte{}, chunk...)
+			chunk[0] &^= webpFlagExif | webpFlagXmp
+		}
+		body = appendRiffChunk(body, fourCC, chunk)
+	})
+
+	out := []byte("RIFF")
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(body)))
+	return append(out, body...)
+}
+
+func appendRiffChunk(body []byte, fourCC string, chunk []byte) []byte {
+	body = append(body, fourCC...)
+	body = binary.LittleEndian.AppendUint32(body, uint32(len(chunk)))
+	body = append(body, chunk...)
+	if len(chunk)%2 == 1 {
+		body = append(body, 0)
+	}
+	return body
+}
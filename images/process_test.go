@@ -0,0 +1,109 @@
+package images
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+)
+
+// orientationExif builds an exif block which only contains the orientation
+func orientationExif(orientation int) []byte {
+	tiff := append([]byte{}, exifHeader...)
+	tiff = append(tiff, "MM\x00*\x00\x00\x00\x08"...)
+	tiff = binary.BigEndian.AppendUint16(tiff, 1) // entry count
+	tiff = binary.BigEndian.AppendUint16(tiff, tagOrientation)
+	tiff = binary.BigEndian.AppendUint16(tiff, typeShort)
+	tiff = binary.BigEndian.AppendUint32(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, uint16(orientation))
+	tiff = binary.BigEndian.AppendUint16(tiff, 0)
+	tiff = binary.BigEndian.AppendUint32(tiff, 0) // no next IFD
+	return tiff
+}
+
+// rotatedJpeg returns a 4x2 jpeg with an APP1 exif segment right after SOI
+func rotatedJpeg(t *testing.T, orientation int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for x := 0; x < 4; x++ {
+		for y := 0; y < 2; y++ {
+			img.Set(x, y, color.White)
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	exif := orientationExif(orientation)
+	segment := []byte{0xff, 0xe1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(exif)+2))
+	segment = append(segment, exif...)
+
+	out := append([]byte{}, data[:2]...)
+	out = append(out, segment...)
+	return append(out, data[2:]...)
+}
+
+// rotatedWebp returns an extended webp container with an exif chunk, the image data itself is never decoded
+func rotatedWebp(orientation int) []byte {
+	vp8x := make([]byte, 10)
+	vp8x[0] = webpFlagExif
+	body := []byte("WEBP")
+	body = appendRiffChunk(body, "VP8X", vp8x)
+	body = appendRiffChunk(body, "VP8L", []byte{0x2f, 0, 0, 0, 0})
+	body = appendRiffChunk(body, "EXIF", orientationExif(orientation))
+
+	out := []byte("RIFF")
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(body)))
+	return append(out, body...)
+}
+
+func TestProcessAppliesJpegOrientation(t *testing.T) {
+	data := rotatedJpeg(t, 6)
+	info := Info{Format: FormatJPEG, ContentType: "image/jpeg", Ext: ".jpg", Width: 4, Height: 2}
+
+	out, outInfo, metadata, err := Process(data, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.Orientation != 6 {
+		t.Errorf("orientation = %v, want 6", metadata.Orientation)
+	}
+	if outInfo.Width != 2 || outInfo.Height != 4 {
+		t.Errorf("size = %vx%v, want 2x4", outInfo.Width, outInfo.Height)
+	}
+	if jpegExif(out) != nil {
+		t.Error("exif is kept")
+	}
+	config, err := jpeg.DecodeConfig(bytes.NewReader(out))
+	if err != nil || config.Width != 2 || config.Height != 4 {
+		t.Errorf("decoded size = %vx%v (%v), want 2x4", config.Width, config.Height, err)
+	}
+}
+
+func TestProcessWebp(t *testing.T) {
+	info := Info{Format: FormatWebP, ContentType: "image/webp", Ext: ".webp"}
+
+	// nothing to apply, the exif is stripped
+	out, _, _, err := Process(rotatedWebp(1), info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if webpChunk(out, "EXIF") != nil {
+		t.Error("exif chunk is kept")
+	}
+	if vp8x := webpChunk(out, "VP8X"); vp8x == nil || vp8x[0]&webpFlagExif != 0 {
+		t.Errorf("VP8X flags = %v, exif flag must be cleared", vp8x)
+	}
+
+	// the orientation can't be applied without decoding
+	_, _, _, err = Process(rotatedWebp(6), info)
+	var imageErr *Error
+	if !errors.As(err, &imageErr) || imageErr.Code != CodeUnsupportedType {
+		t.Errorf("error = %v, want %v", err, CodeUnsupportedType)
+	}
+}
@@ -0,0 +1,126 @@
+package images
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+)
+
+// VariantSizes are the sizes (longest side in px) of the generated variants
+var VariantSizes = []int{150, 640, 1080}
+
+const variantJpegQuality = 85
+
+// Variant is a resized copy of an image
+type Variant struct {
+	Size        int
+	Width       int
+	Height      int
+	ContentType string
+	Ext         string
+	Data        []byte
+}
+
+// Variants resizes an image to fit each size, images smaller than a size aren't upscaled.
+// Webp can't be decoded with the stdlib, so webp isn't an upload type & is refused.
+func Variants(data []byte, info Info, sizes []int) (variants []Variant, err error) {
+	if info.Format == FormatWebP {
+		return nil, newError(CodeUnsupportedType, "webp photos can't be resized")
+	}
+
+	img, err := decode(info.Format, data)
+	if err != nil {
+		return nil, newError(CodeCorrupt, "invalid image: %v", err)
+	}
+
+	for _, size := range sizes {
+		resized := Resize(img, size)
+
+		// png keeps its transparency, everything else becomes jpeg
+		var buf bytes.Buffer
+		variant := Variant{
+			Size:   size,
+			Width:  resized.Bounds().Dx(),
+			Height: resized.Bounds().Dy(),
+		}
+		if info.Format == FormatPNG {
+			variant.ContentType, variant.Ext = "image/png", ".png"
+			err = png.Encode(&buf, resized)
+		} else {
+			variant.ContentType, variant.Ext = "image/jpeg", ".jpg"
+			err = jpeg.Encode(&buf, resized, &jpeg.Options{Quality: variantJpegQuality})
+		}
+		if err != nil {
+			return nil, err
+		}
+		variant.Data = buf.Bytes()
+
+		variants = append(variants, variant)
+	}
+	return
+}
+
+// Resize scales an image down so its longest side fits size
+func Resize(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	srcWidth, srcHeight := bounds.Dx(), bounds.Dy()
+
+	dstWidth, dstHeight := srcWidth, srcHeight
+	if srcWidth >= srcHeight && srcWidth > size {
+		dstWidth, dstHeight = size, atLeast(srcHeight*size/srcWidth, 1)
+	} else if srcHeight > srcWidth && srcHeight > size {
+		dstWidth, dstHeight = atLeast(srcWidth*size/srcHeight, 1), size
+	}
+	return resizeTo(img, dstWidth, dstHeight)
+}
+
+// resizeTo scales an image down to exactly width x height, each destination pixel is
+// the average of the source pixels it covers (box filter)
+func resizeTo(img image.Image, dstWidth int, dstHeight int) *image.RGBA {
+	bounds := img.Bounds()
+	srcWidth, srcHeight := bounds.Dx(), bounds.Dy()
+
+	// work on premultiplied RGBA so transparent pixels don't bleed their color
+	src := image.NewRGBA(image.Rect(0, 0, srcWidth, srcHeight))
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+	if dstWidth == srcWidth && dstHeight == srcHeight {
+		return src
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := 0; y < dstHeight; y++ {
+		y0, y1 := y*srcHeight/dstHeight, atLeast((y+1)*srcHeight/dstHeight, y*srcHeight/dstHeight+1)
+		for x := 0; x < dstWidth; x++ {
+			x0, x1 := x*srcWidth/dstWidth, atLeast((x+1)*srcWidth/dstWidth, x*srcWidth/dstWidth+1)
+
+			var r, g, b, a, count int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					pixel := row[sx*4 : sx*4+4]
+					r += int(pixel[0])
+					g += int(pixel[1])
+					b += int(pixel[2])
+					a += int(pixel[3])
+					count++
+				}
+			}
+
+			pixel := dst.Pix[y*dst.Stride+x*4:]
+			pixel[0] = uint8(r / count)
+			pixel[1] = uint8(g / count)
+			pixel[2] = uint8(b / count)
+			pixel[3] = uint8(a / count)
+		}
+	}
+	return dst
+}
+
+func atLeast(value int, minimum int) int {
+	if value < minimum {
+		return minimum
+	}
+	return value
+}
@@ -0,0 +1,173 @@
+package images
+
+import "encoding/binary"
+
+// jpegEnd walks the jpeg segments and returns the offset right after the EOI marker
+func jpegEnd(data []byte) (end int, err error) {
+	return walkJpeg(data, nil)
+}
+
+// walkJpeg calls fn (when not nil) for every segment after SOI up to and including EOI,
+// a start of scan segment includes the entropy coded data following it
+func walkJpeg(data []byte, fn func(marker byte, segment []byte)) (end int, err error) {
+	pos := 2 // after SOI
+	for {
+		// markers may be preceded by 0xff fill bytes
+		if pos >= len(data) {
+			return 0, newError(CodeCorrupt, "invalid jpeg: truncated file")
+		}
+		if data[pos] != 0xff {
+			return 0, newError(CodeCorrupt, "invalid jpeg: marker expected at offset %d", pos)
+		}
+		for pos < len(data) && data[pos] == 0xff {
+			pos++
+		}
+		if pos >= len(data) {
+			return 0, newError(CodeCorrupt, "invalid jpeg: truncated file")
+		}
+		marker := data[pos]
+		pos++
+		segmentStart := pos - 2 // fill bytes are dropped, segments start with a single 0xff
+
+		switch {
+		case marker == 0xd9: // EOI
+			if fn != nil {
+				fn(marker, data[segmentStart:pos])
+			}
+			return pos, nil
+		case marker == 0x01 || (marker >= 0xd0 && marker <= 0xd7): // markers without a length
+			if fn != nil {
+				fn(marker, data[segmentStart:pos])
+			}
+			continue
+		}
+
+		if pos+2 > len(data) {
+			return 0, newError(CodeCorrupt, "invalid jpeg: truncated file")
+		}
+		length := int(binary.BigEndian.Uint16(data[pos:]))
+		if length < 2 || pos+length > len(data) {
+			return 0, newError(CodeCorrupt, "invalid jpeg: bad segment length at offset %d", pos)
+		}
+		pos += length
+
+		// entropy coded data follows the start of scan header, it runs until the next marker
+		if marker == 0xda {
+			for pos < len(data) {
+				if data[pos] != 0xff || pos+1 >= len(data) {
+					pos++
+					continue
+				}
+				next := data[pos+1]
+				if next == 0x00 || (next >= 0xd0 && next <= 0xd7) {
+					// stuffed byte or restart marker
+					pos += 2
+					continue
+				}
+				if next == 0xff {
+					pos++
+					continue
+				}
+				break
+			}
+		}
+
+		if fn != nil {
+			fn(marker, data[segmentStart:pos])
+		}
+	}
+}
+
+// pngEnd walks the png chunks and returns the offset right after the IEND chunk
+func pngEnd(data []byte) (end int, err error) {
+	pos := 8 // after the signature
+	for {
+		// length (4) + type (4) + data + crc (4)
+		if pos+12 > len(data) {
+			return 0, newError(CodeCorrupt, "invalid png: truncated file")
+		}
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		if length < 0 || length > len(data)-pos-12 {
+			return 0, newError(CodeCorrupt, "invalid png: bad chunk length at offset %d", pos)
+		}
+		chunkType := string(data[pos+4 : pos+8])
+		pos += 12 + length
+
+		if chunkType == "IEND" {
+			return pos, nil
+		}
+	}
+}
+
+// webpEnd walks the webp (RIFF) chunks, returns the offset right after the RIFF container
+// and the dimensions of the image
+func webpEnd(data []byte) (end int, width int, height int, err error) {
+	end = 8 + int(binary.LittleEndian.Uint32(data[4:]))
+	if end > len(data) || end < 12 {
+		return 0, 0, 0, newError(CodeCorrupt, "invalid webp: bad RIFF size")
+	}
+
+	hasBitstream := false
+	pos := 12 // after "RIFF" size "WEBP"
+	for pos < end {
+		if pos+8 > end {
+			return 0, 0, 0, newError(CodeCorrupt, "invalid webp: truncated chunk at offset %d", pos)
+		}
+		fourCC := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		if size < 0 || size > end-pos-8 {
+			return 0, 0, 0, newError(CodeCorrupt, "invalid webp: bad chunk size at offset %d", pos)
+		}
+		chunk := data[pos+8 : pos+8+size]
+
+		// the first chunk describes the image
+		if pos == 12 {
+			width, height, err = webpDimensions(fourCC, chunk)
+			if err != nil {
+				return 0, 0, 0, err
+			}
+		}
+		if fourCC == "VP8 " || fourCC == "VP8L" || fourCC == "ANMF" {
+			hasBitstream = true
+		}
+
+		// chunks are padded to an even size
+		pos += 8 + size + size%2
+	}
+
+	if !hasBitstream {
+		return 0, 0, 0, newError(CodeCorrupt, "invalid webp: no image data")
+	}
+	return end, width, height, nil
+}
+
+func webpDimensions(fourCC string, chunk []byte) (width int, height int, err error) {
+	switch fourCC {
+	case "VP8 ":
+		// frame tag (3), start code 9d 01 2a, 14 bit width & height
+		if len(chunk) < 10 || chunk[3] != 0x9d || chunk[4] != 0x01 || chunk[5] != 0x2a {
+			break
+		}
+		width = int(binary.LittleEndian.Uint16(chunk[6:]) & 0x3fff)
+		height = int(binary.LittleEndian.Uint16(chunk[8:]) & 0x3fff)
+		return
+	case "VP8L":
+		// signature 0x2f, 14 bit width - 1 & height - 1
+		if len(chunk) < 5 || chunk[0] != 0x2f {
+			break
+		}
+		bits := binary.LittleEndian.Uint32(chunk[1:])
+		width = int(bits&0x3fff) + 1
+		height = int(bits>>14&0x3fff) + 1
+		return
+	case "VP8X":
+		// flags (4), 24 bit canvas width - 1 & height - 1
+		if len(chunk) < 10 {
+			break
+		}
+		width = int(uint32(chunk[4])|uint32(chunk[5])<<8|uint32(chunk[6])<<16) + 1
+		height = int(uint32(chunk[7])|uint32(chunk[8])<<8|uint32(chunk[9])<<16) + 1
+		return
+	}
+	return 0, 0, newError(CodeCorrupt, "invalid webp: bad %q header", fourCC)
+}
@@ -0,0 +1,94 @@
+package images
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"image/png"
+)
+
+// how the image is fitted into the requested box
+const (
+	FitContain = "contain" // keep the whole image, the result may be smaller than the box
+	FitCover   = "cover"   // fill the box, the image is cropped around its center
+)
+
+// TransformOptions of an on the fly transformation, an empty width or height is unbounded
+type TransformOptions struct {
+	Width  int
+	Height int
+	Fit    string
+	Format string // jpeg or png, empty keeps the format of the original
+}
+
+// Transform resizes, crops and re-encodes a stored (already validated) image, images are never upscaled.
+// Stored photos are jpeg or png, webp can't be decoded with the stdlib & isn't an upload type.
+func Transform(data []byte, options TransformOptions) (out []byte, err error) {
+	format := sniff(data)
+	if format != FormatJPEG && format != FormatPNG {
+		return nil, newError(CodeUnsupportedType, "file is not a jpeg or png image")
+	}
+
+	img, err := decode(format, data)
+	if err != nil {
+		return nil, newError(CodeCorrupt, "invalid image: %v", err)
+	}
+
+	if options.Fit == FitCover && options.Width > 0 && options.Height > 0 {
+		img = cropToRatio(img, options.Width, options.Height)
+	}
+	width, height := fitBox(img.Bounds().Dx(), img.Bounds().Dy(), options.Width, options.Height)
+	img = resizeTo(img, width, height)
+
+	if options.Format != "" {
+		format = options.Format
+	}
+
+	var buf bytes.Buffer
+	if format == FormatPNG {
+		err = png.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: variantJpegQuality})
+	}
+	return buf.Bytes(), err
+}
+
+// fitBox returns the largest size with the ratio of the source which fits the box without upscaling
+func fitBox(srcWidth, srcHeight, boxWidth, boxHeight int) (width int, height int) {
+	if boxWidth <= 0 || boxWidth > srcWidth {
+		boxWidth = srcWidth
+	}
+	if boxHeight <= 0 || boxHeight > srcHeight {
+		boxHeight = srcHeight
+	}
+
+	// compare boxWidth/srcWidth with boxHeight/srcHeight without floats
+	if boxWidth*srcHeight <= boxHeight*srcWidth {
+		return boxWidth, atLeast(srcHeight*boxWidth/srcWidth, 1)
+	}
+	return atLeast(srcWidth*boxHeight/srcHeight, 1), boxHeight
+}
+
+// cropToRatio crops the center of an image to the ratio width:height
+func cropToRatio(img image.Image, width int, height int) image.Image {
+	bounds := img.Bounds()
+	srcWidth, srcHeight := bounds.Dx(), bounds.Dy()
+
+	cropWidth, cropHeight := srcWidth, srcHeight
+	if srcWidth*height > srcHeight*width {
+		cropWidth = atLeast(srcHeight*width/height, 1)
+	} else {
+		cropHeight = atLeast(srcWidth*height/width, 1)
+	}
+
+	crop := image.Rect(0, 0, cropWidth, cropHeight).
+		Add(image.Pt((srcWidth-cropWidth)/2, (srcHeight-cropHeight)/2))
+
+	// decoded images support sub images, fall back to copying the pixels
+	if subImager, ok := img.(interface {
+		SubImage(r image.Rectangle) image.Image
+	}); ok {
+		return subImager.SubImage(crop.Add(bounds.Min))
+	}
+	return resizeTo(img, srcWidth, srcHeight).SubImage(crop)
+}
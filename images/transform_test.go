@@ -0,0 +1,77 @@
+package images
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+)
+
+func TestFitBox(t *testing.T) {
+	tests := []struct {
+		name                  string
+		srcWidth, srcHeight   int
+		boxWidth, boxHeight   int
+		wantWidth, wantHeight int
+	}{
+		{"width only", 400, 200, 100, 0, 100, 50},
+		{"height only", 400, 200, 0, 100, 200, 100},
+		{"limited by width", 400, 200, 100, 100, 100, 50},
+		{"limited by height", 200, 400, 100, 100, 50, 100},
+		{"never upscaled", 400, 200, 800, 800, 400, 200},
+		{"unbounded", 400, 200, 0, 0, 400, 200},
+		{"at least one pixel", 1000, 1, 10, 0, 10, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			width, height := fitBox(test.srcWidth, test.srcHeight, test.boxWidth, test.boxHeight)
+			if width != test.wantWidth || height != test.wantHeight {
+				t.Errorf("fitBox() = %dx%d, want %dx%d", width, height, test.wantWidth, test.wantHeight)
+			}
+		})
+	}
+}
+
+func TestTransform(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 400, 200))); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	tests := []struct {
+		name                  string
+		options               TransformOptions
+		wantFormat            string
+		wantWidth, wantHeight int
+	}{
+		{"contain", TransformOptions{Width: 100, Height: 100, Fit: FitContain}, FormatPNG, 100, 50},
+		{"cover crops to the box", TransformOptions{Width: 100, Height: 100, Fit: FitCover}, FormatPNG, 100, 100},
+		{"cover never upscales", TransformOptions{Width: 800, Height: 800, Fit: FitCover}, FormatPNG, 200, 200},
+		{"format", TransformOptions{Width: 100, Fit: FitContain, Format: FormatJPEG}, FormatJPEG, 100, 50},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out, err := Transform(data, test.options)
+			if err != nil {
+				t.Fatalf("Transform() error = %v", err)
+			}
+			config, format, err := image.DecodeConfig(bytes.NewReader(out))
+			if err != nil {
+				t.Fatalf("decoding the transformed image: %v", err)
+			}
+			if format != test.wantFormat || config.Width != test.wantWidth || config.Height != test.wantHeight {
+				t.Errorf("Transform() = %v %dx%d, want %v %dx%d", format, config.Width, config.Height, test.wantFormat, test.wantWidth, test.wantHeight)
+			}
+		})
+	}
+}
+
+func TestTransformUnsupported(t *testing.T) {
+	_, err := Transform([]byte("not an image"), TransformOptions{Width: 100, Fit: FitContain})
+	if imageErr, ok := err.(*Error); !ok || imageErr.Code != CodeUnsupportedType {
+		t.Errorf("Transform() error = %v, want code %v", err, CodeUnsupportedType)
+	}
+}
@@ -0,0 +1,181 @@
+package images
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+)
+
+const (
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+	FormatWebP = "webp"
+)
+
+// Info describes a validated image
+type Info struct {
+	Format      string
+	ContentType string
+	Ext         string
+	Width       int
+	Height      int
+}
+
+// Ext returns the file extension of a supported content type, empty for other types
+func Ext(contentType string) string {
+	switch contentType {
+	case "image/jpeg":
+		return ".jpg"
+	case "image/png":
+		return ".png"
+	case "image/webp":
+		return ".webp"
+	}
+	return ""
+}
+
+// Limits of an uploaded image, dimensions are checked before decoding to stop decompression bombs
+type Limits struct {
+	MaxBytes  int64
+	MaxWidth  int
+	MaxHeight int
+	MaxPixels int
+}
+
+var DefaultLimits = Limits{
+	MaxBytes:  10 << 20, // 10 MiB
+	MaxWidth:  10000,
+	MaxHeight: 10000,
+	MaxPixels: 40_000_000,
+}
+
+// error codes of a rejected image
+const (
+	CodeTooLarge           = "file_too_large"
+	CodeUnsupportedType    = "unsupported_type"
+	CodeCorrupt            = "corrupt_image"
+	CodeTrailingData       = "trailing_data"
+	CodeDimensionsTooLarge = "dimensions_too_large"
+)
+
+// Error is returned when an image is rejected
+type Error struct {
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func newError(code string, format string, args ...any) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// Read reads an image up to the size limit and validates it
+func Read(r io.Reader, limits Limits) (data []byte, info Info, err error) {
+	// read one more byte than allowed to detect files that are too large
+	data, err = io.ReadAll(io.LimitReader(r, limits.MaxBytes+1))
+	if err != nil {
+		return
+	}
+	if int64(len(data)) > limits.MaxBytes {
+		return nil, info, newError(CodeTooLarge, "file is larger than %d bytes", limits.MaxBytes)
+	}
+
+	info, err = Validate(data, limits)
+	return
+}
+
+// Validate checks that data is a single well-formed jpeg, png or webp image within the limits:
+// the format is sniffed from the magic bytes (the file name isn't trusted), the structure is walked
+// to reject data appended after the image (e.g. a zip or script hidden behind a valid image)
+// and jpeg/png images are fully decoded
+func Validate(data []byte, limits Limits) (info Info, err error) {
+	info.Format = sniff(data)
+
+	var end int
+	switch info.Format {
+	case FormatJPEG:
+		info.ContentType, info.Ext = "image/jpeg", ".jpg"
+		end, err = jpegEnd(data)
+	case FormatPNG:
+		info.ContentType, info.Ext = "image/png", ".png"
+		end, err = pngEnd(data)
+	case FormatWebP:
+		info.ContentType, info.Ext = "image/webp", ".webp"
+		end, info.Width, info.Height, err = webpEnd(data)
+	default:
+		return info, newError(CodeUnsupportedType, "file is not a jpeg, png or webp image")
+	}
+	if err != nil {
+		return
+	}
+
+	// only padding is allowed after the end of the image
+	if len(bytes.Trim(data[end:], "\x00")) > 0 {
+		return info, newError(CodeTrailingData, "file contains data after the end of the image")
+	}
+
+	// the stdlib has no webp decoder, its dimensions are read from the bitstream header
+	if info.Format != FormatWebP {
+		var config image.Config
+		config, err = decodeConfig(info.Format, data)
+		if err != nil {
+			return info, newError(CodeCorrupt, "invalid image: %v", err)
+		}
+		info.Width, info.Height = config.Width, config.Height
+	}
+
+	if err = checkDimensions(info, limits); err != nil {
+		return
+	}
+
+	if info.Format != FormatWebP {
+		if _, err = decode(info.Format, data); err != nil {
+			return info, newError(CodeCorrupt, "invalid image: %v", err)
+		}
+	}
+	return
+}
+
+func sniff(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte("\xff\xd8\xff")):
+		return FormatJPEG
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return FormatPNG
+	case len(data) >= 12 && string(data[0:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return FormatWebP
+	}
+	return ""
+}
+
+func checkDimensions(info Info, limits Limits) error {
+	if info.Width <= 0 || info.Height <= 0 {
+		return newError(CodeCorrupt, "invalid image dimensions %dx%d", info.Width, info.Height)
+	}
+	if info.Width > limits.MaxWidth || info.Height > limits.MaxHeight || info.Width*info.Height > limits.MaxPixels {
+		return newError(CodeDimensionsTooLarge,
+			"image dimensions %dx%d exceed the limit of %dx%d and %d pixels",
+			info.Width, info.Height, limits.MaxWidth, limits.MaxHeight, limits.MaxPixels)
+	}
+	return nil
+}
+
+func decodeConfig(format string, data []byte) (image.Config, error) {
+	if format == FormatJPEG {
+		return jpeg.DecodeConfig(bytes.NewReader(data))
+	}
+	return png.DecodeConfig(bytes.NewReader(data))
+}
+
+func decode(format string, data []byte) (image.Image, error) {
+	if format == FormatJPEG {
+		return jpeg.Decode(bytes.NewReader(data))
+	}
+	return png.Decode(bytes.NewReader(data))
+}
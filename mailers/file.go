@@ -0,0 +1,38 @@
+package mailers
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FileMailer writes every email as an .eml file, useful for local development and tests
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+func NewFileMailer(dir, from string) Mailer {
+	return &FileMailer{
+		dir:  dir,
+		from: from,
+	}
+}
+
+func (f *FileMailer) Send(mail Mail) (err error) {
+	if err = os.MkdirAll(f.dir, 0o755); err != nil {
+		log.Printf("error creating mail directory: %v", err.Error())
+		return
+	}
+
+	fileName := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), uuid.New().String())
+	err = os.WriteFile(filepath.Join(f.dir, fileName), buildMessage(f.from, mail), 0o644)
+	if err != nil {
+		log.Printf("error writing email to %v: %v", mail.To, err.Error())
+	}
+	return
+}
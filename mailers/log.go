@@ -0,0 +1,19 @@
+package mailers
+
+import "log"
+
+// LogMailer writes every email to the application log
+type LogMailer struct {
+	from string
+}
+
+func NewLogMailer(from string) Mailer {
+	return &LogMailer{
+		from: from,
+	}
+}
+
+func (l *LogMailer) Send(mail Mail) (err error) {
+	log.Printf("email from %s to %s\nsubject: %s\n\n%s", l.from, mail.To, mail.Subject, mail.Body)
+	return
+}
@@ -0,0 +1,47 @@
+package mailers
+
+import (
+	"log"
+	"os"
+)
+
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends plain text emails (password reset, email verification, etc.)
+type Mailer interface {
+	Send(mail Mail) (err error)
+}
+
+// NewMailer returns the mailer for the given driver: "smtp" sends real emails
+// (e.g. to a local SMTP catcher), "file" writes .eml files to MAIL_DIR
+// and anything else logs the email.
+func NewMailer(driver string) Mailer {
+	from := os.Getenv("MAIL_FROM")
+	if from == "" {
+		from = "MyGram <no-reply@mygram.local>"
+	}
+
+	switch driver {
+	case "smtp":
+		return NewSmtpMailer(
+			os.Getenv("SMTP_HOST"),
+			os.Getenv("SMTP_PORT"),
+			os.Getenv("SMTP_USERNAME"),
+			os.Getenv("SMTP_PASSWORD"),
+			from,
+		)
+	case "file":
+		dir := os.Getenv("MAIL_DIR")
+		if dir == "" {
+			dir = "tmp/mails"
+		}
+		return NewFileMailer(dir, from)
+	default:
+		log.Println("using log mailer, emails are written to the application log")
+		return NewLogMailer(from)
+	}
+}
@@ -0,0 +1,70 @@
+package mailers
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+type SmtpMailer struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     string
+}
+
+func NewSmtpMailer(host, port, username, password, from string) Mailer {
+	return &SmtpMailer{
+		host:     host,
+		port:     port,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+func (s *SmtpMailer) Send(mail Mail) (err error) {
+	// authentication is optional, local SMTP catchers (e.g. mailpit) don't need it
+	var auth smtp.Auth
+	if s.username != "" {
+		auth = smtp.PlainAuth("", s.username, s.password, s.host)
+	}
+
+	err = smtp.SendMail(
+		net.JoinHostPort(s.host, s.port),
+		auth,
+		envelopeAddress(s.from),
+		[]string{mail.To},
+		buildMessage(s.from, mail),
+	)
+	if err != nil {
+		log.Printf("error sending email to %v: %v", mail.To, err.Error())
+	}
+	return
+}
+
+// buildMessage returns the raw RFC 5322 message of a plain text email
+func buildMessage(from string, mail Mail) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", mail.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mail.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(mail.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// envelopeAddress extracts the address from "Name <address>"
+func envelopeAddress(from string) string {
+	if start, end := strings.LastIndex(from, "<"), strings.LastIndex(from, ">"); start >= 0 && end > start {
+		return from[start+1 : end]
+	}
+	return from
+}
@@ -5,8 +5,8 @@ import (
 	"os"
 
 	"github.com/alvinmdj/mygram-api/database"
-	"github.com/alvinmdj/mygram-api/helpers"
 	"github.com/alvinmdj/mygram-api/routers"
+	"github.com/alvinmdj/mygram-api/storages"
 	"github.com/joho/godotenv"
 )
 
@@ -19,9 +19,20 @@ func init() {
 	}
 }
 
+// @title MyGram API
+// @version 1.0
+// @description This is service for MyGram.
+// @termsOfService http://swagger.io/terms/
+
+// @contact.name API Support
+// @contact.url http://www.swagger.io/support
+// @contact.email support@swagger.io
+
+// @license.name Apache 2.0
+// @license.url http://www.apache.org/licenses/LICENSE-2.0.html
 func main() {
 	database.StartDB()
-	helpers.InitCloudinary()
+	storages.StartStorage()
 	r := routers.StartApp()
 	r.Run()
 }
@@ -1,14 +1,17 @@
 package middlewares
 
 import (
+	"log"
 	"net/http"
 
 	"github.com/alvinmdj/mygram-api/helpers"
 	"github.com/alvinmdj/mygram-api/models"
+	"github.com/alvinmdj/mygram-api/repositories"
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 )
 
-func Authentication() gin.HandlerFunc {
+func Authentication(tokenRevocationRepo repositories.TokenRevocationRepoInterface) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		verifyToken, err := helpers.VerifyToken(c)
 		if err != nil {
@@ -19,6 +22,31 @@ func Authentication() gin.HandlerFunc {
 			return
 		}
 
+		// check if the token was revoked (logout / logout everywhere),
+		// jti & iat claims are guaranteed to exist by helpers.VerifyToken
+		claims := verifyToken.(jwt.MapClaims)
+		issuedAt, _ := claims.GetIssuedAt()
+		revoked, err := tokenRevocationRepo.IsRevoked(
+			claims["jti"].(string),
+			uint(claims["id"].(float64)),
+			issuedAt.Time,
+		)
+		if err != nil {
+			log.Printf("error checking token revocation: %v", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+				Error:   "INTERNAL SERVER ERROR",
+				Message: "failed to verify token",
+			})
+			return
+		}
+		if revoked {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, models.ErrorResponse{
+				Error:   "UNAUTHENTICATED",
+				Message: "token has been revoked, sign in again",
+			})
+			return
+		}
+
 		// store token claims in request data
 		c.Set("userData", verifyToken)
 		c.Next()
@@ -1,65 +1,113 @@
 package middlewares
 
 import (
+	"errors"
+	"log"
 	"net/http"
 	"strconv"
 
 	"github.com/alvinmdj/mygram-api/database"
 	"github.com/alvinmdj/mygram-api/models"
+	"github.com/alvinmdj/mygram-api/policies"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
 )
 
-func SocialMediaAuthorization() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		db := database.GetDB()
+// resourceLoader loads the data needed to evaluate a policy from the route params
+type resourceLoader struct {
+	param string
+	load  func(db *gorm.DB, c *gin.Context, id int) (policies.Resource, error)
+}
 
-		// get route param "socialMediaId"
-		socialMediaId, err := strconv.Atoi(c.Param("socialMediaId"))
-		if err != nil {
-			c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
-				Error:   "BAD REQUEST",
-				Message: "invalid parameter",
-			})
+var resourceLoaders = map[policies.ResourceType]resourceLoader{
+	policies.ResourceSocialMedia: {
+		param: "socialMediaId",
+		load: func(db *gorm.DB, c *gin.Context, id int) (resource policies.Resource, err error) {
+			socialMedia := models.SocialMedia{}
+
+			// get user_id column from social media table with the associated social media id
+			err = db.Debug().Select("id", "user_id").First(&socialMedia, id).Error
+			resource = policies.Resource{
+				Type:    policies.ResourceSocialMedia,
+				ID:      socialMedia.ID,
+				OwnerID: socialMedia.UserID,
+			}
 			return
-		}
-
-		// get token claims, which is set in authentication middleware
-		userData := c.MustGet("userData").(jwt.MapClaims)
-
-		// get user id from token claims
-		userId := uint(userData["id"].(float64))
-		socialMedia := models.SocialMedia{}
-
-		// get user_id column from social media table with the associated social media id
-		err = db.Debug().Select("user_id").First(&socialMedia, socialMediaId).Error
-		if err != nil {
-			c.AbortWithStatusJSON(http.StatusNotFound, models.ErrorResponse{
-				Error:   "NOT FOUND",
-				Message: "data doesn't exist",
-			})
+		},
+	},
+	policies.ResourcePhoto: {
+		param: "photoId",
+		load: func(db *gorm.DB, c *gin.Context, id int) (resource policies.Resource, err error) {
+			photo := models.Photo{}
+
+			// get user_id column from photo table with the associated photo id
+			err = db.Debug().Select("id", "user_id", "deleted_by").First(&photo, id).Error
+			resource = policies.Resource{
+				Type:      policies.ResourcePhoto,
+				ID:        photo.ID,
+				OwnerID:   photo.UserID,
+				DeletedBy: deletedBy(photo.DeletedBy),
+			}
 			return
-		}
-
-		// check if user id from db of the associated social media == user id from token claims
-		if socialMedia.UserID != userId {
-			c.AbortWithStatusJSON(http.StatusForbidden, models.ErrorResponse{
-				Error:   "FORBIDDEN",
-				Message: "you are not allowed to access this data",
-			})
+		},
+	},
+	policies.ResourceComment: {
+		param: "commentId",
+		load: func(db *gorm.DB, c *gin.Context, id int) (resource policies.Resource, err error) {
+			comment := models.Comment{}
+
+			// get user_id column from comment table with the associated comment & photo id
+			err = db.Debug().Select("id", "user_id", "photo_id", "deleted_by").
+				Where("photo_id = ?", c.Param("photoId")).
+				First(&comment, id).Error
+			if err != nil {
+				return
+			}
+
+			// the owner of the commented photo, the photo is loaded by the find photo middleware
+			photo, ok := c.Get("photo")
+			if !ok {
+				err = errors.New("photo not loaded, use the find photo middleware")
+				return
+			}
+
+			resource = policies.Resource{
+				Type:          policies.ResourceComment,
+				ID:            comment.ID,
+				OwnerID:       comment.UserID,
+				ParentOwnerID: photo.(models.Photo).UserID,
+				DeletedBy:     deletedBy(comment.DeletedBy),
+			}
 			return
-		}
+		},
+	},
+}
 
-		c.Next()
+func deletedBy(userId *uint) uint {
+	if userId == nil {
+		return 0
 	}
+	return *userId
 }
 
-func PhotoAuthorization() gin.HandlerFunc {
+// Authorize loads the resource of the route and evaluates the given policy against the
+// authenticated user, it must be used after the authentication middleware.
+func Authorize(policy policies.Policy) gin.HandlerFunc {
+	loader, ok := resourceLoaders[policy.Resource]
+	if !ok {
+		log.Fatalf("no resource loader for policy %v", policy)
+	}
+
 	return func(c *gin.Context) {
 		db := database.GetDB()
+		if policy.Action == policies.ActionRestore {
+			// resources in the trash are only found unscoped
+			db = db.Unscoped()
+		}
 
-		// get route param "photoId"
-		photoId, err := strconv.Atoi(c.Param("photoId"))
+		// get route param of the resource id
+		resourceId, err := strconv.Atoi(c.Param(loader.param))
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
 				Error:   "BAD REQUEST",
@@ -68,15 +116,7 @@ func PhotoAuthorization() gin.HandlerFunc {
 			return
 		}
 
-		// get token claims, which is set in authentication middleware
-		userData := c.MustGet("userData").(jwt.MapClaims)
-
-		// get user id from token claims
-		userId := uint(userData["id"].(float64))
-		photo := models.Photo{}
-
-		// get user_id column from photo table with the associated photo id
-		err = db.Debug().Select("user_id").First(&photo, photoId).Error
+		resource, err := loader.load(db, c, resourceId)
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusNotFound, models.ErrorResponse{
 				Error:   "NOT FOUND",
@@ -85,52 +125,19 @@ func PhotoAuthorization() gin.HandlerFunc {
 			return
 		}
 
-		// check if user id from db of the associated photo == user id from token claims
-		if photo.UserID != userId {
-			c.AbortWithStatusJSON(http.StatusForbidden, models.ErrorResponse{
-				Error:   "FORBIDDEN",
-				Message: "you are not allowed to access this data",
-			})
-			return
-		}
-
-		c.Next()
-	}
-}
-
-func CommentAuthorization() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		db := database.GetDB()
-
-		// get route param "commentId"
-		commentId, err := strconv.Atoi(c.Param("commentId"))
-		if err != nil {
-			c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
-				Error:   "BAD REQUEST",
-				Message: "invalid parameter",
-			})
-			return
-		}
-
 		// get token claims, which is set in authentication middleware
 		userData := c.MustGet("userData").(jwt.MapClaims)
-
-		// get user id from token claims
-		userId := uint(userData["id"].(float64))
-		comment := models.Comment{}
-
-		// get user_id column from comment table with the associated comment id
-		err = db.Debug().Select("user_id").First(&comment, commentId).Error
-		if err != nil {
-			c.AbortWithStatusJSON(http.StatusNotFound, models.ErrorResponse{
-				Error:   "NOT FOUND",
-				Message: "data doesn't exist",
-			})
-			return
+		subject := policies.Subject{
+			UserID: uint(userData["id"].(float64)),
+			Role:   claimRole(userData),
 		}
 
-		// check if user id from db of the associated comment == user id from token claims
-		if comment.UserID != userId {
+		decision := policy.Evaluate(subject, resource)
+		if !decision.Allowed {
+			log.Printf(
+				"authorization denied: policy %v, user %d (%s), %s %d: %s",
+				policy, subject.UserID, subject.Role, resource.Type, resource.ID, decision.Reason,
+			)
 			c.AbortWithStatusJSON(http.StatusForbidden, models.ErrorResponse{
 				Error:   "FORBIDDEN",
 				Message: "you are not allowed to access this data",
@@ -3,13 +3,24 @@ package middlewares
 import (
 	"net/http"
 
+	"github.com/alvinmdj/mygram-api/configs"
 	"github.com/alvinmdj/mygram-api/models"
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 )
 
-func BodySizeMiddleware() gin.HandlerFunc {
+// room for the other fields of a multipart form besides the file
+const multipartOverhead = 64 << 10 // 64 KiB
+
+// BodySizeMiddleware limits the request body to the upload limit of the user role,
+// the file itself is checked against the exact limit by the photo service
+func BodySizeMiddleware(uploadConfig configs.UploadConfig) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		maxBodyBytes := int64(2 << 20) // 2 MiB
+		role := models.RoleUser
+		if userData, ok := c.Get("userData"); ok {
+			role = claimRole(userData.(jwt.MapClaims))
+		}
+		maxBodyBytes := uploadConfig.For(role).MaxBytes + multipartOverhead
 
 		var w http.ResponseWriter = c.Writer
 		c.Request.Body = http.MaxBytesReader(w, c.Request.Body, maxBodyBytes)
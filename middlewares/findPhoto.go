@@ -6,7 +6,9 @@ import (
 
 	"github.com/alvinmdj/mygram-api/database"
 	"github.com/alvinmdj/mygram-api/models"
+	"github.com/alvinmdj/mygram-api/repositories"
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 )
 
 func FindPhoto() gin.HandlerFunc {
@@ -24,8 +26,11 @@ func FindPhoto() gin.HandlerFunc {
 			return
 		}
 
-		// check if photo exists
-		err = db.Debug().First(&photo, photoId).Error
+		// check if photo exists & is visible to the user, photos of private accounts (with their likes & comments)
+		// are only visible to their accepted followers
+		userData := c.MustGet("userData").(jwt.MapClaims)
+		visibleTo := repositories.PhotosVisibleTo(uint(userData["id"].(float64)), models.IsStaff(claimRole(userData)))
+		err = db.Debug().Scopes(visibleTo).First(&photo, photoId).Error
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusNotFound, models.ErrorResponse{
 				Error:   "NOT FOUND",
@@ -33,5 +38,9 @@ func FindPhoto() gin.HandlerFunc {
 			})
 			return
 		}
+
+		// store the photo in request data for the next middlewares & handlers
+		c.Set("photo", photo)
+		c.Next()
 	}
 }
@@ -0,0 +1,47 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/alvinmdj/mygram-api/models"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// RequireRole only lets users with one of the given roles through,
+// it must be used after the authentication middleware.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// get token claims, which is set in authentication middleware
+		userData := c.MustGet("userData").(jwt.MapClaims)
+
+		if !hasRole(userData, roles...) {
+			c.AbortWithStatusJSON(http.StatusForbidden, models.ErrorResponse{
+				Error:   "FORBIDDEN",
+				Message: "you are not allowed to access this resource",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// claimRole returns the role claim, tokens without role claim belong to regular users
+func claimRole(userData jwt.MapClaims) string {
+	role, ok := userData["role"].(string)
+	if !ok {
+		return models.RoleUser
+	}
+	return role
+}
+
+func hasRole(userData jwt.MapClaims, roles ...string) bool {
+	role := claimRole(userData)
+	for _, allowed := range roles {
+		if role == allowed {
+			return true
+		}
+	}
+	return false
+}
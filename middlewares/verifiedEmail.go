@@ -0,0 +1,45 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/alvinmdj/mygram-api/database"
+	"github.com/alvinmdj/mygram-api/models"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// VerifiedEmail only lets users with a verified email through,
+// it must be used after the authentication middleware.
+func VerifiedEmail() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := database.GetDB()
+
+		// get token claims, which is set in authentication middleware
+		userData := c.MustGet("userData").(jwt.MapClaims)
+
+		// get user id from token claims
+		userId := uint(userData["id"].(float64))
+		user := models.User{}
+
+		// get verified_at column from user table
+		err := db.Debug().Select("verified_at").First(&user, userId).Error
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, models.ErrorResponse{
+				Error:   "UNAUTHENTICATED",
+				Message: "user doesn't exist",
+			})
+			return
+		}
+
+		if user.VerifiedAt == nil {
+			c.AbortWithStatusJSON(http.StatusForbidden, models.ErrorResponse{
+				Error:   "FORBIDDEN",
+				Message: "verify your email to proceed",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -1,17 +1,22 @@
 package models
 
 import (
+	"time"
+
 	"github.com/asaskevich/govalidator"
 	"gorm.io/gorm"
 )
 
 type Comment struct {
 	Base
-	Message string `gorm:"not null" json:"message" form:"message" valid:"required~message is required"`
-	UserID  uint
-	PhotoID uint
-	User    User
-	Photo   Photo
+	Message   string `gorm:"not null" json:"message" form:"message" valid:"required~message is required"`
+	HiddenAt  *time.Time
+	UserID    uint
+	PhotoID   uint
+	User      User
+	Photo     Photo
+	DeletedAt gorm.DeletedAt `gorm:"index"`        // in the trash, purged after the retention period
+	DeletedBy *uint          `gorm:"default:null"` // user who moved the comment to the trash
 }
 
 func (c *Comment) BeforeCreate(tx *gorm.DB) (err error) {
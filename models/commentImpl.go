@@ -1,9 +1,12 @@
 package models
 
+import "time"
+
 type CommentGetOutput struct {
 	Base
-	Message string             `json:"message"`
-	User    UserRegisterOutput `json:"user"`
+	Message  string             `json:"message"`
+	HiddenAt *time.Time         `json:"hidden_at,omitempty"`
+	User     UserRegisterOutput `json:"user"`
 }
 
 type CommentCreateInput struct {
@@ -4,7 +4,12 @@ type DeleteResponse struct {
 	Message string `json:"message"`
 }
 
+type MessageResponse struct {
+	Message string `json:"message"`
+}
+
 type ErrorResponse struct {
 	Error   string `json:"error"`
 	Message string `json:"message"`
+	Code    string `json:"code,omitempty"` // machine readable reason, e.g. for rejected uploads
 }
@@ -0,0 +1,13 @@
+package models
+
+type UploadLimitsOutput struct {
+	MaxBytes     int64    `json:"max_bytes"`
+	MaxPixels    int      `json:"max_pixels"`
+	AllowedTypes []string `json:"allowed_types"`
+	StorageQuota int64    `json:"storage_quota"` // default quota of all stored photos of a user
+}
+
+type UploadConfigOutput struct {
+	Default UploadLimitsOutput            `json:"default"`
+	Roles   map[string]UploadLimitsOutput `json:"roles"` // limits of the roles with overrides
+}
@@ -0,0 +1,7 @@
+package models
+
+// ExplorePhotoOutput is a trending photo, ranked by its time-decayed engagement score
+type ExplorePhotoOutput struct {
+	PhotoGetOutput
+	Score float64 `json:"score"`
+}
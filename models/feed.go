@@ -0,0 +1,7 @@
+package models
+
+// FeedPhotoOutput is a photo in the home feed with a preview of its latest comments
+type FeedPhotoOutput struct {
+	PhotoGetOutput
+	Comments []CommentGetOutput `json:"comments"`
+}
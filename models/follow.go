@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// follow states, following a private account needs the approval of its owner
+const (
+	FollowStatusPending  = "pending"
+	FollowStatusAccepted = "accepted"
+)
+
+// Follow is a user (follower) following another user (following)
+type Follow struct {
+	Base
+	FollowerID  uint       `gorm:"not null;uniqueIndex:idx_follows_follower_id_following_id"`
+	FollowingID uint       `gorm:"not null;uniqueIndex:idx_follows_follower_id_following_id;index"`
+	Status      string     `gorm:"not null;default:accepted"`
+	AcceptedAt  *time.Time `gorm:"default:null"`
+	Follower    User       `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Following   User       `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
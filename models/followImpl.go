@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// FollowGetOutput is a follower, a followed user or a follow request
+type FollowGetOutput struct {
+	Base
+	Status     string            `json:"status"`
+	AcceptedAt *time.Time        `json:"accepted_at,omitempty"`
+	User       UserProfileOutput `json:"user"`
+}
+
+// FollowOutput is the follow state of the user after following or unfollowing
+type FollowOutput struct {
+	UserID   uint   `json:"user_id"`
+	Username string `json:"username"`
+	Status   string `json:"status"` // pending, accepted or none
+}
+
+type UserPrivacyInput struct {
+	// follow requests need approval, making the account public accepts the pending requests
+	IsPrivate bool `json:"is_private" form:"is_private"`
+}
@@ -0,0 +1,13 @@
+package models
+
+// ImageTransformInput are the query parameters of an on the fly transformed photo
+type ImageTransformInput struct {
+	Width  int    `form:"w"`
+	Height int    `form:"h"`
+	Fit    string `form:"fit"`
+	Format string `form:"fmt"`
+}
+
+type ImageURLOutput struct {
+	URL string `json:"url"`
+}
@@ -0,0 +1,10 @@
+package models
+
+// Like is a reaction of a user to a photo, a user can like a photo once
+type Like struct {
+	Base
+	UserID  uint  `gorm:"not null;uniqueIndex:idx_likes_user_id_photo_id"`
+	PhotoID uint  `gorm:"not null;uniqueIndex:idx_likes_user_id_photo_id;index"`
+	User    User  `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Photo   Photo `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
@@ -0,0 +1,13 @@
+package models
+
+type LikeGetOutput struct {
+	Base
+	User UserProfileOutput `json:"user"`
+}
+
+// LikeOutput is the like state of a photo after liking or unliking it
+type LikeOutput struct {
+	PhotoID   uint  `json:"photo_id"`
+	LikeCount int64 `json:"like_count"`
+	LikedByMe bool  `json:"liked_by_me"`
+}
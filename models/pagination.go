@@ -0,0 +1,16 @@
+package models
+
+// PageInput is the query of list endpoints: ?limit=&cursor=&sort=
+// sort is a whitelisted field, prefixed with "-" for descending order (e.g. -created_at)
+type PageInput struct {
+	Limit  int    `form:"limit"`
+	Cursor string `form:"cursor"`
+	Sort   string `form:"sort"`
+}
+
+// PaginatedResponse is the envelope of list endpoints,
+// next_cursor is empty when there is no next page
+type PaginatedResponse struct {
+	Data       interface{} `json:"data"`
+	NextCursor string      `json:"next_cursor"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// PendingDeletion is a stored file which couldn't be deleted yet, deletions are retried by a background worker
+type PendingDeletion struct {
+	StorageKey    string    `gorm:"primaryKey"`
+	Attempts      int       `gorm:"not null;default:0"`
+	NextAttemptAt time.Time `gorm:"not null;index"`
+	LastError     string    `gorm:"default:null"`
+	CreatedAt     time.Time
+}
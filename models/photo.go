@@ -1,18 +1,41 @@
 package models
 
 import (
+	"time"
+
 	"github.com/asaskevich/govalidator"
 	"gorm.io/gorm"
 )
 
+// who may comment on a photo
+const (
+	CommentPolicyEveryone  = "everyone"
+	CommentPolicyFollowers = "followers"
+	CommentPolicyOff       = "off"
+)
+
 type Photo struct {
 	Base
-	Title    string `gorm:"not null"`
-	Caption  string `gorm:"not null"`
-	PhotoURL string `gorm:"not null"`
-	UserID   uint
-	User     User
-	Comments []Comment `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Title         string     `gorm:"not null"`
+	Caption       string     `gorm:"not null"`
+	PhotoURL      string     `gorm:"not null"`
+	StorageKey    string     `gorm:"default:null"`
+	FileSize      int64      `gorm:"not null;default:0"` // bytes of the stored original
+	CommentPolicy string     `gorm:"not null;default:everyone"`
+	TakenAt       *time.Time `gorm:"default:null"` // kept from the uploaded file when the user opts in
+	Orientation   int        `gorm:"not null;default:0"`
+	CameraModel   string     `gorm:"default:null"`
+	LikeCount     int64      `gorm:"not null;default:0"` // denormalized count of the likes, see cmd/repair-like-counts
+	LikedByMe     bool       `gorm:"-"`                  // set for the user viewing the photo
+	UserID        uint
+	User          User
+	Comments      []Comment      `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Variants      []PhotoVariant `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Versions      []PhotoVersion `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"` // previous versions, only loaded when needed
+	Ranking       *PhotoRanking  `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"` // explore score, only loaded for explore
+	DeletedAt     gorm.DeletedAt `gorm:"index"`                                         // in the trash, purged after the retention period
+	DeletedBy     *uint          `gorm:"default:null"`                                  // user who moved the photo to the trash
+	FannedOut     bool           `gorm:"not null;default:false"`                        // written into the timelines of the followers of its author
 }
 
 func (p *Photo) BeforeCreate(tx *gorm.DB) (err error) {
@@ -1,11 +1,24 @@
 package models
 
+import "time"
+
 type PhotoGetOutput struct {
 	Base
-	Title    string             `json:"title"`
-	Caption  string             `json:"caption"`
-	PhotoURL string             `json:"photo_url"`
-	User     UserRegisterOutput `json:"user"`
+	Title         string               `json:"title"`
+	Caption       string               `json:"caption"`
+	PhotoURL      string               `json:"photo_url"`
+	CommentPolicy string               `json:"comment_policy"`
+	Metadata      *PhotoMetadataOutput `json:"metadata,omitempty"`
+	Variants      map[string]string    `json:"variants,omitempty"` // resized copies by size (longest side in px), e.g. "640"
+	LikeCount     int64                `json:"like_count"`
+	LikedByMe     bool                 `json:"liked_by_me"`
+	User          UserRegisterOutput   `json:"user"`
+}
+
+type PhotoMetadataOutput struct {
+	TakenAt     *time.Time `json:"taken_at,omitempty"`
+	Orientation int        `json:"orientation,omitempty"`
+	CameraModel string     `json:"camera_model,omitempty"`
 }
 
 type PhotoCreateInput struct {
@@ -13,20 +26,28 @@ type PhotoCreateInput struct {
 	Caption  string `form:"caption" valid:"required~caption is required"`
 	PhotoURL string `form:"photo_url" valid:"required~photo URL is required"`
 	UserID   uint   `valid:"required~user ID is required"`
+	Role     string `form:"-"` // role of the user, selects the upload limits
+	FileSize int64  `form:"-"` // announced size of the file, checked against the quota before it's processed
+	// keep capture time, orientation & camera model of the uploaded file, other metadata is always removed
+	KeepMetadata bool `form:"keep_metadata"`
 }
 
 // this struct only used for swagger docs to generate desired input
 type PhotoCreateInputSwagger struct {
-	Title   string `form:"title"`
-	Caption string `form:"caption"`
+	Title        string `form:"title"`
+	Caption      string `form:"caption"`
+	KeepMetadata bool   `form:"keep_metadata"`
 }
 
 type PhotoCreateOutput struct {
 	Base
-	Title    string `json:"title"`
-	Caption  string `json:"caption"`
-	PhotoURL string `json:"photo_url"`
-	UserID   uint   `json:"user_id"`
+	Title         string               `json:"title"`
+	Caption       string               `json:"caption"`
+	PhotoURL      string               `json:"photo_url"`
+	CommentPolicy string               `json:"comment_policy"`
+	Metadata      *PhotoMetadataOutput `json:"metadata,omitempty"`
+	Variants      map[string]string    `json:"variants,omitempty"`
+	UserID        uint                 `json:"user_id"`
 }
 
 type PhotoUpdateInput struct {
@@ -35,8 +56,55 @@ type PhotoUpdateInput struct {
 	Caption  string `form:"caption" valid:"required~caption is required"`
 	PhotoURL string `form:"photo_url" valid:"required~photo URL is required"`
 	UserID   uint   `valid:"required~user ID is required"`
+	Role     string `form:"-"`
+	FileSize int64  `form:"-"`
+	// only applies to a newly uploaded file, without a new file the kept metadata stays as is
+	KeepMetadata bool `form:"keep_metadata"`
 }
 
 type PhotoUpdateInputSwagger = PhotoCreateInputSwagger
 
 type PhotoUpdateOutput = PhotoCreateOutput
+
+type PhotoSettingsInput struct {
+	ID            uint   `valid:"required~ID is required"`
+	CommentPolicy string `json:"comment_policy" form:"comment_policy" valid:"required~comment policy is required,in(everyone|followers|off)~comment policy must be one of everyone, followers or off"`
+}
+
+// this struct only used for swagger docs to generate desired input
+type PhotoSettingsInputSwagger struct {
+	CommentPolicy string `json:"comment_policy" form:"comment_policy"`
+}
+
+// PhotoUploadInput requests a presigned url to upload a photo file directly to the storage
+type PhotoUploadInput struct {
+	ContentType string `json:"content_type" valid:"required~content type is required,in(image/jpeg|image/png)~content type must be image/jpeg or image/png"`
+	Size        int64  `json:"size" valid:"required~size is required"`
+	Role        string `json:"-"`
+}
+
+type PhotoUploadOutput struct {
+	UploadKey string            `json:"upload_key"`
+	URL       string            `json:"url"`
+	Method    string            `json:"method"`
+	Headers   map[string]string `json:"headers"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+// PhotoCreateFromUploadInput creates a photo from a file uploaded with a presigned url
+type PhotoCreateFromUploadInput struct {
+	Title        string `json:"title"`
+	Caption      string `json:"caption"`
+	UploadKey    string `json:"upload_key"`
+	KeepMetadata bool   `json:"keep_metadata"`
+}
+
+type PhotoVersionOutput struct {
+	Version   int                  `json:"version"`
+	Title     string               `json:"title"`
+	Caption   string               `json:"caption"`
+	PhotoURL  string               `json:"photo_url"`
+	Metadata  *PhotoMetadataOutput `json:"metadata,omitempty"`
+	Variants  map[string]string    `json:"variants,omitempty"`
+	CreatedAt *time.Time           `json:"created_at"` // when the photo was changed from this version
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// PhotoRanking is the explore score of a recent photo, the table is recomputed periodically
+type PhotoRanking struct {
+	PhotoID    uint      `gorm:"primaryKey;autoIncrement:false"`
+	Score      float64   `gorm:"not null;index"`
+	Likes      int64     `gorm:"not null;default:0"`
+	Comments   int64     `gorm:"not null;default:0"` // comments of other users than the owner of the photo
+	Commenters int64     `gorm:"not null;default:0"` // unique commenters, the owner of the photo excluded
+	ComputedAt time.Time `gorm:"not null"`
+}
+
+// PhotoEngagement is the engagement of a photo the explore score is computed from
+type PhotoEngagement struct {
+	PhotoID    uint
+	CreatedAt  time.Time
+	Likes      int64
+	Comments   int64
+	Commenters int64
+}
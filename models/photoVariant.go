@@ -0,0 +1,13 @@
+package models
+
+// PhotoVariant is a resized copy of a photo, generated on upload
+type PhotoVariant struct {
+	Base
+	PhotoID    uint   `gorm:"not null;uniqueIndex:idx_photo_variants_photo_id_size"`
+	Size       int    `gorm:"not null;uniqueIndex:idx_photo_variants_photo_id_size"`
+	Width      int    `gorm:"not null"`
+	Height     int    `gorm:"not null"`
+	StorageKey string `gorm:"not null"`
+	FileSize   int64  `gorm:"not null;default:0"` // bytes
+	URL        string `gorm:"not null"`
+}
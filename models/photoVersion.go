@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// PhotoVersion is an immutable snapshot of a photo taken before it was updated or reverted,
+// its stored files are kept until the version is pruned or the photo is purged
+type PhotoVersion struct {
+	Base
+	PhotoID     uint                  `gorm:"not null;uniqueIndex:idx_photo_versions_photo_id_version"`
+	Version     int                   `gorm:"not null;uniqueIndex:idx_photo_versions_photo_id_version"` // 1, 2, ... per photo
+	Title       string                `gorm:"not null"`
+	Caption     string                `gorm:"not null"`
+	PhotoURL    string                `gorm:"not null"`
+	StorageKey  string                `gorm:"default:null"`
+	FileSize    int64                 `gorm:"not null;default:0"`
+	TakenAt     *time.Time            `gorm:"default:null"`
+	Orientation int                   `gorm:"not null;default:0"`
+	CameraModel string                `gorm:"default:null"`
+	Variants    []PhotoVersionVariant `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+// PhotoVersionVariant is a resized copy of the file of a photo version
+type PhotoVersionVariant struct {
+	Base
+	PhotoVersionID uint   `gorm:"not null;index"`
+	Size           int    `gorm:"not null"`
+	Width          int    `gorm:"not null"`
+	Height         int    `gorm:"not null"`
+	StorageKey     string `gorm:"not null"`
+	FileSize       int64  `gorm:"not null;default:0"`
+	URL            string `gorm:"not null"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// RefreshToken is a server-side record of an issued refresh token.
+// Every rotation issues a new token in the same family, the previous token is revoked.
+type RefreshToken struct {
+	Base
+	TokenHash string    `gorm:"not null;uniqueIndex"`
+	FamilyID  string    `gorm:"not null;index"`
+	ExpiresAt time.Time `gorm:"not null"`
+	RevokedAt *time.Time
+	UserID    uint `gorm:"not null;index"`
+	User      User `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// RevokedToken is an access token (by its jti claim) that was revoked before it expired
+type RevokedToken struct {
+	JTI       string    `gorm:"primaryKey"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+}
+
+// UserTokenRevocation revokes every access token of a user issued up to RevokedBefore (log out everywhere)
+type UserTokenRevocation struct {
+	UserID        uint      `gorm:"primaryKey;autoIncrement:false"`
+	RevokedBefore time.Time `gorm:"not null"`
+}
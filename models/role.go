@@ -0,0 +1,24 @@
+package models
+
+// user roles, carried in the "role" claim of the access token
+const (
+	RoleUser      = "user"
+	RolePremium   = "premium" // a regular user with higher upload limits
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+var Roles = []string{RoleUser, RolePremium, RoleModerator, RoleAdmin}
+
+func IsValidRole(role string) bool {
+	switch role {
+	case RoleUser, RolePremium, RoleModerator, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+// IsStaff checks if the role can moderate content of other users
+func IsStaff(role string) bool {
+	return role == RoleModerator || role == RoleAdmin
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// TimelineEntry is a photo in the home feed of a user, written when a followed user posts a photo (fan-out on write).
+// photos of users with very many followers have no entries, they are read from the follows instead (fan-out on read), see Photo.FannedOut
+type TimelineEntry struct {
+	ID       uint  `gorm:"primaryKey"`
+	UserID   uint  `gorm:"not null;uniqueIndex:idx_timeline_entries_user_id_photo_id;index:idx_timeline_entries_user_id_author_id;index:idx_timeline_entries_user_id_created_at"`
+	PhotoID  uint  `gorm:"not null;uniqueIndex:idx_timeline_entries_user_id_photo_id"`
+	AuthorID uint  `gorm:"not null;index:idx_timeline_entries_user_id_author_id"`
+	User     User  `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Photo    Photo `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	// the creation time of the photo, not of the entry, so the feed is paged over the entries in the order of the photos
+	CreatedAt *time.Time `gorm:"index:idx_timeline_entries_user_id_created_at"`
+	UpdatedAt *time.Time
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+type TrashPhotoOutput struct {
+	Base
+	Title     string    `json:"title"`
+	Caption   string    `json:"caption"`
+	PhotoURL  string    `json:"photo_url"`
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"` // deleted for good after this time
+}
+
+type TrashCommentOutput struct {
+	Base
+	Message   string    `json:"message"`
+	PhotoID   uint      `json:"photo_id"`
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
+
+type TrashOutput struct {
+	Photos   []TrashPhotoOutput   `json:"photos"`
+	Comments []TrashCommentOutput `json:"comments"`
+}
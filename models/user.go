@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"github.com/alvinmdj/mygram-api/helpers"
 	"github.com/asaskevich/govalidator"
 	"gorm.io/gorm"
@@ -8,13 +10,21 @@ import (
 
 type User struct {
 	Base
-	Username     string        `gorm:"not null;uniqueIndex"`
-	Email        string        `gorm:"not null;uniqueIndex"`
-	Password     string        `gorm:"not null"`
-	Age          int           `gorm:"not null"`
-	Photos       []Photo       `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
-	Comments     []Comment     `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
-	SocialMedias []SocialMedia `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Username       string        `gorm:"not null;uniqueIndex"`
+	Email          string        `gorm:"not null;uniqueIndex"`
+	Password       string        `gorm:"not null"`
+	Age            int           `gorm:"not null"`
+	VerifiedAt     *time.Time    `gorm:"default:null"`
+	Role           string        `gorm:"not null;default:user"`
+	BlockedAt      *time.Time    `gorm:"default:null"`
+	StorageUsed    int64         `gorm:"not null;default:0"`     // bytes of the stored photos & their variants
+	StorageQuota   *int64        `gorm:"default:null"`           // bytes, null uses the default quota of the role
+	IsPrivate      bool          `gorm:"not null;default:false"` // follow requests need approval
+	FollowerCount  int64         `gorm:"not null;default:0"`     // denormalized counts of the accepted follows
+	FollowingCount int64         `gorm:"not null;default:0"`
+	Photos         []Photo       `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Comments       []Comment     `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	SocialMedias   []SocialMedia `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
 }
 
 func (u *User) BeforeCreate(tx *gorm.DB) (err error) {
@@ -33,3 +43,15 @@ func (u *User) BeforeCreate(tx *gorm.DB) (err error) {
 	u.Password, err = helpers.HashPassword(u.Password)
 	return
 }
+
+func (u *User) BeforeUpdate(tx *gorm.DB) (err error) {
+	// validate input
+	input := UserUpdateInput{
+		ID:       u.ID,
+		Username: u.Username,
+		Email:    u.Email,
+		Age:      u.Age,
+	}
+	_, err = govalidator.ValidateStruct(input)
+	return
+}
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type UserRegisterInput struct {
 	Username string `json:"username" form:"username" valid:"required~username is required"`
 	Email    string `json:"email" form:"email" valid:"required~email is required,email~invalid email format"`
@@ -8,17 +10,106 @@ type UserRegisterInput struct {
 }
 
 type UserRegisterOutput struct {
+	Base
+	Username   string     `json:"username"`
+	Email      string     `json:"email"`
+	Age        int        `json:"age"`
+	VerifiedAt *time.Time `json:"verified_at,omitempty"`
+}
+
+type UserUpdateInput struct {
+	ID       uint   `valid:"required~ID is required"`
+	Username string `json:"username" form:"username" valid:"required~username is required"`
+	Email    string `json:"email" form:"email" valid:"required~email is required,email~invalid email format"`
+	Age      int    `json:"age" form:"age" valid:"required~age is required,range(8|99)~user must be at least 8 years old"`
+}
+
+// this struct only used for swagger docs to generate desired input
+type UserUpdateInputSwagger struct {
+	Username string `json:"username" form:"username"`
+	Email    string `json:"email" form:"email"`
+	Age      int    `json:"age" form:"age"`
+}
+
+type UserUpdateOutput = UserRegisterOutput
+
+// UserProfileOutput is the public profile of a user, it doesn't expose the email
+type UserProfileOutput struct {
 	Base
 	Username string `json:"username"`
-	Email    string `json:"email"`
 	Age      int    `json:"age"`
 }
 
+// UserProfileDetailOutput is the public profile of a user with the follow counts
+type UserProfileDetailOutput struct {
+	UserProfileOutput
+	IsPrivate      bool  `json:"is_private"`
+	FollowerCount  int64 `json:"follower_count"`
+	FollowingCount int64 `json:"following_count"`
+}
+
+type UserChangePasswordInput struct {
+	CurrentPassword string `json:"current_password" form:"current_password" valid:"required~current password is required"`
+	NewPassword     string `json:"new_password" form:"new_password" valid:"required~new password is required,minstringlength(6)~password must have a minimum length of 6 characters"`
+}
+
+type UserForgotPasswordInput struct {
+	Email string `json:"email" form:"email" valid:"required~email is required,email~invalid email format"`
+}
+
+type UserResetPasswordInput struct {
+	Token       string `json:"token" form:"token" valid:"required~token is required"`
+	NewPassword string `json:"new_password" form:"new_password" valid:"required~new password is required,minstringlength(6)~password must have a minimum length of 6 characters"`
+}
+
+type UserResendVerificationInput struct {
+	Email string `json:"email" form:"email" valid:"required~email is required,email~invalid email format"`
+}
+
+type UserRoleUpdateInput struct {
+	Role string `json:"role" form:"role" valid:"required~role is required,in(user|premium|moderator|admin)~role must be one of user, premium, moderator or admin"`
+}
+
+// UserAdminOutput is the user data shown to admins
+type UserAdminOutput struct {
+	Base
+	Username   string     `json:"username"`
+	Email      string     `json:"email"`
+	Age        int        `json:"age"`
+	Role       string     `json:"role"`
+	VerifiedAt *time.Time `json:"verified_at"`
+	BlockedAt  *time.Time `json:"blocked_at"`
+	// storage quota set for the user, null uses the default quota of the role
+	StorageUsed  int64  `json:"storage_used"`
+	StorageQuota *int64 `json:"storage_quota"`
+}
+
+type UserQuotaUpdateInput struct {
+	// bytes, null resets the quota to the default quota of the role
+	StorageQuota *int64 `json:"storage_quota"`
+}
+
+type UserUsageOutput struct {
+	StorageUsed      int64 `json:"storage_used"`
+	StorageQuota     int64 `json:"storage_quota"`
+	StorageAvailable int64 `json:"storage_available"`
+}
+
 type UserLoginInput struct {
 	Email    string `json:"email" form:"email"`
 	Password string `json:"password" form:"password"`
 }
 
 type UserLoginOutput struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+type UserRefreshInput struct {
+	RefreshToken string `json:"refresh_token" form:"refresh_token" valid:"required~refresh token is required"`
+}
+
+type UserLogoutInput struct {
+	RefreshToken string `json:"refresh_token" form:"refresh_token"`
 }
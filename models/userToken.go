@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// purposes of a user token
+const (
+	UserTokenPasswordReset     = "password_reset"
+	UserTokenEmailVerification = "email_verification"
+)
+
+// UserToken is a single-use, time-limited token sent to the user by email.
+// Only the hash of the token is stored.
+type UserToken struct {
+	Base
+	Purpose   string    `gorm:"not null;index"`
+	TokenHash string    `gorm:"not null;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	UserID    uint `gorm:"not null;index"`
+	User      User `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
@@ -0,0 +1,92 @@
+package policies
+
+import (
+	"fmt"
+	"strings"
+)
+
+type ResourceType string
+
+const (
+	ResourcePhoto       ResourceType = "photo"
+	ResourceComment     ResourceType = "comment"
+	ResourceSocialMedia ResourceType = "social_media"
+)
+
+type Action string
+
+const (
+	ActionRead    Action = "read"
+	ActionUpdate  Action = "update"
+	ActionDelete  Action = "delete"
+	ActionHide    Action = "hide"
+	ActionRestore Action = "restore" // the resource is in the trash (soft deleted)
+)
+
+// Subject is the authenticated user performing an action
+type Subject struct {
+	UserID uint
+	Role   string
+}
+
+// Resource is the data an action is performed on
+type Resource struct {
+	Type    ResourceType
+	ID      uint
+	OwnerID uint
+	// owner of the parent resource, e.g. the owner of the photo a comment belongs to
+	ParentOwnerID uint
+	// user who moved the resource to the trash, 0 when it isn't in the trash (or unknown)
+	DeletedBy uint
+}
+
+// Decision is the result of evaluating a policy, Reason explains why access is (not) granted
+type Decision struct {
+	Allowed bool
+	Reason  string
+}
+
+// Policy declares who may perform an action on a resource type,
+// access is granted when at least one of the rules matches.
+type Policy struct {
+	Resource ResourceType
+	Action   Action
+	Rules    []Rule
+}
+
+func New(resource ResourceType, action Action, rules ...Rule) Policy {
+	return Policy{
+		Resource: resource,
+		Action:   action,
+		Rules:    rules,
+	}
+}
+
+func (p Policy) Evaluate(subject Subject, resource Resource) Decision {
+	if resource.Type != p.Resource {
+		return Decision{
+			Allowed: false,
+			Reason:  fmt.Sprintf("policy for %s can't be applied to %s", p.Resource, resource.Type),
+		}
+	}
+
+	names := make([]string, 0, len(p.Rules))
+	for _, rule := range p.Rules {
+		if rule.Match(subject, resource) {
+			return Decision{
+				Allowed: true,
+				Reason:  "matched rule " + rule.Name,
+			}
+		}
+		names = append(names, rule.Name)
+	}
+
+	return Decision{
+		Allowed: false,
+		Reason:  "no rule matched: " + strings.Join(names, ", "),
+	}
+}
+
+func (p Policy) String() string {
+	return fmt.Sprintf("%s:%s", p.Resource, p.Action)
+}
@@ -0,0 +1,134 @@
+package policies
+
+import "testing"
+
+func TestPolicyEvaluate(t *testing.T) {
+	policy := New(ResourceComment, ActionDelete, Owner(), ParentOwner(), Role("admin", "moderator"))
+
+	tests := []struct {
+		name     string
+		subject  Subject
+		resource Resource
+		allowed  bool
+		reason   string
+	}{
+		{
+			name:     "owner",
+			subject:  Subject{UserID: 1, Role: "user"},
+			resource: Resource{Type: ResourceComment, ID: 10, OwnerID: 1, ParentOwnerID: 2},
+			allowed:  true,
+			reason:   "matched rule owner",
+		},
+		{
+			name:     "parent owner",
+			subject:  Subject{UserID: 2, Role: "user"},
+			resource: Resource{Type: ResourceComment, ID: 10, OwnerID: 1, ParentOwnerID: 2},
+			allowed:  true,
+			reason:   "matched rule parent owner",
+		},
+		{
+			name:     "role",
+			subject:  Subject{UserID: 3, Role: "moderator"},
+			resource: Resource{Type: ResourceComment, ID: 10, OwnerID: 1, ParentOwnerID: 2},
+			allowed:  true,
+			reason:   "matched rule role(admin|moderator)",
+		},
+		{
+			name:     "owner matches before role",
+			subject:  Subject{UserID: 1, Role: "admin"},
+			resource: Resource{Type: ResourceComment, ID: 10, OwnerID: 1, ParentOwnerID: 2},
+			allowed:  true,
+			reason:   "matched rule owner",
+		},
+		{
+			name:     "other user",
+			subject:  Subject{UserID: 3, Role: "user"},
+			resource: Resource{Type: ResourceComment, ID: 10, OwnerID: 1, ParentOwnerID: 2},
+			allowed:  false,
+			reason:   "no rule matched: owner, parent owner, role(admin|moderator)",
+		},
+		{
+			name:     "unknown owners don't match a subject without id",
+			subject:  Subject{},
+			resource: Resource{Type: ResourceComment, ID: 10},
+			allowed:  false,
+			reason:   "no rule matched: owner, parent owner, role(admin|moderator)",
+		},
+		{
+			name:     "other resource type",
+			subject:  Subject{UserID: 1, Role: "admin"},
+			resource: Resource{Type: ResourcePhoto, ID: 10, OwnerID: 1},
+			allowed:  false,
+			reason:   "policy for comment can't be applied to photo",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			decision := policy.Evaluate(test.subject, test.resource)
+			if decision.Allowed != test.allowed || decision.Reason != test.reason {
+				t.Errorf("Evaluate() = %+v, want allowed %v reason %q", decision, test.allowed, test.reason)
+			}
+		})
+	}
+}
+
+func TestPolicyWithoutRules(t *testing.T) {
+	policy := New(ResourcePhoto, ActionHide)
+
+	decision := policy.Evaluate(Subject{UserID: 1, Role: "admin"}, Resource{Type: ResourcePhoto, ID: 1, OwnerID: 1})
+	if decision.Allowed {
+		t.Errorf("Evaluate() = %+v, a policy without rules must deny", decision)
+	}
+	if policy.String() != "photo:hide" {
+		t.Errorf("String() = %v", policy.String())
+	}
+}
+
+func TestDeleter(t *testing.T) {
+	policy := New(ResourceComment, ActionRestore, Deleter(), Role("admin"))
+
+	tests := []struct {
+		name     string
+		subject  Subject
+		resource Resource
+		allowed  bool
+	}{
+		{"owner deleted it", Subject{UserID: 1}, Resource{Type: ResourceComment, OwnerID: 1, ParentOwnerID: 2, DeletedBy: 1}, true},
+		{"parent owner deleted it", Subject{UserID: 2}, Resource{Type: ResourceComment, OwnerID: 1, ParentOwnerID: 2, DeletedBy: 2}, true},
+		{"owner, deleted by the parent owner", Subject{UserID: 1}, Resource{Type: ResourceComment, OwnerID: 1, ParentOwnerID: 2, DeletedBy: 2}, false},
+		{"owner, deleted by staff", Subject{UserID: 1}, Resource{Type: ResourceComment, OwnerID: 1, ParentOwnerID: 2, DeletedBy: 3}, false},
+		{"owner, deleter unknown", Subject{UserID: 1}, Resource{Type: ResourceComment, OwnerID: 1, ParentOwnerID: 2}, false},
+		{"deleter who isn't an owner anymore", Subject{UserID: 3}, Resource{Type: ResourceComment, OwnerID: 1, ParentOwnerID: 2, DeletedBy: 3}, false},
+		{"staff", Subject{UserID: 3, Role: "admin"}, Resource{Type: ResourceComment, OwnerID: 1, ParentOwnerID: 2, DeletedBy: 2}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if decision := policy.Evaluate(test.subject, test.resource); decision.Allowed != test.allowed {
+				t.Errorf("Evaluate() = %+v, want allowed %v", decision, test.allowed)
+			}
+		})
+	}
+}
+
+func TestRole(t *testing.T) {
+	tests := []struct {
+		roles []string
+		role  string
+		match bool
+	}{
+		{[]string{"admin"}, "admin", true},
+		{[]string{"admin", "moderator"}, "moderator", true},
+		{[]string{"admin"}, "user", false},
+		{[]string{"admin"}, "", false},
+		{nil, "admin", false},
+	}
+
+	for _, test := range tests {
+		rule := Role(test.roles...)
+		if got := rule.Match(Subject{UserID: 1, Role: test.role}, Resource{}); got != test.match {
+			t.Errorf("Role(%v).Match(%q) = %v, want %v", test.roles, test.role, got, test.match)
+		}
+	}
+}
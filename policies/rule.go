@@ -0,0 +1,62 @@
+package policies
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Rule grants access when Match returns true, Name is used in decision reasons
+type Rule struct {
+	Name  string
+	Match func(subject Subject, resource Resource) bool
+}
+
+// Owner matches when the subject owns the resource
+func Owner() Rule {
+	return Rule{
+		Name: "owner",
+		Match: func(subject Subject, resource Resource) bool {
+			return resource.OwnerID != 0 && resource.OwnerID == subject.UserID
+		},
+	}
+}
+
+// ParentOwner matches when the subject owns the parent of the resource,
+// e.g. the photo owner may delete comments on their photo
+func ParentOwner() Rule {
+	return Rule{
+		Name: "parent owner",
+		Match: func(subject Subject, resource Resource) bool {
+			return resource.ParentOwnerID != 0 && resource.ParentOwnerID == subject.UserID
+		},
+	}
+}
+
+// Deleter matches when the subject moved the resource to the trash and owns it or its parent,
+// e.g. authors can't restore comments the photo owner or a moderator removed
+func Deleter() Rule {
+	return Rule{
+		Name: "deleter",
+		Match: func(subject Subject, resource Resource) bool {
+			if resource.DeletedBy == 0 || resource.DeletedBy != subject.UserID {
+				return false
+			}
+			return resource.OwnerID == subject.UserID || resource.ParentOwnerID == subject.UserID
+		},
+	}
+}
+
+// Role matches when the subject has one of the given roles
+func Role(roles ...string) Rule {
+	return Rule{
+		Name: fmt.Sprintf("role(%s)", strings.Join(roles, "|")),
+		Match: func(subject Subject, resource Resource) bool {
+			for _, role := range roles {
+				if subject.Role == role {
+					return true
+				}
+			}
+			return false
+		},
+	}
+}
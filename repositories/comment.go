@@ -1,41 +1,67 @@
 package repositories
 
 import (
+	"time"
+
 	"github.com/alvinmdj/mygram-api/models"
 	"gorm.io/gorm"
 )
 
 type CommentRepoInterface interface {
-	FindAll(photoId int) (comments []models.Comment, err error)
-	FindById(photoId int, commentId int) (comment models.Comment, err error)
+	FindAll(photoId int, viewerId uint, includeHidden bool, page models.PageInput) (comments []models.Comment, nextCursor string, err error)
+	FindById(photoId int, commentId int, viewerId uint, includeHidden bool) (comment models.Comment, err error)
+	FindLatest(photoIds []uint, viewerId uint, limit int) (comments []models.Comment, err error)
 	Save(comment models.Comment) (models.Comment, error)
 	Update(comment models.Comment) (models.Comment, error)
-	Delete(comment models.Comment) (err error)
+	UpdateHiddenAt(comment models.Comment) (err error)
+	Delete(comment models.Comment, deletedBy uint) (err error)
+	FindTrashed(userId uint) (comments []models.Comment, err error)
+	Restore(comment models.Comment) (err error)
+	PurgeDeletedBefore(before time.Time) (purged int64, err error)
 }
 
 type CommentRepo struct {
 	db *gorm.DB
 }
 
+var commentSortOptions = sortOptions[models.Comment]{
+	defaultSort: "created_at",
+	idColumn:    "comments.id",
+	id:          func(comment models.Comment) uint { return comment.ID },
+	fields:      baseSortFields("comments", func(comment models.Comment) models.Base { return comment.Base }),
+}
+
 func NewCommentRepo(db *gorm.DB) CommentRepoInterface {
 	return &CommentRepo{
 		db: db,
 	}
 }
 
-func (co *CommentRepo) FindAll(photoId int) (comments []models.Comment, err error) {
-	err = co.db.Debug().
+// visibleTo hides hidden comments, except from their author or when includeHidden is set (moderators)
+func visibleTo(viewerId uint, includeHidden bool) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if includeHidden {
+			return db
+		}
+		return db.Where("comments.hidden_at IS NULL OR comments.user_id = ?", viewerId)
+	}
+}
+
+func (co *CommentRepo) FindAll(photoId int, viewerId uint, includeHidden bool, page models.PageInput) (comments []models.Comment, nextCursor string, err error) {
+	query := co.db.Debug().
 		Where("photo_id = ?", photoId).
+		Scopes(visibleTo(viewerId, includeHidden)).
 		Preload("User", func(db *gorm.DB) *gorm.DB {
 			return db.Select("id", "username", "email", "age", "created_at", "updated_at")
-		}).
-		Find(&comments).Error
+		})
+	comments, nextCursor, err = findPage(query, page, commentSortOptions)
 	return
 }
 
-func (co *CommentRepo) FindById(photoId int, commentId int) (comment models.Comment, err error) {
+func (co *CommentRepo) FindById(photoId int, commentId int, viewerId uint, includeHidden bool) (comment models.Comment, err error) {
 	err = co.db.Debug().
 		Where("photo_id = ?", photoId).
+		Scopes(visibleTo(viewerId, includeHidden)).
 		Preload("User", func(db *gorm.DB) *gorm.DB {
 			return db.Select("id", "username", "email", "age", "created_at", "updated_at")
 		}).
@@ -43,6 +69,29 @@ func (co *CommentRepo) FindById(photoId int, commentId int) (comment models.Comm
 	return
 }
 
+// FindLatest returns the latest limit comments visible to the viewer of each photo, oldest first
+func (co *CommentRepo) FindLatest(photoIds []uint, viewerId uint, limit int) (comments []models.Comment, err error) {
+	if len(photoIds) == 0 {
+		return
+	}
+
+	// number the comments of each photo, newest first
+	latest := co.db.Model(&models.Comment{}).
+		Select("comments.*, ROW_NUMBER() OVER (PARTITION BY comments.photo_id ORDER BY comments.created_at DESC, comments.id DESC) AS row_number").
+		Where("comments.photo_id IN ?", photoIds).
+		Scopes(visibleTo(viewerId, false))
+
+	err = co.db.Debug().Table("(?) AS comments", latest).
+		Where("comments.row_number <= ?", limit).
+		Preload("User", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "username", "email", "age", "created_at", "updated_at")
+		}).
+		Order("comments.created_at").
+		Order("comments.id").
+		Find(&comments).Error
+	return
+}
+
 func (co *CommentRepo) Save(comment models.Comment) (models.Comment, error) {
 	err := co.db.Debug().Create(&comment).Error
 	return comment, err
@@ -57,7 +106,45 @@ func (co *CommentRepo) Update(comment models.Comment) (models.Comment, error) {
 	return comment, err
 }
 
-func (co *CommentRepo) Delete(comment models.Comment) (err error) {
-	err = co.db.Debug().Delete(&comment).Error
+func (co *CommentRepo) UpdateHiddenAt(comment models.Comment) (err error) {
+	err = co.db.Debug().Model(&comment).
+		Where("id = ?", comment.ID).
+		UpdateColumn("hidden_at", comment.HiddenAt).Error
 	return
 }
+
+// Delete moves a comment to the trash (soft delete) and records who deleted it
+func (co *CommentRepo) Delete(comment models.Comment, deletedBy uint) (err error) {
+	err = co.db.Debug().Model(&comment).
+		Where("id = ?", comment.ID).
+		UpdateColumns(map[string]interface{}{"deleted_at": time.Now(), "deleted_by": deletedBy}).Error
+	return
+}
+
+// FindTrashed returns the comments of a user in the trash, the most recently deleted first
+func (co *CommentRepo) FindTrashed(userId uint) (comments []models.Comment, err error) {
+	err = co.db.Debug().Unscoped().
+		Where("user_id = ? AND deleted_at IS NOT NULL", userId).
+		Order("deleted_at DESC").
+		Find(&comments).Error
+	return
+}
+
+func (co *CommentRepo) Restore(comment models.Comment) (err error) {
+	result := co.db.Debug().Unscoped().Model(&comment).
+		Where("id = ? AND deleted_at IS NOT NULL", comment.ID).
+		UpdateColumns(map[string]interface{}{"deleted_at": nil, "deleted_by": nil})
+	err = result.Error
+	if err == nil && result.RowsAffected == 0 {
+		err = gorm.ErrRecordNotFound
+	}
+	return
+}
+
+// PurgeDeletedBefore deletes the comments moved to the trash before the given time for good
+func (co *CommentRepo) PurgeDeletedBefore(before time.Time) (purged int64, err error) {
+	result := co.db.Debug().Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Delete(&models.Comment{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,170 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/alvinmdj/mygram-api/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FollowRepoInterface interface {
+	Find(followerId uint, followingId uint) (follow models.Follow, err error)
+	FindFollowers(userId uint, status string, page models.PageInput) (follows []models.Follow, nextCursor string, err error)
+	FindFollowing(userId uint, page models.PageInput) (follows []models.Follow, nextCursor string, err error)
+	IsFollowing(followerId uint, followingId uint) (following bool, err error)
+	Save(follow models.Follow) (models.Follow, error)
+	Accept(followerId uint, followingId uint) (err error)
+	AcceptAll(followingId uint) (followerIds []uint, err error)
+	Delete(followerId uint, followingId uint) (deleted bool, err error)
+}
+
+type FollowRepo struct {
+	db *gorm.DB
+}
+
+var followSortOptions = sortOptions[models.Follow]{
+	defaultSort: "-created_at",
+	idColumn:    "follows.id",
+	id:          func(follow models.Follow) uint { return follow.ID },
+	fields:      baseSortFields("follows", func(follow models.Follow) models.Base { return follow.Base }),
+}
+
+func NewFollowRepo(db *gorm.DB) FollowRepoInterface {
+	return &FollowRepo{
+		db: db,
+	}
+}
+
+func (f *FollowRepo) Find(followerId uint, followingId uint) (follow models.Follow, err error) {
+	err = f.db.Debug().
+		Where("follower_id = ? AND following_id = ?", followerId, followingId).
+		Take(&follow).Error
+	return
+}
+
+// FindFollowers returns the follows of a user with the given status, with the followers
+func (f *FollowRepo) FindFollowers(userId uint, status string, page models.PageInput) (follows []models.Follow, nextCursor string, err error) {
+	query := f.db.Debug().
+		Where("following_id = ? AND status = ?", userId, status).
+		Preload("Follower", selectProfile)
+	follows, nextCursor, err = findPage(query, page, followSortOptions)
+	return
+}
+
+// FindFollowing returns the accepted follows of a user, with the followed users
+func (f *FollowRepo) FindFollowing(userId uint, page models.PageInput) (follows []models.Follow, nextCursor string, err error) {
+	query := f.db.Debug().
+		Where("follower_id = ? AND status = ?", userId, models.FollowStatusAccepted).
+		Preload("Following", selectProfile)
+	follows, nextCursor, err = findPage(query, page, followSortOptions)
+	return
+}
+
+// IsFollowing reports whether the follower follows the user, pending requests don't count
+func (f *FollowRepo) IsFollowing(followerId uint, followingId uint) (following bool, err error) {
+	var count int64
+	err = f.db.Debug().Model(&models.Follow{}).
+		Where("follower_id = ? AND following_id = ? AND status = ?", followerId, followingId, models.FollowStatusAccepted).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Save follows a user or requests to follow a private user, following twice has no effect and the existing
+// follow is returned. An accepted follow and the increment of the follow counts are done in one transaction
+func (f *FollowRepo) Save(follow models.Follow) (models.Follow, error) {
+	err := f.db.Debug().Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&follow)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return tx.Where("follower_id = ? AND following_id = ?", follow.FollowerID, follow.FollowingID).Take(&follow).Error
+		}
+		if follow.Status == models.FollowStatusAccepted {
+			return addFollowCounts(tx, []uint{follow.FollowerID}, follow.FollowingID, 1)
+		}
+		return nil
+	})
+	return follow, err
+}
+
+// Accept accepts a pending follow request
+func (f *FollowRepo) Accept(followerId uint, followingId uint) (err error) {
+	err = f.db.Debug().Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Follow{}).
+			Where("follower_id = ? AND following_id = ? AND status = ?", followerId, followingId, models.FollowStatusPending).
+			Updates(map[string]interface{}{"status": models.FollowStatusAccepted, "accepted_at": time.Now()})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return addFollowCounts(tx, []uint{followerId}, followingId, 1)
+	})
+	return
+}
+
+// AcceptAll accepts all pending follow requests to a user, e.g. when the account is made public
+// returns the followers whose requests were accepted
+func (f *FollowRepo) AcceptAll(followingId uint) (followerIds []uint, err error) {
+	err = f.db.Debug().Transaction(func(tx *gorm.DB) error {
+		// only the requests which are accepted by this statement are counted
+		err := tx.Raw(
+			"UPDATE follows SET status = ?, accepted_at = ?, updated_at = ? WHERE following_id = ? AND status = ? RETURNING follower_id",
+			models.FollowStatusAccepted, time.Now(), time.Now(), followingId, models.FollowStatusPending,
+		).Scan(&followerIds).Error
+		if err != nil {
+			return err
+		}
+
+		return addFollowCounts(tx, followerIds, followingId, 1)
+	})
+	return
+}
+
+// Delete unfollows a user or withdraws (declines) a follow request, the follow counts are only
+// decremented for an accepted follow. Unfollowing a user who isn't followed has no effect
+func (f *FollowRepo) Delete(followerId uint, followingId uint) (deleted bool, err error) {
+	err = f.db.Debug().Transaction(func(tx *gorm.DB) error {
+		var follows []models.Follow
+		err := tx.Clauses(clause.Returning{}).
+			Where("follower_id = ? AND following_id = ?", followerId, followingId).
+			Delete(&follows).Error
+		if err != nil || len(follows) == 0 {
+			return err
+		}
+
+		deleted = true
+		if follows[0].Status == models.FollowStatusAccepted {
+			return addFollowCounts(tx, []uint{followerId}, followingId, -1)
+		}
+		return nil
+	})
+	return
+}
+
+// addFollowCounts adds delta to the following count of the followers and delta per follower
+// to the follower count of the followed user, the counts are changed in the db so concurrent follows don't get lost
+func addFollowCounts(tx *gorm.DB, followerIds []uint, followingId uint, delta int) error {
+	if len(followerIds) == 0 {
+		return nil
+	}
+
+	err := tx.Model(&models.User{}).
+		Where("id IN ?", followerIds).
+		UpdateColumn("following_count", gorm.Expr("GREATEST(following_count + ?, 0)", delta)).Error
+	if err != nil {
+		return err
+	}
+
+	return tx.Model(&models.User{}).
+		Where("id = ?", followingId).
+		UpdateColumn("follower_count", gorm.Expr("GREATEST(follower_count + ?, 0)", delta*len(followerIds))).Error
+}
+
+// selectProfile only selects the public profile columns of a user
+func selectProfile(db *gorm.DB) *gorm.DB {
+	return db.Select("id", "username", "age", "created_at", "updated_at")
+}
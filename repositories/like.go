@@ -0,0 +1,114 @@
+package repositories
+
+import (
+	"github.com/alvinmdj/mygram-api/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LikeRepoInterface interface {
+	FindAll(photoId int, page models.PageInput) (likes []models.Like, nextCursor string, err error)
+	FindLikedPhotoIds(userId uint, photoIds []uint) (liked map[uint]bool, err error)
+	Save(userId uint, photoId uint) (likeCount int64, err error)
+	Delete(userId uint, photoId uint) (likeCount int64, err error)
+	RepairCounts() (repaired int64, err error)
+}
+
+type LikeRepo struct {
+	db *gorm.DB
+}
+
+var likeSortOptions = sortOptions[models.Like]{
+	defaultSort: "-created_at",
+	idColumn:    "likes.id",
+	id:          func(like models.Like) uint { return like.ID },
+	fields:      baseSortFields("likes", func(like models.Like) models.Base { return like.Base }),
+}
+
+func NewLikeRepo(db *gorm.DB) LikeRepoInterface {
+	return &LikeRepo{
+		db: db,
+	}
+}
+
+func (l *LikeRepo) FindAll(photoId int, page models.PageInput) (likes []models.Like, nextCursor string, err error) {
+	query := l.db.Debug().
+		Where("photo_id = ?", photoId).
+		Preload("User", selectProfile)
+	likes, nextCursor, err = findPage(query, page, likeSortOptions)
+	return
+}
+
+// FindLikedPhotoIds returns which of the photos are liked by the user
+func (l *LikeRepo) FindLikedPhotoIds(userId uint, photoIds []uint) (liked map[uint]bool, err error) {
+	liked = map[uint]bool{}
+	if len(photoIds) == 0 {
+		return
+	}
+
+	var ids []uint
+	err = l.db.Debug().Model(&models.Like{}).
+		Where("user_id = ? AND photo_id IN ?", userId, photoIds).
+		Pluck("photo_id", &ids).Error
+	for _, id := range ids {
+		liked[id] = true
+	}
+	return
+}
+
+// Save likes a photo, liking a photo twice has no effect. The like and the increment of the like count
+// of the photo are done in one transaction, the count is incremented in the db so concurrent likes don't get lost
+func (l *LikeRepo) Save(userId uint, photoId uint) (likeCount int64, err error) {
+	err = l.db.Debug().Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.Like{UserID: userId, PhotoID: photoId})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			if err := addLikeCount(tx, photoId, 1); err != nil {
+				return err
+			}
+		}
+		return findLikeCount(tx, photoId, &likeCount)
+	})
+	return
+}
+
+// Delete unlikes a photo, unliking a photo which isn't liked has no effect
+func (l *LikeRepo) Delete(userId uint, photoId uint) (likeCount int64, err error) {
+	err = l.db.Debug().Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("user_id = ? AND photo_id = ?", userId, photoId).Delete(&models.Like{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			if err := addLikeCount(tx, photoId, -1); err != nil {
+				return err
+			}
+		}
+		return findLikeCount(tx, photoId, &likeCount)
+	})
+	return
+}
+
+// RepairCounts recomputes the like counts of all photos (trashed ones included) from the likes,
+// returns how many photos had a wrong count
+func (l *LikeRepo) RepairCounts() (repaired int64, err error) {
+	result := l.db.Debug().Exec(`UPDATE photos SET like_count = counts.like_count
+		FROM (SELECT photos.id, COUNT(likes.id) AS like_count FROM photos LEFT JOIN likes ON likes.photo_id = photos.id GROUP BY photos.id) AS counts
+		WHERE photos.id = counts.id AND photos.like_count <> counts.like_count`)
+	return result.RowsAffected, result.Error
+}
+
+func addLikeCount(tx *gorm.DB, photoId uint, delta int) error {
+	return tx.Unscoped().Model(&models.Photo{}).
+		Where("id = ?", photoId).
+		UpdateColumn("like_count", gorm.Expr("GREATEST(like_count + ?, 0)", delta)).Error
+}
+
+func findLikeCount(tx *gorm.DB, photoId uint, likeCount *int64) error {
+	return tx.Unscoped().Model(&models.Photo{}).
+		Where("id = ?", photoId).
+		Select("like_count").
+		Scan(likeCount).Error
+}
@@ -0,0 +1,224 @@
+package repositories
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alvinmdj/mygram-api/models"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+type sortKind int
+
+const (
+	sortKindInt sortKind = iota
+	sortKindTime
+	sortKindFloat
+)
+
+// sortField is a whitelisted sort field, value returns the field value of an item
+// (int64, time.Time or float64 depending on kind) to build the next cursor
+type sortField[T any] struct {
+	column string
+	kind   sortKind
+	value  func(item T) interface{}
+}
+
+// sortOptions declares how a list can be sorted, items are always
+// sorted by id as well so the order (and the cursor) is unique
+type sortOptions[T any] struct {
+	defaultSort string
+	idColumn    string
+	id          func(item T) uint
+	fields      map[string]sortField[T]
+}
+
+// baseSortFields returns the id, created_at & updated_at sort fields of a table
+func baseSortFields[T any](table string, base func(item T) models.Base) map[string]sortField[T] {
+	return map[string]sortField[T]{
+		"id": {
+			column: table + ".id",
+			kind:   sortKindInt,
+			value:  func(item T) interface{} { return int64(base(item).ID) },
+		},
+		"created_at": {
+			column: table + ".created_at",
+			kind:   sortKindTime,
+			value:  func(item T) interface{} { return timeValue(base(item).CreatedAt) },
+		},
+		"updated_at": {
+			column: table + ".updated_at",
+			kind:   sortKindTime,
+			value:  func(item T) interface{} { return timeValue(base(item).UpdatedAt) },
+		},
+	}
+}
+
+func timeValue(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
+
+// cursor is the position after the last item of a page, it is opaque for clients
+type cursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    uint   `json:"i"`
+}
+
+// findPage runs the query with keyset pagination: ?sort= is checked against the whitelist,
+// ?cursor= continues after the last item of the previous page
+func findPage[T any](query *gorm.DB, page models.PageInput, options sortOptions[T]) (items []T, nextCursor string, err error) {
+	keyset, err := parseKeyset(page, options)
+	if err != nil {
+		return
+	}
+
+	query = keyset.after(query, keyset.field.column, options.idColumn)
+	err = keyset.order(query, keyset.field.column, options.idColumn).Find(&items).Error
+	if err != nil || len(items) <= keyset.limit {
+		return
+	}
+
+	items = items[:keyset.limit]
+	last := items[keyset.limit-1]
+	nextCursor, err = encodeCursor(cursor{
+		Sort:  keyset.sort,
+		Value: formatCursorValue(keyset.field.value(last)),
+		ID:    options.id(last),
+	})
+	return
+}
+
+// keyset is the sort order, size & position of a page, parsed from the page input
+type keyset[T any] struct {
+	sort  string
+	field sortField[T]
+	desc  bool
+	limit int
+	value interface{} // sort value & id of the last item of the previous page, nil on the first page
+	id    uint
+}
+
+func parseKeyset[T any](page models.PageInput, options sortOptions[T]) (keyset keyset[T], err error) {
+	keyset.limit = page.Limit
+	if keyset.limit <= 0 {
+		keyset.limit = defaultPageLimit
+	}
+	if keyset.limit > maxPageLimit {
+		keyset.limit = maxPageLimit
+	}
+
+	keyset.sort = page.Sort
+	if keyset.sort == "" {
+		keyset.sort = options.defaultSort
+	}
+	keyset.desc = strings.HasPrefix(keyset.sort, "-")
+	field, ok := options.fields[strings.TrimPrefix(keyset.sort, "-")]
+	if !ok {
+		err = fmt.Errorf("invalid sort field '%s'", keyset.sort)
+		return
+	}
+	keyset.field = field
+
+	if page.Cursor != "" {
+		var after cursor
+		if after, err = decodeCursor(page.Cursor); err != nil {
+			return
+		}
+		if after.Sort != keyset.sort {
+			err = errors.New("cursor doesn't match the sort order")
+			return
+		}
+		if keyset.value, err = parseCursorValue(field.kind, after.Value); err != nil {
+			return
+		}
+		keyset.id = after.ID
+	}
+	return
+}
+
+// after restricts the query to the items after the cursor, column & idColumn hold the sort value & id,
+// so queries over other tables (e.g. a subquery) can be paged alike
+func (k keyset[T]) after(query *gorm.DB, column string, idColumn string) *gorm.DB {
+	if k.value == nil {
+		return query
+	}
+	operator := ">"
+	if k.desc {
+		operator = "<"
+	}
+	return query.Where(fmt.Sprintf("(%s, %s) %s (?, ?)", column, idColumn, operator), k.value, k.id)
+}
+
+// order sorts the query and fetches one more item than the limit to know if there is a next page
+func (k keyset[T]) order(query *gorm.DB, column string, idColumn string) *gorm.DB {
+	direction := "ASC"
+	if k.desc {
+		direction = "DESC"
+	}
+	return query.
+		Order(column + " " + direction).
+		Order(idColumn + " " + direction).
+		Limit(k.limit + 1)
+}
+
+func encodeCursor(c cursor) (string, error) {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodeCursor(s string) (c cursor, err error) {
+	errInvalid := errors.New("invalid cursor")
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, errInvalid
+	}
+	if err = json.Unmarshal(b, &c); err != nil {
+		return c, errInvalid
+	}
+	return
+}
+
+func formatCursorValue(value interface{}) string {
+	switch v := value.(type) {
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case float64:
+		// shortest representation which parses back to the same value
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func parseCursorValue(kind sortKind, s string) (value interface{}, err error) {
+	switch kind {
+	case sortKindTime:
+		value, err = time.Parse(time.RFC3339Nano, s)
+	case sortKindFloat:
+		value, err = strconv.ParseFloat(s, 64)
+	default:
+		value, err = strconv.ParseInt(s, 10, 64)
+	}
+	if err != nil {
+		err = errors.New("invalid cursor")
+	}
+	return
+}
@@ -0,0 +1,174 @@
+package repositories
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alvinmdj/mygram-api/models"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	createdAt := time.Date(2023, 4, 1, 12, 30, 15, 123456789, time.FixedZone("WIB", 7*60*60))
+
+	tests := []struct {
+		name  string
+		kind  sortKind
+		value interface{}
+	}{
+		{"int", sortKindInt, int64(42)},
+		{"time keeps nanoseconds", sortKindTime, createdAt},
+		{"float", sortKindFloat, 0.1 + 0.2},
+		{"small float", sortKindFloat, 1.5e-12},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			encoded, err := encodeCursor(cursor{Sort: "-score", Value: formatCursorValue(test.value), ID: 7})
+			if err != nil {
+				t.Fatalf("encodeCursor() error = %v", err)
+			}
+			decoded, err := decodeCursor(encoded)
+			if err != nil {
+				t.Fatalf("decodeCursor() error = %v", err)
+			}
+			if decoded.Sort != "-score" || decoded.ID != 7 {
+				t.Errorf("decodeCursor() = %+v", decoded)
+			}
+
+			value, err := parseCursorValue(test.kind, decoded.Value)
+			if err != nil {
+				t.Fatalf("parseCursorValue() error = %v", err)
+			}
+			if want, ok := test.value.(time.Time); ok {
+				if !value.(time.Time).Equal(want) {
+					t.Errorf("parseCursorValue() = %v, want %v", value, want)
+				}
+			} else if value != test.value {
+				t.Errorf("parseCursorValue() = %v, want %v", value, test.value)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	for _, s := range []string{"not base64!", "bm90IGpzb24"} {
+		if _, err := decodeCursor(s); err == nil || err.Error() != "invalid cursor" {
+			t.Errorf("decodeCursor(%q) error = %v, want invalid cursor", s, err)
+		}
+	}
+}
+
+func TestParseCursorValueInvalid(t *testing.T) {
+	tests := []struct {
+		kind sortKind
+		s    string
+	}{
+		{sortKindInt, "1.5"},
+		{sortKindTime, "2023-04-01"},
+		{sortKindFloat, "abc"},
+	}
+
+	for _, test := range tests {
+		if _, err := parseCursorValue(test.kind, test.s); err == nil {
+			t.Errorf("parseCursorValue(%v, %q) error = nil", test.kind, test.s)
+		}
+	}
+}
+
+// dryRunDB builds the statements without a database, the built statements are returned by the function
+func dryRunDB(t *testing.T) (*gorm.DB, func() []string) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+
+	var statements []string
+	record := func(db *gorm.DB) {
+		statements = append(statements, db.Statement.SQL.String())
+	}
+	db.Callback().Query().After("gorm:query").Register("test:sql", record)
+	db.Callback().Create().After("gorm:create").Register("test:sql", record)
+	db.Callback().Update().After("gorm:update").Register("test:sql", record)
+	db.Callback().Delete().After("gorm:delete").Register("test:sql", record)
+	db.Callback().Raw().After("gorm:raw").Register("test:sql", record)
+	return db, func() []string { return statements }
+}
+
+func TestFindPage(t *testing.T) {
+	createdAt := time.Date(2023, 4, 1, 12, 0, 0, 0, time.UTC)
+	afterPhoto, _ := encodeCursor(cursor{Sort: "-created_at", Value: formatCursorValue(createdAt), ID: 7})
+	afterId, _ := encodeCursor(cursor{Sort: "id", Value: "7", ID: 7})
+
+	tests := []struct {
+		name string
+		page models.PageInput
+		want []string
+	}{
+		{
+			name: "default sort & limit",
+			page: models.PageInput{},
+			want: []string{`ORDER BY photos.created_at DESC,photos.id DESC LIMIT 21`},
+		},
+		{
+			name: "limit is capped",
+			page: models.PageInput{Limit: 1000, Sort: "like_count"},
+			want: []string{`ORDER BY photos.like_count ASC,photos.id ASC LIMIT 101`},
+		},
+		{
+			name: "descending cursor",
+			page: models.PageInput{Cursor: afterPhoto},
+			want: []string{`WHERE (photos.created_at, photos.id) < ($1, $2)`, `LIMIT 21`},
+		},
+		{
+			name: "ascending cursor",
+			page: models.PageInput{Limit: 5, Sort: "id", Cursor: afterId},
+			want: []string{`WHERE (photos.id, photos.id) > ($1, $2)`, `ORDER BY photos.id ASC,photos.id ASC LIMIT 6`},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, statements := dryRunDB(t)
+			if _, _, err := findPage(db.Model(&models.Photo{}), test.page, photoSortOptions); err != nil {
+				t.Fatalf("findPage() error = %v", err)
+			}
+			query := strings.Join(statements(), "; ")
+			for _, want := range test.want {
+				if !strings.Contains(query, want) {
+					t.Errorf("findPage() query = %v, want it to contain %v", query, want)
+				}
+			}
+		})
+	}
+}
+
+func TestFindPageInvalid(t *testing.T) {
+	afterId, _ := encodeCursor(cursor{Sort: "id", Value: "7", ID: 7})
+
+	tests := []struct {
+		name string
+		page models.PageInput
+		err  string
+	}{
+		{"unknown sort field", models.PageInput{Sort: "-title"}, "invalid sort field '-title'"},
+		{"invalid cursor", models.PageInput{Cursor: "abc"}, "invalid cursor"},
+		{"cursor of another sort order", models.PageInput{Sort: "-id", Cursor: afterId}, "cursor doesn't match the sort order"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, _ := dryRunDB(t)
+			_, _, err := findPage(db.Model(&models.Photo{}), test.page, photoSortOptions)
+			if err == nil || err.Error() != test.err {
+				t.Errorf("findPage() error = %v, want %v", err, test.err)
+			}
+		})
+	}
+}
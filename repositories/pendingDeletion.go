@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/alvinmdj/mygram-api/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PendingDeletionRepoInterface interface {
+	Save(storageKeys []string) (err error)
+	FindDue(now time.Time, limit int) (deletions []models.PendingDeletion, err error)
+	UpdateAttempt(deletion models.PendingDeletion) (err error)
+	Delete(deletion models.PendingDeletion) (err error)
+}
+
+type PendingDeletionRepo struct {
+	db *gorm.DB
+}
+
+func NewPendingDeletionRepo(db *gorm.DB) PendingDeletionRepoInterface {
+	return &PendingDeletionRepo{
+		db: db,
+	}
+}
+
+// Save queues files for deletion, files already queued keep their retry state
+func (p *PendingDeletionRepo) Save(storageKeys []string) (err error) {
+	if len(storageKeys) == 0 {
+		return
+	}
+
+	now := time.Now()
+	deletions := make([]models.PendingDeletion, 0, len(storageKeys))
+	for _, storageKey := range storageKeys {
+		deletions = append(deletions, models.PendingDeletion{StorageKey: storageKey, NextAttemptAt: now})
+	}
+	err = p.db.Debug().Clauses(clause.OnConflict{DoNothing: true}).Create(&deletions).Error
+	return
+}
+
+func (p *PendingDeletionRepo) FindDue(now time.Time, limit int) (deletions []models.PendingDeletion, err error) {
+	err = p.db.Debug().
+		Where("next_attempt_at <= ?", now).
+		Order("next_attempt_at").
+		Limit(limit).
+		Find(&deletions).Error
+	return
+}
+
+func (p *PendingDeletionRepo) UpdateAttempt(deletion models.PendingDeletion) (err error) {
+	err = p.db.Debug().Model(&deletion).
+		Where("storage_key = ?", deletion.StorageKey).
+		Select("attempts", "next_attempt_at", "last_error").
+		Updates(models.PendingDeletion{
+			Attempts:      deletion.Attempts,
+			NextAttemptAt: deletion.NextAttemptAt,
+			LastError:     deletion.LastError,
+		}).Error
+	return
+}
+
+func (p *PendingDeletionRepo) Delete(deletion models.PendingDeletion) (err error) {
+	err = p.db.Debug().Where("storage_key = ?", deletion.StorageKey).Delete(&models.PendingDeletion{}).Error
+	return
+}
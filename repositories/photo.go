@@ -1,39 +1,95 @@
 package repositories
 
 import (
+	"time"
+
 	"github.com/alvinmdj/mygram-api/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type PhotoRepoInterface interface {
-	FindAll() (photos []models.Photo, err error)
+	FindAll(page models.PageInput, viewerId uint, includePrivate bool) (photos []models.Photo, nextCursor string, err error)
 	FindById(id int) (photo models.Photo, err error)
+	FindVisibleById(id int, viewerId uint, includePrivate bool) (photo models.Photo, err error)
 	Save(photo models.Photo) (models.Photo, error)
-	Update(photo models.Photo) (models.Photo, error)
-	Delete(photo models.Photo) (err error)
+	Update(photo models.Photo, version models.PhotoVersion) (models.Photo, error)
+	UpdateCommentPolicy(photo models.Photo) (err error)
+	UpdateWithVariants(photo models.Photo, version models.PhotoVersion) (models.Photo, error)
+	FindVersions(photoId int) (versions []models.PhotoVersion, err error)
+	FindVersion(photoId int, version int) (photoVersion models.PhotoVersion, err error)
+	PruneVersions(photoId uint, keep int) (pruned []models.PhotoVersion, err error)
+	FindStorageRefs() (photos []models.Photo, err error)
+	UpdateFileSizes(photo models.Photo) (err error)
+	StorageKeyInUse(storageKey string) (inUse bool, err error)
+	Delete(photo models.Photo, deletedBy uint) (err error)
+	FindTrashed(userId uint) (photos []models.Photo, err error)
+	FindDeletedBefore(before time.Time, limit int) (photos []models.Photo, err error)
+	Restore(photo models.Photo) (err error)
+	Purge(photo models.Photo) (err error)
 }
 
 type PhotoRepo struct {
 	db *gorm.DB
 }
 
+var photoSortOptions = sortOptions[models.Photo]{
+	defaultSort: "-created_at",
+	idColumn:    "photos.id",
+	id:          func(photo models.Photo) uint { return photo.ID },
+	fields: func() map[string]sortField[models.Photo] {
+		fields := baseSortFields("photos", func(photo models.Photo) models.Base { return photo.Base })
+		fields["like_count"] = sortField[models.Photo]{
+			column: "photos.like_count",
+			kind:   sortKindInt,
+			value:  func(photo models.Photo) interface{} { return photo.LikeCount },
+		}
+		return fields
+	}(),
+}
+
 func NewPhotoRepo(db *gorm.DB) PhotoRepoInterface {
 	return &PhotoRepo{
 		db: db,
 	}
 }
 
-func (p *PhotoRepo) FindAll() (photos []models.Photo, err error) {
-	err = p.db.Debug().Preload("User", func(db *gorm.DB) *gorm.DB {
-		return db.Select("username", "id", "email", "age", "created_at", "updated_at")
-	}).Find(&photos).Error
+// PhotosVisibleTo hides the photos of private accounts, except from their owner, their accepted followers
+// or when includePrivate is set (moderators)
+func PhotosVisibleTo(viewerId uint, includePrivate bool) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if includePrivate {
+			return db
+		}
+		return db.Where(
+			"photos.user_id = ? OR photos.user_id IN (?) OR photos.user_id IN (?)",
+			viewerId,
+			db.Session(&gorm.Session{NewDB: true}).Model(&models.User{}).Select("id").Where("is_private = ?", false),
+			db.Session(&gorm.Session{NewDB: true}).Model(&models.Follow{}).Select("following_id").
+				Where("follower_id = ? AND status = ?", viewerId, models.FollowStatusAccepted),
+		)
+	}
+}
+
+func (p *PhotoRepo) FindAll(page models.PageInput, viewerId uint, includePrivate bool) (photos []models.Photo, nextCursor string, err error) {
+	query := p.db.Debug().Scopes(PhotosVisibleTo(viewerId, includePrivate)).Preload("User", func(db *gorm.DB) *gorm.DB {
+		return db.Select("username", "id", "email", "age", "role", "created_at", "updated_at")
+	}).Preload("Variants", orderVariants)
+	photos, nextCursor, err = findPage(query, page, photoSortOptions)
 	return
 }
 
 func (p *PhotoRepo) FindById(id int) (photo models.Photo, err error) {
 	err = p.db.Debug().Preload("User", func(db *gorm.DB) *gorm.DB {
-		return db.Select("username", "id", "email", "age", "created_at", "updated_at")
-	}).First(&photo, id).Error
+		return db.Select("username", "id", "email", "age", "role", "created_at", "updated_at")
+	}).Preload("Variants", orderVariants).First(&photo, id).Error
+	return
+}
+
+func (p *PhotoRepo) FindVisibleById(id int, viewerId uint, includePrivate bool) (photo models.Photo, err error) {
+	err = p.db.Debug().Scopes(PhotosVisibleTo(viewerId, includePrivate)).Preload("User", func(db *gorm.DB) *gorm.DB {
+		return db.Select("username", "id", "email", "age", "role", "created_at", "updated_at")
+	}).Preload("Variants", orderVariants).First(&photo, id).Error
 	return
 }
 
@@ -42,18 +98,251 @@ func (p *PhotoRepo) Save(photo models.Photo) (models.Photo, error) {
 	return photo, err
 }
 
-func (p *PhotoRepo) Update(photo models.Photo) (models.Photo, error) {
-	err := p.db.Debug().Model(&photo).
+// Update updates a photo and saves its previous state as a new version
+func (p *PhotoRepo) Update(photo models.Photo, version models.PhotoVersion) (models.Photo, error) {
+	err := p.db.Debug().Transaction(func(tx *gorm.DB) error {
+		if err := saveVersion(tx, photo.ID, version); err != nil {
+			return err
+		}
+		return updatePhoto(tx, photo)
+	})
+	return photo, err
+}
+
+// UpdateWithVariants updates a photo whose file was replaced, the photo, its variants and the version
+// of its previous state change together
+func (p *PhotoRepo) UpdateWithVariants(photo models.Photo, version models.PhotoVersion) (models.Photo, error) {
+	err := p.db.Debug().Transaction(func(tx *gorm.DB) error {
+		if err := saveVersion(tx, photo.ID, version); err != nil {
+			return err
+		}
+		if err := updatePhoto(tx, photo); err != nil {
+			return err
+		}
+		return replaceVariants(tx, photo)
+	})
+	return photo, err
+}
+
+// saveVersion saves a version of a photo with the next version number,
+// the photo row is locked so concurrent updates don't get the same number
+func saveVersion(tx *gorm.DB, photoId uint, version models.PhotoVersion) error {
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&models.Photo{}, photoId).Error
+	if err != nil {
+		return err
+	}
+
+	var latest int
+	err = tx.Model(&models.PhotoVersion{}).
+		Where("photo_id = ?", photoId).
+		Select("COALESCE(MAX(version), 0)").
+		Scan(&latest).Error
+	if err != nil {
+		return err
+	}
+
+	version.PhotoID = photoId
+	version.Version = latest + 1
+	return tx.Create(&version).Error
+}
+
+func updatePhoto(db *gorm.DB, photo models.Photo) error {
+	// select the columns so cleared metadata (zero values) is written as well
+	return db.Model(&photo).
 		Where("id = ?", photo.ID).
+		Select("title", "caption", "photo_url", "storage_key", "file_size", "taken_at", "orientation", "camera_model").
 		Updates(models.Photo{
-			Title:    photo.Title,
-			Caption:  photo.Caption,
-			PhotoURL: photo.PhotoURL,
+			Title:       photo.Title,
+			Caption:     photo.Caption,
+			PhotoURL:    photo.PhotoURL,
+			StorageKey:  photo.StorageKey,
+			FileSize:    photo.FileSize,
+			TakenAt:     photo.TakenAt,
+			Orientation: photo.Orientation,
+			CameraModel: photo.CameraModel,
 		}).Error
-	return photo, err
 }
 
-func (p *PhotoRepo) Delete(photo models.Photo) (err error) {
-	err = p.db.Debug().Delete(&photo).Error
+func (p *PhotoRepo) UpdateCommentPolicy(photo models.Photo) (err error) {
+	err = p.db.Debug().Model(&photo).
+		Where("id = ?", photo.ID).
+		UpdateColumn("comment_policy", photo.CommentPolicy).Error
+	return
+}
+
+// FindVersions returns the versions of a photo with their variants, the latest version first
+func (p *PhotoRepo) FindVersions(photoId int) (versions []models.PhotoVersion, err error) {
+	err = p.db.Debug().
+		Where("photo_id = ?", photoId).
+		Preload("Variants", orderVariants).
+		Order("version DESC").
+		Find(&versions).Error
+	return
+}
+
+func (p *PhotoRepo) FindVersion(photoId int, version int) (photoVersion models.PhotoVersion, err error) {
+	err = p.db.Debug().
+		Where("photo_id = ? AND version = ?", photoId, version).
+		Preload("Variants", orderVariants).
+		First(&photoVersion).Error
 	return
 }
+
+// PruneVersions deletes all but the latest keep versions of a photo and returns the deleted versions,
+// their variants are deleted by the foreign key
+func (p *PhotoRepo) PruneVersions(photoId uint, keep int) (pruned []models.PhotoVersion, err error) {
+	err = p.db.Debug().
+		Where("photo_id = ?", photoId).
+		Preload("Variants").
+		Order("version DESC").
+		Offset(keep).
+		Find(&pruned).Error
+	if err != nil || len(pruned) == 0 {
+		return
+	}
+
+	ids := make([]uint, 0, len(pruned))
+	for _, version := range pruned {
+		ids = append(ids, version.ID)
+	}
+	err = p.db.Debug().Delete(&models.PhotoVersion{}, ids).Error
+	return
+}
+
+// replaceVariants replaces the variants of a photo with photo.Variants
+func replaceVariants(tx *gorm.DB, photo models.Photo) error {
+	if err := tx.Where("photo_id = ?", photo.ID).Delete(&models.PhotoVariant{}).Error; err != nil {
+		return err
+	}
+	if len(photo.Variants) == 0 {
+		return nil
+	}
+	for i := range photo.Variants {
+		photo.Variants[i].PhotoID = photo.ID
+	}
+	return tx.Create(&photo.Variants).Error
+}
+
+// Delete moves a photo to the trash (soft delete) and records who deleted it, its files are kept until it is purged
+func (p *PhotoRepo) Delete(photo models.Photo, deletedBy uint) (err error) {
+	err = p.db.Debug().Model(&photo).
+		Where("id = ?", photo.ID).
+		UpdateColumns(map[string]interface{}{"deleted_at": time.Now(), "deleted_by": deletedBy}).Error
+	return
+}
+
+// FindTrashed returns the photos of a user in the trash, the most recently deleted first
+func (p *PhotoRepo) FindTrashed(userId uint) (photos []models.Photo, err error) {
+	err = p.db.Debug().Unscoped().
+		Where("user_id = ? AND deleted_at IS NOT NULL", userId).
+		Order("deleted_at DESC").
+		Find(&photos).Error
+	return
+}
+
+// FindDeletedBefore returns photos which were moved to the trash before the given time, with their variants & versions
+func (p *PhotoRepo) FindDeletedBefore(before time.Time, limit int) (photos []models.Photo, err error) {
+	err = p.db.Debug().Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Preload("Variants").
+		Preload("Versions.Variants").
+		Order("deleted_at").
+		Limit(limit).
+		Find(&photos).Error
+	return
+}
+
+func (p *PhotoRepo) Restore(photo models.Photo) (err error) {
+	result := p.db.Debug().Unscoped().Model(&photo).
+		Where("id = ? AND deleted_at IS NOT NULL", photo.ID).
+		UpdateColumns(map[string]interface{}{"deleted_at": nil, "deleted_by": nil})
+	err = result.Error
+	if err == nil && result.RowsAffected == 0 {
+		err = gorm.ErrRecordNotFound
+	}
+	return
+}
+
+// Purge deletes a photo for good, its variants & versions are deleted by the foreign key
+func (p *PhotoRepo) Purge(photo models.Photo) (err error) {
+	err = p.db.Debug().Unscoped().Delete(&photo).Error
+	return
+}
+
+// FindStorageRefs returns every photo (trashed ones included) with only the columns which refer to stored files
+// & their sizes, variants & versions included
+func (p *PhotoRepo) FindStorageRefs() (photos []models.Photo, err error) {
+	err = findStorageRefs(p.db.Debug(), &photos)
+	return
+}
+
+// findStorageRefs loads the photos of the query with the columns of FindStorageRefs
+func findStorageRefs(query *gorm.DB, photos *[]models.Photo) error {
+	return query.Unscoped().Select("id", "user_id", "photo_url", "storage_key", "file_size").
+		Preload("Variants", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "photo_id", "storage_key", "file_size")
+		}).
+		Preload("Versions", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "photo_id", "storage_key", "file_size")
+		}).
+		Preload("Versions.Variants", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "photo_version_id", "storage_key", "file_size")
+		}).
+		Find(photos).Error
+}
+
+// UpdateFileSizes stores the file sizes of a photo (trashed or not), its variants, its versions & their variants
+func (p *PhotoRepo) UpdateFileSizes(photo models.Photo) (err error) {
+	err = p.db.Debug().Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Model(&models.Photo{}).Where("id = ?", photo.ID).
+			UpdateColumn("file_size", photo.FileSize).Error
+		if err != nil {
+			return err
+		}
+		for _, variant := range photo.Variants {
+			err = tx.Model(&models.PhotoVariant{}).Where("id = ?", variant.ID).
+				UpdateColumn("file_size", variant.FileSize).Error
+			if err != nil {
+				return err
+			}
+		}
+		for _, version := range photo.Versions {
+			err = tx.Model(&models.PhotoVersion{}).Where("id = ?", version.ID).
+				UpdateColumn("file_size", version.FileSize).Error
+			if err != nil {
+				return err
+			}
+			for _, variant := range version.Variants {
+				err = tx.Model(&models.PhotoVersionVariant{}).Where("id = ?", variant.ID).
+					UpdateColumn("file_size", variant.FileSize).Error
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	return
+}
+
+// StorageKeyInUse reports whether a photo (trashed ones included), a photo version or one of their variants
+// refers to the stored file
+func (p *PhotoRepo) StorageKeyInUse(storageKey string) (inUse bool, err error) {
+	var count int64
+	err = p.db.Debug().Unscoped().Model(&models.Photo{}).Where("storage_key = ?", storageKey).Count(&count).Error
+	if err != nil || count > 0 {
+		return count > 0, err
+	}
+
+	for _, model := range []interface{}{&models.PhotoVariant{}, &models.PhotoVersion{}, &models.PhotoVersionVariant{}} {
+		err = p.db.Debug().Model(model).Where("storage_key = ?", storageKey).Count(&count).Error
+		if err != nil || count > 0 {
+			return count > 0, err
+		}
+	}
+	return false, nil
+}
+
+func orderVariants(db *gorm.DB) *gorm.DB {
+	return db.Order("size")
+}
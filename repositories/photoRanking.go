@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/alvinmdj/mygram-api/models"
+	"gorm.io/gorm"
+)
+
+type PhotoRankingRepoInterface interface {
+	FindEngagement(since time.Time) (engagements []models.PhotoEngagement, err error)
+	ReplaceAll(rankings []models.PhotoRanking) (err error)
+	FindExplore(page models.PageInput) (photos []models.Photo, nextCursor string, err error)
+}
+
+type PhotoRankingRepo struct {
+	db *gorm.DB
+}
+
+// explore is ranked by score, the cursor continues after the last photo
+var exploreSortOptions = sortOptions[models.Photo]{
+	defaultSort: "-score",
+	idColumn:    "photos.id",
+	id:          func(photo models.Photo) uint { return photo.ID },
+	fields: map[string]sortField[models.Photo]{
+		"score": {
+			column: "photo_rankings.score",
+			kind:   sortKindFloat,
+			value: func(photo models.Photo) interface{} {
+				if photo.Ranking == nil {
+					return float64(0)
+				}
+				return photo.Ranking.Score
+			},
+		},
+	},
+}
+
+func NewPhotoRankingRepo(db *gorm.DB) PhotoRankingRepoInterface {
+	return &PhotoRankingRepo{
+		db: db,
+	}
+}
+
+// publicAuthors only keeps the photos of users who aren't blocked and whose account isn't private
+func publicAuthors(db *gorm.DB) *gorm.DB {
+	return db.Joins("JOIN users ON users.id = photos.user_id").
+		Where("users.blocked_at IS NULL AND users.is_private = ?", false)
+}
+
+// FindEngagement returns the likes, comments & unique commenters of the photos of public users created since
+// the given time. only visible comments of other users who aren't blocked count, owners can't comment their
+// photos up the ranking
+func (p *PhotoRankingRepo) FindEngagement(since time.Time) (engagements []models.PhotoEngagement, err error) {
+	err = p.db.Model(&models.Photo{}).
+		Select(`photos.id AS photo_id, photos.created_at, photos.like_count AS likes,
+			COUNT(comments.id) AS comments,
+			COUNT(DISTINCT comments.user_id) AS commenters`).
+		Scopes(publicAuthors).
+		Joins(`LEFT JOIN comments ON comments.photo_id = photos.id
+			AND comments.user_id <> photos.user_id
+			AND comments.deleted_at IS NULL AND comments.hidden_at IS NULL
+			AND NOT EXISTS (SELECT 1 FROM users AS comment_authors WHERE comment_authors.id = comments.user_id AND comment_authors.blocked_at IS NOT NULL)`).
+		Where("photos.created_at >= ?", since).
+		Group("photos.id").
+		Order("photos.id").
+		Scan(&engagements).Error
+	return
+}
+
+// ReplaceAll replaces the rankings in one transaction, readers see either the old or the new rankings
+func (p *PhotoRankingRepo) ReplaceAll(rankings []models.PhotoRanking) (err error) {
+	err = p.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&models.PhotoRanking{}).Error; err != nil {
+			return err
+		}
+		if len(rankings) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(&rankings, 500).Error
+	})
+	return
+}
+
+// FindExplore returns the ranked photos, highest score first. the authors are checked again
+// as users may have been blocked or made their account private since the rankings were computed
+func (p *PhotoRankingRepo) FindExplore(page models.PageInput) (photos []models.Photo, nextCursor string, err error) {
+	query := p.db.
+		Joins("JOIN photo_rankings ON photo_rankings.photo_id = photos.id").
+		Scopes(publicAuthors).
+		Preload("User", func(db *gorm.DB) *gorm.DB {
+			return db.Select("username", "id", "email", "age", "role", "created_at", "updated_at")
+		}).
+		Preload("Variants", orderVariants).
+		Preload("Ranking")
+	photos, nextCursor, err = findPage(query, page, exploreSortOptions)
+	return
+}
@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/alvinmdj/mygram-api/models"
+	"gorm.io/gorm"
+)
+
+type RefreshTokenRepoInterface interface {
+	Save(refreshToken models.RefreshToken) (models.RefreshToken, error)
+	FindByHash(tokenHash string) (refreshToken models.RefreshToken, err error)
+	Revoke(refreshToken models.RefreshToken) (revoked bool, err error)
+	RevokeFamily(familyId string) (err error)
+	RevokeAllByUserId(userId uint) (err error)
+}
+
+type RefreshTokenRepo struct {
+	db *gorm.DB
+}
+
+func NewRefreshTokenRepo(db *gorm.DB) RefreshTokenRepoInterface {
+	return &RefreshTokenRepo{
+		db: db,
+	}
+}
+
+func (r *RefreshTokenRepo) Save(refreshToken models.RefreshToken) (models.RefreshToken, error) {
+	err := r.db.Debug().Create(&refreshToken).Error
+	return refreshToken, err
+}
+
+func (r *RefreshTokenRepo) FindByHash(tokenHash string) (refreshToken models.RefreshToken, err error) {
+	err = r.db.Debug().Where("token_hash = ?", tokenHash).Take(&refreshToken).Error
+	return
+}
+
+// Revoke marks a token as used, revoked is false when another request already revoked it
+func (r *RefreshTokenRepo) Revoke(refreshToken models.RefreshToken) (revoked bool, err error) {
+	result := r.db.Debug().Model(&models.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", refreshToken.ID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *RefreshTokenRepo) RevokeFamily(familyId string) (err error) {
+	err = r.db.Debug().Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyId).
+		Update("revoked_at", time.Now()).Error
+	return
+}
+
+func (r *RefreshTokenRepo) RevokeAllByUserId(userId uint) (err error) {
+	err = r.db.Debug().Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userId).
+		Update("revoked_at", time.Now()).Error
+	return
+}
@@ -6,7 +6,7 @@ import (
 )
 
 type SocialMediaRepoInterface interface {
-	FindAll() (socialMedias []models.SocialMedia, err error)
+	FindAll(page models.PageInput) (socialMedias []models.SocialMedia, nextCursor string, err error)
 	FindById(id int) (socialMedia models.SocialMedia, err error)
 	Save(socialMedia models.SocialMedia) (models.SocialMedia, error)
 	Update(socialMedia models.SocialMedia) (models.SocialMedia, error)
@@ -17,16 +17,24 @@ type SocialMediaRepo struct {
 	db *gorm.DB
 }
 
+var socialMediaSortOptions = sortOptions[models.SocialMedia]{
+	defaultSort: "-created_at",
+	idColumn:    "social_media.id",
+	id:          func(socialMedia models.SocialMedia) uint { return socialMedia.ID },
+	fields:      baseSortFields("social_media", func(socialMedia models.SocialMedia) models.Base { return socialMedia.Base }),
+}
+
 func NewSocialMediaRepo(db *gorm.DB) SocialMediaRepoInterface {
 	return &SocialMediaRepo{
 		db: db,
 	}
 }
 
-func (s *SocialMediaRepo) FindAll() (socialMedias []models.SocialMedia, err error) {
-	err = s.db.Debug().Preload("User", func(db *gorm.DB) *gorm.DB {
+func (s *SocialMediaRepo) FindAll(page models.PageInput) (socialMedias []models.SocialMedia, nextCursor string, err error) {
+	query := s.db.Debug().Preload("User", func(db *gorm.DB) *gorm.DB {
 		return db.Select("username", "id", "email", "age", "created_at", "updated_at")
-	}).Find(&socialMedias).Error
+	})
+	socialMedias, nextCursor, err = findPage(query, page, socialMediaSortOptions)
 	return
 }
 
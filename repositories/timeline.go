@@ -0,0 +1,123 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/alvinmdj/mygram-api/models"
+	"gorm.io/gorm"
+)
+
+type TimelineRepoInterface interface {
+	FindFeed(userId uint, page models.PageInput) (photos []models.Photo, nextCursor string, err error)
+	FanOut(photo models.Photo, fanOutThreshold int64) (entries int64, err error)
+	Backfill(userId uint, authorId uint, limit int) (err error)
+	RemoveAuthor(userId uint, authorId uint) (err error)
+}
+
+type TimelineRepo struct {
+	db *gorm.DB
+}
+
+// the feed is always newest first, the cursor continues after the last photo
+var feedSortOptions = sortOptions[models.Photo]{
+	defaultSort: "-created_at",
+	idColumn:    "photos.id",
+	id:          func(photo models.Photo) uint { return photo.ID },
+	fields: map[string]sortField[models.Photo]{
+		"created_at": baseSortFields("photos", func(photo models.Photo) models.Base { return photo.Base })["created_at"],
+	},
+}
+
+func NewTimelineRepo(db *gorm.DB) TimelineRepoInterface {
+	return &TimelineRepo{
+		db: db,
+	}
+}
+
+// FindFeed returns the photos in the timeline of a user and the photos of the followed users which weren't
+// fanned out, as their author had more than the fan-out threshold followers. a photo in both is only returned once,
+// trashed photos & photos of blocked users are left out
+func (t *TimelineRepo) FindFeed(userId uint, page models.PageInput) (photos []models.Photo, nextCursor string, err error) {
+	keyset, err := parseKeyset(page, feedSortOptions)
+	if err != nil {
+		return
+	}
+
+	// both parts are paged on their own, so only a page of each is read (the entries by the user_id & created_at index),
+	// timeline entries are created at the time of their photo
+	timeline := t.db.Model(&models.TimelineEntry{}).
+		Select("timeline_entries.photo_id, timeline_entries.created_at").
+		Joins("JOIN photos ON photos.id = timeline_entries.photo_id AND photos.deleted_at IS NULL").
+		Joins("JOIN users ON users.id = timeline_entries.author_id AND users.blocked_at IS NULL").
+		Where("timeline_entries.user_id = ?", userId)
+	timeline = keyset.after(timeline, "timeline_entries.created_at", "timeline_entries.photo_id")
+	timeline = keyset.order(timeline, "timeline_entries.created_at", "timeline_entries.photo_id")
+
+	// whether a photo was fanned out is recorded per photo, the photos of authors who were above the threshold
+	// when they posted them are still read here after the author dropped below it (and vice versa)
+	followed := t.db.Model(&models.Follow{}).
+		Select("following_id").
+		Where("follower_id = ? AND status = ?", userId, models.FollowStatusAccepted)
+	notFannedOut := t.db.Model(&models.Photo{}).
+		Select("photos.id AS photo_id, photos.created_at").
+		Joins("JOIN users ON users.id = photos.user_id AND users.blocked_at IS NULL").
+		Where("photos.fanned_out = ? AND photos.user_id IN (?)", false, followed)
+	notFannedOut = keyset.after(notFannedOut, "photos.created_at", "photos.id")
+	notFannedOut = keyset.order(notFannedOut, "photos.created_at", "photos.id")
+
+	query := t.db.Debug().
+		Joins("JOIN ((?) UNION (?)) AS feed ON feed.photo_id = photos.id", timeline, notFannedOut).
+		Preload("User", func(db *gorm.DB) *gorm.DB {
+			return db.Select("username", "id", "email", "age", "role", "created_at", "updated_at")
+		}).
+		Preload("Variants", orderVariants)
+	photos, nextCursor, err = findPage(query, page, feedSortOptions)
+	return
+}
+
+// FanOut writes a photo into the timelines of the followers of its author and marks the photo as fanned out
+// in one transaction, nothing is written when the author has more than fanOutThreshold followers
+func (t *TimelineRepo) FanOut(photo models.Photo, fanOutThreshold int64) (entries int64, err error) {
+	err = t.db.Debug().Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Photo{}).
+			Where("id = ? AND (SELECT follower_count FROM users WHERE users.id = ?) <= ?", photo.ID, photo.UserID, fanOutThreshold).
+			UpdateColumn("fanned_out", true)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		result = tx.Exec(`INSERT INTO timeline_entries (user_id, photo_id, author_id, created_at, updated_at)
+			SELECT follows.follower_id, photos.id, photos.user_id, photos.created_at, ?
+			FROM follows
+			JOIN photos ON photos.id = ?
+			WHERE follows.following_id = ? AND follows.status = ?
+			ON CONFLICT DO NOTHING`,
+			time.Now(), photo.ID, photo.UserID, models.FollowStatusAccepted,
+		)
+		entries = result.RowsAffected
+		return result.Error
+	})
+	return
+}
+
+// Backfill writes the latest photos of an author into the timeline of a user who just followed the author
+func (t *TimelineRepo) Backfill(userId uint, authorId uint, limit int) (err error) {
+	err = t.db.Debug().Exec(`INSERT INTO timeline_entries (user_id, photo_id, author_id, created_at, updated_at)
+		SELECT ?, photos.id, photos.user_id, photos.created_at, ?
+		FROM photos
+		WHERE photos.user_id = ? AND photos.deleted_at IS NULL
+		ORDER BY photos.created_at DESC
+		LIMIT ?
+		ON CONFLICT DO NOTHING`,
+		userId, time.Now(), authorId, limit,
+	).Error
+	return
+}
+
+// RemoveAuthor removes the photos of an author from the timeline of a user who unfollowed the author
+func (t *TimelineRepo) RemoveAuthor(userId uint, authorId uint) (err error) {
+	err = t.db.Debug().
+		Where("user_id = ? AND author_id = ?", userId, authorId).
+		Delete(&models.TimelineEntry{}).Error
+	return
+}
@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alvinmdj/mygram-api/models"
+)
+
+func TestFindFeed(t *testing.T) {
+	after, _ := encodeCursor(cursor{Sort: "-created_at", Value: formatCursorValue(time.Date(2023, 4, 1, 12, 0, 0, 0, time.UTC)), ID: 7})
+
+	tests := []struct {
+		name string
+		page models.PageInput
+		want []string
+	}{
+		{
+			name: "first page",
+			page: models.PageInput{Limit: 10},
+			want: []string{
+				// the timeline entries of the user, without trashed photos & blocked authors, a page only
+				`SELECT timeline_entries.photo_id, timeline_entries.created_at FROM "timeline_entries" ` +
+					`JOIN photos ON photos.id = timeline_entries.photo_id AND photos.deleted_at IS NULL ` +
+					`JOIN users ON users.id = timeline_entries.author_id AND users.blocked_at IS NULL ` +
+					`WHERE timeline_entries.user_id = $1 ORDER BY timeline_entries.created_at DESC,timeline_entries.photo_id DESC LIMIT 11`,
+				// the photos of followed users which weren't fanned out, a page only
+				`WHERE (photos.fanned_out = $2 AND photos.user_id IN (SELECT "following_id" FROM "follows" WHERE follower_id = $3 AND status = $4)) ` +
+					`AND "photos"."deleted_at" IS NULL ORDER BY photos.created_at DESC,photos.id DESC LIMIT 11`,
+				`JOIN users ON users.id = photos.user_id AND users.blocked_at IS NULL`,
+				`) UNION (`,
+				`ORDER BY photos.created_at DESC,photos.id DESC LIMIT 11`,
+			},
+		},
+		{
+			name: "next page",
+			page: models.PageInput{Limit: 10, Cursor: after},
+			want: []string{
+				`AND (timeline_entries.created_at, timeline_entries.photo_id) < ($2, $3)`,
+				`AND (photos.created_at, photos.id) < ($7, $8)`,
+				`WHERE (photos.created_at, photos.id) < ($9, $10)`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, statements := dryRunDB(t)
+			if _, _, err := NewTimelineRepo(db).FindFeed(1, test.page); err != nil {
+				t.Fatalf("FindFeed() error = %v", err)
+			}
+			query := strings.Join(statements(), "; ")
+			for _, want := range test.want {
+				if !strings.Contains(query, want) {
+					t.Errorf("FindFeed() query = %v, want it to contain %v", query, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package repositories
+
+import (
+	"sync"
+	"time"
+
+	"github.com/alvinmdj/mygram-api/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TokenRevocationRepoInterface stores revoked access tokens,
+// it is checked by the authentication middleware on every request.
+type TokenRevocationRepoInterface interface {
+	RevokeToken(jti string, expiresAt time.Time) (err error)
+	RevokeUserTokens(userId uint, before time.Time) (err error)
+	IsRevoked(jti string, userId uint, issuedAt time.Time) (revoked bool, err error)
+}
+
+// NewTokenRevocationRepo returns the revocation store for the given driver:
+// "memory" for single-node deployments, anything else uses the database
+// so that every instance shares the same revocation list.
+func NewTokenRevocationRepo(db *gorm.DB, driver string) TokenRevocationRepoInterface {
+	if driver == "memory" {
+		return NewMemoryTokenRevocationRepo()
+	}
+	return NewDbTokenRevocationRepo(db)
+}
+
+type DbTokenRevocationRepo struct {
+	db *gorm.DB
+}
+
+func NewDbTokenRevocationRepo(db *gorm.DB) TokenRevocationRepoInterface {
+	return &DbTokenRevocationRepo{
+		db: db,
+	}
+}
+
+func (t *DbTokenRevocationRepo) RevokeToken(jti string, expiresAt time.Time) (err error) {
+	err = t.db.Debug().Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.RevokedToken{JTI: jti, ExpiresAt: expiresAt}).Error
+	if err != nil {
+		return
+	}
+
+	// expired tokens are rejected anyway, no need to keep them in the list
+	err = t.db.Debug().Where("expires_at < ?", time.Now()).Delete(&models.RevokedToken{}).Error
+	return
+}
+
+func (t *DbTokenRevocationRepo) RevokeUserTokens(userId uint, before time.Time) (err error) {
+	err = t.db.Debug().Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"revoked_before"}),
+	}).Create(&models.UserTokenRevocation{UserID: userId, RevokedBefore: before}).Error
+	return
+}
+
+func (t *DbTokenRevocationRepo) IsRevoked(jti string, userId uint, issuedAt time.Time) (revoked bool, err error) {
+	var count int64
+	err = t.db.Debug().Model(&models.RevokedToken{}).Where("jti = ?", jti).Count(&count).Error
+	if err != nil || count > 0 {
+		return count > 0, err
+	}
+
+	err = t.db.Debug().Model(&models.UserTokenRevocation{}).
+		Where("user_id = ? AND revoked_before >= ?", userId, issuedAt).
+		Count(&count).Error
+	return count > 0, err
+}
+
+type MemoryTokenRevocationRepo struct {
+	mu            sync.RWMutex
+	tokens        map[string]time.Time
+	revokedBefore map[uint]time.Time
+}
+
+func NewMemoryTokenRevocationRepo() TokenRevocationRepoInterface {
+	return &MemoryTokenRevocationRepo{
+		tokens:        map[string]time.Time{},
+		revokedBefore: map[uint]time.Time{},
+	}
+}
+
+func (t *MemoryTokenRevocationRepo) RevokeToken(jti string, expiresAt time.Time) (err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	for revokedJti, exp := range t.tokens {
+		if exp.Before(now) {
+			delete(t.tokens, revokedJti)
+		}
+	}
+	t.tokens[jti] = expiresAt
+	return
+}
+
+func (t *MemoryTokenRevocationRepo) RevokeUserTokens(userId uint, before time.Time) (err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.revokedBefore[userId] = before
+	return
+}
+
+func (t *MemoryTokenRevocationRepo) IsRevoked(jti string, userId uint, issuedAt time.Time) (revoked bool, err error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if _, ok := t.tokens[jti]; ok {
+		return true, nil
+	}
+	before, ok := t.revokedBefore[userId]
+	return ok && !issuedAt.After(before), nil
+}
@@ -0,0 +1,118 @@
+package repositories
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMemoryTokenRevocationRepo(t *testing.T) {
+	now := time.Now()
+	repo := NewMemoryTokenRevocationRepo()
+
+	if revoked, _ := repo.IsRevoked("jti-1", 1, now); revoked {
+		t.Error("IsRevoked() = true before anything was revoked")
+	}
+
+	// revoking a jti only revokes that token
+	if err := repo.RevokeToken("jti-1", now.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if revoked, _ := repo.IsRevoked("jti-1", 1, now); !revoked {
+		t.Error("IsRevoked() of a revoked jti = false")
+	}
+	if revoked, _ := repo.IsRevoked("jti-2", 1, now); revoked {
+		t.Error("IsRevoked() of another jti = true")
+	}
+
+	// revoking the tokens of a user revokes the ones issued up to then
+	before := now.Truncate(time.Second)
+	if err := repo.RevokeUserTokens(1, before); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		userId   uint
+		issuedAt time.Time
+		revoked  bool
+	}{
+		{"issued before", 1, before.Add(-time.Minute), true},
+		{"issued in the same second", 1, before, true},
+		{"issued after", 1, before.Add(time.Second), false},
+		{"other user", 2, before.Add(-time.Minute), false},
+	}
+	for _, test := range tests {
+		if revoked, _ := repo.IsRevoked("jti-3", test.userId, test.issuedAt); revoked != test.revoked {
+			t.Errorf("%v: IsRevoked() = %v, want %v", test.name, revoked, test.revoked)
+		}
+	}
+}
+
+func TestMemoryTokenRevocationRepoCleanup(t *testing.T) {
+	repo := NewMemoryTokenRevocationRepo().(*MemoryTokenRevocationRepo)
+
+	repo.RevokeToken("expired", time.Now().Add(-time.Second))
+	repo.RevokeToken("valid", time.Now().Add(time.Hour))
+
+	// expired tokens are dropped when the next token is revoked
+	if _, ok := repo.tokens["expired"]; ok {
+		t.Error("RevokeToken() kept an expired token")
+	}
+	if _, ok := repo.tokens["valid"]; !ok {
+		t.Error("RevokeToken() dropped a token which isn't expired")
+	}
+}
+
+func TestDbTokenRevocationRepo(t *testing.T) {
+	tests := []struct {
+		name   string
+		revoke func(repo TokenRevocationRepoInterface) error
+		want   []string
+	}{
+		{
+			name: "revoke a jti & clean up expired tokens",
+			revoke: func(repo TokenRevocationRepoInterface) error {
+				return repo.RevokeToken("jti-1", time.Now().Add(time.Hour))
+			},
+			want: []string{
+				`INSERT INTO "revoked_tokens"`,
+				`ON CONFLICT DO NOTHING`,
+				`DELETE FROM "revoked_tokens" WHERE expires_at < $1`,
+			},
+		},
+		{
+			name:   "revoke the tokens of a user",
+			revoke: func(repo TokenRevocationRepoInterface) error { return repo.RevokeUserTokens(1, time.Now()) },
+			want: []string{
+				`INSERT INTO "user_token_revocations"`,
+				`ON CONFLICT ("user_id") DO UPDATE SET "revoked_before"="excluded"."revoked_before"`,
+			},
+		},
+		{
+			name: "check a token",
+			revoke: func(repo TokenRevocationRepoInterface) error {
+				_, err := repo.IsRevoked("jti-1", 1, time.Now())
+				return err
+			},
+			want: []string{
+				`FROM "revoked_tokens" WHERE jti = $1`,
+				`FROM "user_token_revocations" WHERE user_id = $1 AND revoked_before >= $2`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, statements := dryRunDB(t)
+			if err := test.revoke(NewTokenRevocationRepo(db, "db")); err != nil {
+				t.Fatal(err)
+			}
+			sql := strings.Join(statements(), "; ")
+			for _, want := range test.want {
+				if !strings.Contains(sql, want) {
+					t.Errorf("statements = %v, want them to contain %v", sql, want)
+				}
+			}
+		})
+	}
+}
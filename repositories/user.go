@@ -1,6 +1,8 @@
 package repositories
 
 import (
+	"time"
+
 	"github.com/alvinmdj/mygram-api/models"
 	"gorm.io/gorm"
 )
@@ -8,6 +10,19 @@ import (
 type UserRepoInterface interface {
 	Save(user models.User) (models.User, error)
 	FindByEmail(user models.User) (models.User, error)
+	FindById(id uint) (user models.User, err error)
+	FindByUsername(username string) (user models.User, err error)
+	Update(user models.User) (models.User, error)
+	UpdatePassword(user models.User) (err error)
+	MarkVerified(user models.User) (err error)
+	FindAll() (users []models.User, err error)
+	UpdateRole(user models.User) (err error)
+	UpdateBlockedAt(user models.User) (err error)
+	UpdateStorageQuota(user models.User) (err error)
+	UpdatePrivacy(user models.User) (err error)
+	AddStorageUsed(userId uint, delta int64, defaultQuota int64) (ok bool, err error)
+	SetStorageUsed(used map[uint]int64) (err error)
+	Delete(user models.User) (photos []models.Photo, err error)
 }
 
 type UserRepo struct {
@@ -29,3 +44,165 @@ func (u *UserRepo) FindByEmail(user models.User) (models.User, error) {
 	err := u.db.Debug().Where("email = ?", user.Email).Take(&user).Error
 	return user, err
 }
+
+func (u *UserRepo) FindById(id uint) (user models.User, err error) {
+	err = u.db.Debug().First(&user, id).Error
+	return
+}
+
+func (u *UserRepo) FindByUsername(username string) (user models.User, err error) {
+	err = u.db.Debug().Where("username = ?", username).Take(&user).Error
+	return
+}
+
+// Update stores the profile of a user, a changed email is no longer verified
+func (u *UserRepo) Update(user models.User) (models.User, error) {
+	err := u.db.Debug().Model(&user).
+		Where("id = ?", user.ID).
+		Updates(map[string]interface{}{
+			"username": user.Username,
+			"email":    user.Email,
+			"age":      user.Age,
+			// compared against the email before the update
+			"verified_at": gorm.Expr("CASE WHEN email = ? THEN verified_at END", user.Email),
+		}).Error
+	if err != nil {
+		return user, err
+	}
+
+	// reload to return the complete user data (e.g. created_at)
+	err = u.db.Debug().First(&user, user.ID).Error
+	return user, err
+}
+
+// UpdatePassword stores an already hashed password, hooks are skipped on purpose
+func (u *UserRepo) UpdatePassword(user models.User) (err error) {
+	err = u.db.Debug().Model(&user).
+		Where("id = ?", user.ID).
+		UpdateColumn("password", user.Password).Error
+	return
+}
+
+func (u *UserRepo) MarkVerified(user models.User) (err error) {
+	err = u.db.Debug().Model(&user).
+		Where("id = ? AND verified_at IS NULL", user.ID).
+		UpdateColumn("verified_at", time.Now()).Error
+	return
+}
+
+func (u *UserRepo) FindAll() (users []models.User, err error) {
+	err = u.db.Debug().Order("id").Find(&users).Error
+	return
+}
+
+func (u *UserRepo) UpdateRole(user models.User) (err error) {
+	err = u.db.Debug().Model(&user).
+		Where("id = ?", user.ID).
+		UpdateColumn("role", user.Role).Error
+	return
+}
+
+func (u *UserRepo) UpdateBlockedAt(user models.User) (err error) {
+	err = u.db.Debug().Model(&user).
+		Where("id = ?", user.ID).
+		UpdateColumn("blocked_at", user.BlockedAt).Error
+	return
+}
+
+func (u *UserRepo) UpdateStorageQuota(user models.User) (err error) {
+	err = u.db.Debug().Model(&user).
+		Where("id = ?", user.ID).
+		UpdateColumn("storage_quota", user.StorageQuota).Error
+	return
+}
+
+func (u *UserRepo) UpdatePrivacy(user models.User) (err error) {
+	err = u.db.Debug().Model(&user).
+		Where("id = ?", user.ID).
+		UpdateColumn("is_private", user.IsPrivate).Error
+	return
+}
+
+// AddStorageUsed adds delta bytes to the storage used by a user in a single statement, an increase
+// is only applied (ok) while the total stays within the quota, the user's own or defaultQuota
+func (u *UserRepo) AddStorageUsed(userId uint, delta int64, defaultQuota int64) (ok bool, err error) {
+	query := u.db.Debug().Model(&models.User{}).Where("id = ?", userId)
+	if delta > 0 {
+		query = query.Where("storage_used + ? <= COALESCE(storage_quota, ?)", delta, defaultQuota)
+	}
+	result := query.UpdateColumn("storage_used", gorm.Expr("GREATEST(storage_used + ?, 0)", delta))
+	return result.RowsAffected > 0, result.Error
+}
+
+// SetStorageUsed overwrites the storage used by every user, users missing in used don't use any storage
+func (u *UserRepo) SetStorageUsed(used map[uint]int64) (err error) {
+	err = u.db.Debug().Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.User{}).Where("storage_used <> 0").UpdateColumn("storage_used", 0).Error
+		if err != nil {
+			return err
+		}
+		for userId, storageUsed := range used {
+			err = tx.Model(&models.User{}).Where("id = ?", userId).UpdateColumn("storage_used", storageUsed).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return
+}
+
+// Delete deletes a user with their photos (trashed ones included), comments & social medias in one transaction,
+// the comments of other users on the photos are deleted as well and the like counts of the photos the user liked
+// & the follow counts of the users they followed or were followed by are decremented. the deleted photos are
+// returned with the columns of PhotoRepo.FindStorageRefs so their files can be deleted
+func (u *UserRepo) Delete(user models.User) (photos []models.Photo, err error) {
+	err = u.db.Debug().Transaction(func(tx *gorm.DB) error {
+		if err := findStorageRefs(tx.Where("user_id = ?", user.ID), &photos); err != nil {
+			return err
+		}
+
+		// the likes of the user are deleted by their foreign key, the like counts of the liked photos of other users
+		// are decremented like unliking does
+		likedPhotos := tx.Model(&models.Like{}).Select("photo_id").Where("user_id = ?", user.ID)
+		err := tx.Unscoped().Model(&models.Photo{}).
+			Where("id IN (?) AND user_id <> ?", likedPhotos, user.ID).
+			UpdateColumn("like_count", gorm.Expr("GREATEST(like_count - 1, 0)")).Error
+		if err != nil {
+			return err
+		}
+
+		// the follows of & to the user are deleted by their foreign keys, the counts of the other users
+		// are decremented like unfollowing does, pending requests aren't counted
+		following := tx.Model(&models.Follow{}).Select("following_id").
+			Where("follower_id = ? AND status = ?", user.ID, models.FollowStatusAccepted)
+		err = tx.Model(&models.User{}).
+			Where("id IN (?)", following).
+			UpdateColumn("follower_count", gorm.Expr("GREATEST(follower_count - 1, 0)")).Error
+		if err != nil {
+			return err
+		}
+		followers := tx.Model(&models.Follow{}).Select("follower_id").
+			Where("following_id = ? AND status = ?", user.ID, models.FollowStatusAccepted)
+		err = tx.Model(&models.User{}).
+			Where("id IN (?)", followers).
+			UpdateColumn("following_count", gorm.Expr("GREATEST(following_count - 1, 0)")).Error
+		if err != nil {
+			return err
+		}
+
+		// variants, versions, likes & timeline entries of the photos are deleted by their foreign keys
+		userPhotos := tx.Unscoped().Model(&models.Photo{}).Select("id").Where("user_id = ?", user.ID)
+		if err := tx.Unscoped().Where("user_id = ? OR photo_id IN (?)", user.ID, userPhotos).Delete(&models.Comment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(&models.Photo{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.SocialMedia{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&user).Error
+	})
+	return
+}
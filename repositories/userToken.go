@@ -0,0 +1,52 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/alvinmdj/mygram-api/models"
+	"gorm.io/gorm"
+)
+
+type UserTokenRepoInterface interface {
+	Save(userToken models.UserToken) (models.UserToken, error)
+	FindByHash(purpose string, tokenHash string) (userToken models.UserToken, err error)
+	MarkUsed(userToken models.UserToken) (used bool, err error)
+	DeleteUnusedByUserId(purpose string, userId uint) (err error)
+}
+
+type UserTokenRepo struct {
+	db *gorm.DB
+}
+
+func NewUserTokenRepo(db *gorm.DB) UserTokenRepoInterface {
+	return &UserTokenRepo{
+		db: db,
+	}
+}
+
+func (u *UserTokenRepo) Save(userToken models.UserToken) (models.UserToken, error) {
+	err := u.db.Debug().Create(&userToken).Error
+	return userToken, err
+}
+
+func (u *UserTokenRepo) FindByHash(purpose string, tokenHash string) (userToken models.UserToken, err error) {
+	err = u.db.Debug().
+		Where("purpose = ? AND token_hash = ?", purpose, tokenHash).
+		Take(&userToken).Error
+	return
+}
+
+// MarkUsed consumes a token, used is false when the token was already consumed by another request
+func (u *UserTokenRepo) MarkUsed(userToken models.UserToken) (used bool, err error) {
+	result := u.db.Debug().Model(&models.UserToken{}).
+		Where("id = ? AND used_at IS NULL", userToken.ID).
+		Update("used_at", time.Now())
+	return result.RowsAffected == 1, result.Error
+}
+
+func (u *UserTokenRepo) DeleteUnusedByUserId(purpose string, userId uint) (err error) {
+	err = u.db.Debug().
+		Where("purpose = ? AND user_id = ? AND used_at IS NULL", purpose, userId).
+		Delete(&models.UserToken{}).Error
+	return
+}
@@ -1,12 +1,24 @@
 package routers
 
 import (
+	"log"
+	"os"
+	"time"
+
+	"github.com/alvinmdj/mygram-api/caches"
+	"github.com/alvinmdj/mygram-api/configs"
 	"github.com/alvinmdj/mygram-api/database"
 	_ "github.com/alvinmdj/mygram-api/docs" // docs is generated by Swag CLI, you have to import it.
 	"github.com/alvinmdj/mygram-api/handlers"
+	"github.com/alvinmdj/mygram-api/helpers"
+	"github.com/alvinmdj/mygram-api/mailers"
 	"github.com/alvinmdj/mygram-api/middlewares"
+	"github.com/alvinmdj/mygram-api/models"
+	"github.com/alvinmdj/mygram-api/policies"
 	"github.com/alvinmdj/mygram-api/repositories"
 	"github.com/alvinmdj/mygram-api/services"
+	"github.com/alvinmdj/mygram-api/storages"
+	"github.com/alvinmdj/mygram-api/uploads"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -15,26 +27,107 @@ import (
 func StartApp() *gin.Engine {
 	db := database.GetDB()
 
+	// token revocation store: "memory" (single node) or "database" (multi instance)
+	tokenRevocationRepo := repositories.NewTokenRevocationRepo(db, os.Getenv("TOKEN_REVOCATION_STORE"))
+	authentication := middlewares.Authentication(tokenRevocationRepo)
+
+	// mailer: "smtp", "file" or "log"
+	mailer := mailers.NewMailer(os.Getenv("MAIL_DRIVER"))
+
+	// upload limits & allowed types, storage quotas, per role overrides (e.g. premium users)
+	uploadConfig, err := configs.LoadUploadConfig()
+	if err != nil {
+		log.Fatal("error loading upload config:", err.Error())
+	}
+	configHdl := handlers.NewConfigHdl(uploadConfig)
+
 	userRepo := repositories.NewUserRepo(db)
-	userSvc := services.NewUserSvc(userRepo)
-	userHdl := handlers.NewUserHdl(userSvc)
+	refreshTokenRepo := repositories.NewRefreshTokenRepo(db)
+	userTokenRepo := repositories.NewUserTokenRepo(db)
+
+	photoRepo := repositories.NewPhotoRepo(db)
+	likeRepo := repositories.NewLikeRepo(db)
+	commentRepo := repositories.NewCommentRepo(db)
+
+	// home feeds: photos are fanned out to the timelines of the followers on write,
+	// except for users with more followers than the threshold whose photos are read from the follows
+	timelineRepo := repositories.NewTimelineRepo(db)
+	feedSvc := services.NewFeedSvc(timelineRepo, commentRepo, likeRepo, int64(helpers.GetEnvInt("FEED_FANOUT_THRESHOLD", 10000)))
+	feedHdl := handlers.NewFeedHdl(feedSvc)
+
+	// explore: recent photos of public users ranked by engagement, recomputed by a scheduler
+	photoRankingRepo := repositories.NewPhotoRankingRepo(db)
+	exploreSvc := services.NewExploreSvc(photoRankingRepo, likeRepo, helpers.SystemClock{}, helpers.GetEnvDuration("EXPLORE_WINDOW", 7*24*time.Hour))
+	exploreSvc.StartScheduler(helpers.GetEnvDuration("EXPLORE_RANKING_INTERVAL", 15*time.Minute))
+	exploreHdl := handlers.NewExploreHdl(exploreSvc)
+
+	followRepo := repositories.NewFollowRepo(db)
+	followSvc := services.NewFollowSvc(followRepo, userRepo, feedSvc)
+	followHdl := handlers.NewFollowHdl(followSvc)
 
 	socialMediaRepo := repositories.NewSocialMediaRepo(db)
 	socialMediaSvc := services.NewSocialMediaSvc(socialMediaRepo)
 	socialMediaHdl := handlers.NewSocialMediaHdl(socialMediaSvc)
 
-	photoRepo := repositories.NewPhotoRepo(db)
-	photoSvc := services.NewPhotoSvc(photoRepo)
+	// photo storage: "local", "s3" or "cloudinary"
+	storage := storages.GetStorage()
+
+	// files which fail to delete are queued and retried in the background
+	pendingDeletionRepo := repositories.NewPendingDeletionRepo(db)
+	storageCleanupSvc := services.NewStorageCleanupSvc(pendingDeletionRepo, photoRepo, storage)
+	storageCleanupSvc.StartWorker(helpers.GetEnvDuration("PENDING_DELETION_INTERVAL", time.Minute))
+
+	// deleting a user deletes their photos & the stored files
+	userSvc := services.NewUserSvc(userRepo, refreshTokenRepo, tokenRevocationRepo, userTokenRepo, mailer, uploadConfig, storageCleanupSvc)
+	userHdl := handlers.NewUserHdl(userSvc)
+	adminHdl := handlers.NewAdminHdl(userSvc)
+
+	// previous versions of a photo are kept up to the limit, the oldest ones are pruned with their files
+	photoSvc := services.NewPhotoSvc(photoRepo, userRepo, likeRepo, feedSvc, storage, storageCleanupSvc, uploadConfig, helpers.GetEnvInt("PHOTO_VERSION_LIMIT", 10))
 	photoHdl := handlers.NewPhotoHdl(photoSvc)
 
-	commentRepo := repositories.NewCommentRepo(db)
-	commentSvc := services.NewCommentSvc(commentRepo)
+	likeSvc := services.NewLikeSvc(likeRepo)
+	likeHdl := handlers.NewLikeHdl(likeSvc)
+
+	// resumable uploads are kept in a temporary directory until they are complete or expire
+	uploadStore, err := uploads.NewFileStore(uploadDir(), helpers.GetEnvDuration("UPLOAD_TTL", 24*time.Hour))
+	if err != nil {
+		log.Fatal("error creating upload directory:", err.Error())
+	}
+	uploadStore.StartGC(helpers.GetEnvDuration("UPLOAD_GC_INTERVAL", time.Hour))
+	uploadSvc := services.NewUploadSvc(uploadStore, photoSvc, uploadConfig)
+	uploadHdl := handlers.NewUploadHdl(uploadSvc)
+
+	// transformed image cache: "memory" or "disk"
+	imageCache := caches.NewCache(os.Getenv("IMAGE_CACHE_DRIVER"))
+	imageSvc := services.NewImageSvc(photoRepo, storage, imageCache, uploadConfig)
+	imageHdl := handlers.NewImageHdl(imageSvc)
+
+	commentSvc := services.NewCommentSvc(commentRepo, photoRepo, followRepo)
 	commentHdl := handlers.NewCommentHdl(commentSvc)
 
+	// deleted photos & comments stay in the trash for the retention period
+	trashSvc := services.NewTrashSvc(photoRepo, commentRepo, userRepo, storageCleanupSvc, helpers.GetEnvDuration("TRASH_RETENTION", 30*24*time.Hour))
+	trashSvc.StartPurgeJob(helpers.GetEnvDuration("TRASH_PURGE_INTERVAL", time.Hour))
+	trashHdl := handlers.NewTrashHdl(trashSvc)
+
+	// authorization rules: the owner of the data, the owner of the commented photo, moderators and admins
+	owner := policies.Owner()
+	photoOwner := policies.ParentOwner()
+	staff := policies.Role(models.RoleModerator, models.RoleAdmin)
+	// owners can only restore what they moved to the trash themselves
+	deleter := policies.Deleter()
+
 	r := gin.Default()
 
-	// set a lower memory limit for multipart forms (default is 32 MiB)
-	r.MaxMultipartMemory = 2 << 20 // 2 MiB
+	// files of the local storage are served by the app itself
+	if localStorage, ok := storage.(*storages.LocalStorage); ok {
+		localStorageHdl := handlers.NewLocalStorageHdl(localStorage)
+		r.GET(localStorage.URLPrefix+"/*key", localStorageHdl.Get)
+		r.HEAD(localStorage.URLPrefix+"/*key", localStorageHdl.Get)
+		// direct (presigned) uploads to the local storage
+		r.PUT(localStorage.URLPrefix+"/*key", localStorageHdl.Put)
+	}
 
 	v1 := r.Group("/api/v1")
 	{
@@ -43,23 +136,85 @@ func StartApp() *gin.Engine {
 		{
 			userRouter.POST("/register", userHdl.Register)
 			userRouter.POST("/login", userHdl.Login)
+			userRouter.POST("/refresh", userHdl.Refresh)
+			userRouter.POST("/logout", authentication, userHdl.Logout)
+			userRouter.POST("/logout-all", authentication, userHdl.LogoutAll)
+
+			// profile routes
+			userRouter.GET("/me", authentication, userHdl.GetMe)
+			userRouter.PUT("/me", authentication, userHdl.UpdateMe)
+			userRouter.DELETE("/me", authentication, userHdl.DeleteMe)
+			userRouter.GET("/me/usage", authentication, userHdl.GetMyUsage)
+			userRouter.GET("/me/trash", authentication, trashHdl.GetMine)
+			userRouter.GET("/:username", userHdl.GetByUsername)
+
+			// follows, following a private account needs approval
+			userRouter.PUT("/me/privacy", authentication, followHdl.UpdatePrivacy)
+			userRouter.GET("/me/follow-requests", authentication, followHdl.GetRequests)
+			userRouter.POST("/me/follow-requests/:userId/accept", authentication, followHdl.AcceptRequest)
+			userRouter.DELETE("/me/follow-requests/:userId", authentication, followHdl.DeclineRequest)
+			userRouter.PUT("/:username/follow", authentication, followHdl.Follow)
+			userRouter.DELETE("/:username/follow", authentication, followHdl.Unfollow)
+			userRouter.GET("/:username/followers", authentication, followHdl.GetFollowers)
+			userRouter.GET("/:username/following", authentication, followHdl.GetFollowing)
+
+			// password routes
+			userRouter.POST("/me/password", authentication, userHdl.ChangePassword)
+			userRouter.POST("/password/forgot", userHdl.ForgotPassword)
+			userRouter.POST("/password/reset", userHdl.ResetPassword)
+
+			// email verification routes
+			userRouter.GET("/verify", userHdl.VerifyEmail)
+			userRouter.POST("/verify/resend", userHdl.ResendVerification)
+		}
+
+		// admin only routes
+		adminRouter := v1.Group("/admin")
+		{
+			adminRouter.Use(authentication, middlewares.RequireRole(models.RoleAdmin))
+
+			adminRouter.GET("/users", adminHdl.GetAllUsers)
+			adminRouter.GET("/users/:userId", adminHdl.GetUserById)
+			adminRouter.PUT("/users/:userId/role", adminHdl.UpdateUserRole)
+			adminRouter.PUT("/users/:userId/quota", adminHdl.UpdateUserQuota)
+			adminRouter.POST("/users/:userId/block", adminHdl.BlockUser)
+			adminRouter.DELETE("/users/:userId/block", adminHdl.UnblockUser)
+			adminRouter.DELETE("/users/:userId", adminHdl.DeleteUser)
 		}
 
+		// resumable (tus) upload discovery
+		v1.OPTIONS("/uploads", uploadHdl.Options)
+
+		// upload limits for validation on the client
+		v1.GET("/config/uploads", configHdl.GetUploads)
+
 		// authenticated user only routes
 		authenticatedRouter := v1.Group("/")
 		{
-			authenticatedRouter.Use(middlewares.Authentication())
+			authenticatedRouter.Use(authentication)
+
+			// home feed of the photos of the followed users
+			authenticatedRouter.GET("/feed", feedHdl.Get)
+			authenticatedRouter.GET("/explore", exploreHdl.Get)
 
 			// social media routes
 			socialMediaRouter := authenticatedRouter.Group("/social-medias")
 			{
 				socialMediaRouter.GET("", socialMediaHdl.GetAll)
 				socialMediaRouter.GET("/:socialMediaId", socialMediaHdl.GetOneById)
-				socialMediaRouter.POST("", socialMediaHdl.Create)
+				socialMediaRouter.POST("", middlewares.VerifiedEmail(), socialMediaHdl.Create)
 
 				// implement authorization middleware
-				socialMediaRouter.PUT("/:socialMediaId", middlewares.SocialMediaAuthorization(), socialMediaHdl.Update)
-				socialMediaRouter.DELETE("/:socialMediaId", middlewares.SocialMediaAuthorization(), socialMediaHdl.Delete)
+				socialMediaRouter.PUT(
+					"/:socialMediaId",
+					middlewares.Authorize(policies.New(policies.ResourceSocialMedia, policies.ActionUpdate, owner, staff)),
+					socialMediaHdl.Update,
+				)
+				socialMediaRouter.DELETE(
+					"/:socialMediaId",
+					middlewares.Authorize(policies.New(policies.ResourceSocialMedia, policies.ActionDelete, owner, staff)),
+					socialMediaHdl.Delete,
+				)
 			}
 
 			// photo routes
@@ -69,11 +224,61 @@ func StartApp() *gin.Engine {
 				photoRouter.GET("/:photoId", photoHdl.GetOneById)
 
 				// implement body size middleware to validate uploaded file size
-				photoRouter.POST("", middlewares.BodySizeMiddleware(), photoHdl.Create)
+				photoRouter.POST("", middlewares.VerifiedEmail(), middlewares.BodySizeMiddleware(uploadConfig), photoHdl.Create)
+				photoRouter.POST("/uploads", middlewares.VerifiedEmail(), photoHdl.CreateUpload)
 
 				// implement authorization middleware (+ body size middleware for update handler)
-				photoRouter.PUT("/:photoId", middlewares.PhotoAuthorization(), middlewares.BodySizeMiddleware(), photoHdl.Update)
-				photoRouter.DELETE("/:photoId", middlewares.PhotoAuthorization(), photoHdl.Delete)
+				photoRouter.PUT(
+					"/:photoId",
+					middlewares.Authorize(policies.New(policies.ResourcePhoto, policies.ActionUpdate, owner, staff)),
+					middlewares.BodySizeMiddleware(uploadConfig),
+					photoHdl.Update,
+				)
+				photoRouter.DELETE(
+					"/:photoId",
+					middlewares.Authorize(policies.New(policies.ResourcePhoto, policies.ActionDelete, owner, staff)),
+					photoHdl.Delete,
+				)
+				photoRouter.POST(
+					"/:photoId/restore",
+					middlewares.Authorize(policies.New(policies.ResourcePhoto, policies.ActionRestore, deleter, staff)),
+					photoHdl.Restore,
+				)
+				photoRouter.GET(
+					"/:photoId/versions",
+					middlewares.Authorize(policies.New(policies.ResourcePhoto, policies.ActionRead, owner, staff)),
+					photoHdl.GetVersions,
+				)
+				photoRouter.POST(
+					"/:photoId/versions/:version/revert",
+					middlewares.Authorize(policies.New(policies.ResourcePhoto, policies.ActionUpdate, owner, staff)),
+					photoHdl.Revert,
+				)
+				photoRouter.PUT(
+					"/:photoId/settings",
+					middlewares.Authorize(policies.New(policies.ResourcePhoto, policies.ActionUpdate, owner, staff)),
+					photoHdl.UpdateSettings,
+				)
+				photoRouter.GET("/:photoId/image-url", imageHdl.GetSignedURL)
+			}
+
+			likeRouter := authenticatedRouter.Group("/photos/:photoId")
+			{
+				// implement middleware to find photo by photo id
+				likeRouter.Use(middlewares.FindPhoto())
+
+				likeRouter.GET("/likes", likeHdl.GetAll)
+				likeRouter.PUT("/like", likeHdl.Like)
+				likeRouter.DELETE("/like", likeHdl.Unlike)
+			}
+
+			// resumable photo uploads, the photo is created when the upload is complete
+			uploadRouter := authenticatedRouter.Group("/uploads")
+			{
+				uploadRouter.POST("", middlewares.VerifiedEmail(), uploadHdl.Create)
+				uploadRouter.HEAD("/:uploadId", uploadHdl.Head)
+				uploadRouter.PATCH("/:uploadId", uploadHdl.Patch)
+				uploadRouter.DELETE("/:uploadId", uploadHdl.Delete)
 			}
 
 			commentRouter := authenticatedRouter.Group("/photos/:photoId/comments")
@@ -83,17 +288,52 @@ func StartApp() *gin.Engine {
 
 				commentRouter.GET("", commentHdl.GetAll)
 				commentRouter.GET("/:commentId", commentHdl.GetOneById)
-				commentRouter.POST("", commentHdl.Create)
+				commentRouter.POST("", middlewares.VerifiedEmail(), commentHdl.Create)
 
 				// implement authorization middleware
-				commentRouter.PUT("/:commentId", middlewares.CommentAuthorization(), commentHdl.Update)
-				commentRouter.DELETE("/:commentId", middlewares.CommentAuthorization(), commentHdl.Delete)
+				commentRouter.PUT(
+					"/:commentId",
+					middlewares.Authorize(policies.New(policies.ResourceComment, policies.ActionUpdate, owner, staff)),
+					commentHdl.Update,
+				)
+				commentRouter.DELETE(
+					"/:commentId",
+					middlewares.Authorize(policies.New(policies.ResourceComment, policies.ActionDelete, owner, photoOwner, staff)),
+					commentHdl.Delete,
+				)
+				commentRouter.POST(
+					"/:commentId/restore",
+					middlewares.Authorize(policies.New(policies.ResourceComment, policies.ActionRestore, deleter, staff)),
+					commentHdl.Restore,
+				)
+
+				// the photo owner may hide comments on their photo
+				commentRouter.POST(
+					"/:commentId/hide",
+					middlewares.Authorize(policies.New(policies.ResourceComment, policies.ActionHide, photoOwner, staff)),
+					commentHdl.Hide,
+				)
+				commentRouter.DELETE(
+					"/:commentId/hide",
+					middlewares.Authorize(policies.New(policies.ResourceComment, policies.ActionHide, photoOwner, staff)),
+					commentHdl.Unhide,
+				)
 			}
 		}
 	}
 
+	// transformed photos, public so they can be used in img tags, urls are signed
+	r.GET("/img/:photoId", imageHdl.Get)
+
 	// swagger
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	return r
 }
+
+func uploadDir() string {
+	if dir := os.Getenv("UPLOAD_DIR"); dir != "" {
+		return dir
+	}
+	return "tmp/uploads"
+}
@@ -1,39 +1,75 @@
 package services
 
 import (
+	"time"
+
 	"github.com/alvinmdj/mygram-api/models"
 	"github.com/alvinmdj/mygram-api/repositories"
 )
 
 type CommentSvcInterface interface {
-	GetAll(photoId int) (comments []models.Comment, err error)
-	GetOneById(photoId int, commentId int) (comment models.Comment, err error)
+	GetAll(photoId int, viewerId uint, includeHidden bool, page models.PageInput) (comments []models.Comment, nextCursor string, err error)
+	GetOneById(photoId int, commentId int, viewerId uint, includeHidden bool) (comment models.Comment, err error)
 	Create(commentInput models.CommentCreateInput) (comment models.Comment, err error)
 	Update(commentInput models.CommentUpdateInput) (comment models.Comment, err error)
-	Delete(commentId int) (err error)
+	Delete(commentId int, userId uint) (err error)
+	Restore(commentId int) (err error)
+	Hide(commentId int) (err error)
+	Unhide(commentId int) (err error)
 }
 
 type CommentSvc struct {
 	commentRepo repositories.CommentRepoInterface
+	photoRepo   repositories.PhotoRepoInterface
+	followRepo  repositories.FollowRepoInterface
 }
 
-func NewCommentSvc(commentRepo repositories.CommentRepoInterface) CommentSvcInterface {
+func NewCommentSvc(
+	commentRepo repositories.CommentRepoInterface,
+	photoRepo repositories.PhotoRepoInterface,
+	followRepo repositories.FollowRepoInterface,
+) CommentSvcInterface {
 	return &CommentSvc{
 		commentRepo: commentRepo,
+		photoRepo:   photoRepo,
+		followRepo:  followRepo,
 	}
 }
 
-func (co *CommentSvc) GetAll(photoId int) (comments []models.Comment, err error) {
-	comments, err = co.commentRepo.FindAll(photoId)
+func (co *CommentSvc) GetAll(photoId int, viewerId uint, includeHidden bool, page models.PageInput) (comments []models.Comment, nextCursor string, err error) {
+	comments, nextCursor, err = co.commentRepo.FindAll(photoId, viewerId, includeHidden, page)
 	return
 }
 
-func (co *CommentSvc) GetOneById(photoId int, commentId int) (comment models.Comment, err error) {
-	comment, err = co.commentRepo.FindById(photoId, commentId)
+func (co *CommentSvc) GetOneById(photoId int, commentId int, viewerId uint, includeHidden bool) (comment models.Comment, err error) {
+	comment, err = co.commentRepo.FindById(photoId, commentId, viewerId, includeHidden)
 	return
 }
 
 func (co *CommentSvc) Create(commentInput models.CommentCreateInput) (comment models.Comment, err error) {
+	// check if the photo owner allows the user to comment
+	photo, err := co.photoRepo.FindById(int(commentInput.PhotoID))
+	if err != nil {
+		return
+	}
+
+	switch photo.CommentPolicy {
+	case models.CommentPolicyOff:
+		err = ErrCommentsDisabled
+		return
+	case models.CommentPolicyFollowers:
+		// the owner and accepted followers can comment, pending follow requests don't count
+		if photo.UserID != commentInput.UserID {
+			following, err := co.followRepo.IsFollowing(commentInput.UserID, photo.UserID)
+			if err != nil {
+				return comment, err
+			}
+			if !following {
+				return comment, ErrCommentsFollowersOnly
+			}
+		}
+	}
+
 	comment = models.Comment{
 		Message: commentInput.Message,
 		UserID:  commentInput.UserID,
@@ -45,22 +81,58 @@ func (co *CommentSvc) Create(commentInput models.CommentCreateInput) (comment mo
 }
 
 func (co *CommentSvc) Update(commentInput models.CommentUpdateInput) (comment models.Comment, err error) {
+	// staff may update comments of other users, the author stays
+	current, err := co.commentRepo.FindById(int(commentInput.PhotoID), int(commentInput.ID), commentInput.UserID, true)
+	if err != nil {
+		return
+	}
+
 	comment = models.Comment{
 		Base:    models.Base{ID: commentInput.ID},
 		Message: commentInput.Message,
-		UserID:  commentInput.UserID,
-		PhotoID: commentInput.PhotoID,
+		UserID:  current.UserID,
+		PhotoID: current.PhotoID,
 	}
 
 	comment, err = co.commentRepo.Update(comment)
 	return
 }
 
-func (co *CommentSvc) Delete(commentId int) (err error) {
+func (co *CommentSvc) Delete(commentId int, userId uint) (err error) {
+	comment := models.Comment{
+		Base: models.Base{ID: uint(commentId)},
+	}
+
+	err = co.commentRepo.Delete(comment, userId)
+	return
+}
+
+// Restore moves a comment out of the trash
+func (co *CommentSvc) Restore(commentId int) (err error) {
+	comment := models.Comment{
+		Base: models.Base{ID: uint(commentId)},
+	}
+
+	err = co.commentRepo.Restore(comment)
+	return
+}
+
+func (co *CommentSvc) Hide(commentId int) (err error) {
+	now := time.Now()
+	comment := models.Comment{
+		Base:     models.Base{ID: uint(commentId)},
+		HiddenAt: &now,
+	}
+
+	err = co.commentRepo.UpdateHiddenAt(comment)
+	return
+}
+
+func (co *CommentSvc) Unhide(commentId int) (err error) {
 	comment := models.Comment{
 		Base: models.Base{ID: uint(commentId)},
 	}
 
-	err = co.commentRepo.Delete(comment)
+	err = co.commentRepo.UpdateHiddenAt(comment)
 	return
 }
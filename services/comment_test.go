@@ -0,0 +1,50 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/alvinmdj/mygram-api/models"
+	"github.com/alvinmdj/mygram-api/repositories"
+	"gorm.io/gorm"
+)
+
+// fakeCommentRepo keeps the comments in memory, the other methods aren't used by the tests
+type fakeCommentRepo struct {
+	repositories.CommentRepoInterface
+	comments map[uint]models.Comment
+}
+
+func (f *fakeCommentRepo) FindById(photoId int, commentId int, viewerId uint, includeHidden bool) (models.Comment, error) {
+	comment, ok := f.comments[uint(commentId)]
+	if !ok || comment.PhotoID != uint(photoId) {
+		return comment, gorm.ErrRecordNotFound
+	}
+	return comment, nil
+}
+
+func (f *fakeCommentRepo) Update(comment models.Comment) (models.Comment, error) {
+	stored := f.comments[comment.ID]
+	stored.Message = comment.Message
+	f.comments[comment.ID] = stored
+	return comment, nil
+}
+
+func TestCommentUpdateByStaffKeepsAuthor(t *testing.T) {
+	commentRepo := &fakeCommentRepo{comments: map[uint]models.Comment{
+		5: {Base: models.Base{ID: 5}, Message: "spam", UserID: 1, PhotoID: 2},
+	}}
+	commentSvc := NewCommentSvc(commentRepo, nil, nil)
+
+	// a moderator (user 9) edits the comment of user 1
+	comment, err := commentSvc.Update(models.CommentUpdateInput{ID: 5, Message: "[removed]", UserID: 9, PhotoID: 2})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if comment.UserID != 1 || comment.PhotoID != 2 || comment.Message != "[removed]" {
+		t.Errorf("Update() by staff = %+v, want the message updated & user 1 as author", comment)
+	}
+
+	if _, err := commentSvc.Update(models.CommentUpdateInput{ID: 5, Message: "moved", UserID: 9, PhotoID: 3}); err == nil {
+		t.Error("Update() of a comment of another photo error = nil")
+	}
+}
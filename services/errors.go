@@ -0,0 +1,24 @@
+package services
+
+import "errors"
+
+var (
+	ErrUsernameTaken = errors.New("username is already taken")
+	ErrEmailTaken    = errors.New("email is already registered")
+
+	ErrEmailNotVerified = errors.New("email is not verified, check your inbox for the verification link")
+	ErrUserBlocked      = errors.New("this account has been blocked")
+	ErrSelfManagement   = errors.New("you can't change the role of or block your own account")
+
+	ErrCommentsDisabled      = errors.New("comments are turned off for this photo")
+	ErrCommentsFollowersOnly = errors.New("only followers of the owner can comment on this photo")
+
+	ErrFollowSelf     = errors.New("you can't follow yourself")
+	ErrPrivateAccount = errors.New("this account is private, follow it to see its followers and followed users")
+
+	ErrInvalidImageSignature = errors.New("invalid image signature")
+	ErrImagePresetRequired   = errors.New("only the owner can request this size, use w=150&h=150&fit=cover, w=320&h=320&fit=cover, w=640 or w=1080")
+	ErrUploadTooLarge        = errors.New("upload is larger than the maximum upload size")
+	ErrUploadNotFound        = errors.New("uploaded file doesn't exist, upload the file with the presigned url first")
+	ErrQuotaExceeded         = errors.New("storage quota exceeded, delete some photos to free up space")
+)
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"log"
+	"math"
+	"time"
+
+	"github.com/alvinmdj/mygram-api/helpers"
+	"github.com/alvinmdj/mygram-api/models"
+	"github.com/alvinmdj/mygram-api/repositories"
+)
+
+type ExploreSvcInterface interface {
+	GetExplore(viewerId uint, page models.PageInput) (photos []models.Photo, nextCursor string, err error)
+	ComputeRankings() (ranked int, err error)
+	StartScheduler(interval time.Duration)
+}
+
+// ExploreSvc ranks the recent photos of public users by their engagement, decayed by their age.
+// the time is taken from the clock so the rankings are deterministic for a fixed clock
+type ExploreSvc struct {
+	photoRankingRepo repositories.PhotoRankingRepoInterface
+	likeRepo         repositories.LikeRepoInterface
+	clock            helpers.Clock
+	window           time.Duration // how old photos may be to be ranked
+}
+
+func NewExploreSvc(
+	photoRankingRepo repositories.PhotoRankingRepoInterface,
+	likeRepo repositories.LikeRepoInterface,
+	clock helpers.Clock,
+	window time.Duration,
+) ExploreSvcInterface {
+	return &ExploreSvc{
+		photoRankingRepo: photoRankingRepo,
+		likeRepo:         likeRepo,
+		clock:            clock,
+		window:           window,
+	}
+}
+
+// weights of the engagement & how fast the score decays with the age of a photo
+const (
+	exploreLikeWeight      = 1.0
+	exploreCommentWeight   = 2.0
+	exploreCommenterWeight = 3.0
+	exploreGravity         = 1.5
+)
+
+// GetExplore returns the ranked photos, highest score first
+func (e *ExploreSvc) GetExplore(viewerId uint, page models.PageInput) (photos []models.Photo, nextCursor string, err error) {
+	photos, nextCursor, err = e.photoRankingRepo.FindExplore(page)
+	if err != nil || len(photos) == 0 {
+		return
+	}
+
+	photoIds := make([]uint, 0, len(photos))
+	for _, photo := range photos {
+		photoIds = append(photoIds, photo.ID)
+	}
+	liked, err := e.likeRepo.FindLikedPhotoIds(viewerId, photoIds)
+	if err != nil {
+		return
+	}
+	for i := range photos {
+		photos[i].LikedByMe = liked[photos[i].ID]
+	}
+	return
+}
+
+// ComputeRankings scores the photos created within the window and replaces the rankings
+func (e *ExploreSvc) ComputeRankings() (ranked int, err error) {
+	now := e.clock.Now()
+	engagements, err := e.photoRankingRepo.FindEngagement(now.Add(-e.window))
+	if err != nil {
+		return
+	}
+
+	rankings := make([]models.PhotoRanking, 0, len(engagements))
+	for _, engagement := range engagements {
+		rankings = append(rankings, models.PhotoRanking{
+			PhotoID:    engagement.PhotoID,
+			Score:      exploreScore(engagement, now),
+			Likes:      engagement.Likes,
+			Comments:   engagement.Comments,
+			Commenters: engagement.Commenters,
+			ComputedAt: now,
+		})
+	}
+
+	err = e.photoRankingRepo.ReplaceAll(rankings)
+	return len(rankings), err
+}
+
+// StartScheduler computes the rankings right away and then every interval in the background
+func (e *ExploreSvc) StartScheduler(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			ranked, err := e.ComputeRankings()
+			if err != nil {
+				log.Printf("error computing explore rankings: %v", err)
+			} else {
+				log.Printf("ranked %d photos for explore", ranked)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// exploreScore weighs the engagement of a photo and decays it by its age in hours:
+// engagement / (age + 2) ^ gravity, so a new photo needs less engagement to rank as high as an old one
+func exploreScore(engagement models.PhotoEngagement, now time.Time) float64 {
+	points := exploreLikeWeight*float64(engagement.Likes) +
+		exploreCommentWeight*float64(engagement.Comments) +
+		exploreCommenterWeight*float64(engagement.Commenters)
+
+	age := now.Sub(engagement.CreatedAt).Hours()
+	if age < 0 {
+		age = 0
+	}
+	return points / math.Pow(age+2, exploreGravity)
+}
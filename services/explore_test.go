@@ -0,0 +1,140 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/alvinmdj/mygram-api/helpers"
+	"github.com/alvinmdj/mygram-api/models"
+)
+
+// fakePhotoRankingRepo returns fixed engagements and records the replaced rankings
+type fakePhotoRankingRepo struct {
+	engagements []models.PhotoEngagement
+	err         error
+	since       time.Time
+	rankings    []models.PhotoRanking
+	replaced    bool
+}
+
+func (f *fakePhotoRankingRepo) FindEngagement(since time.Time) ([]models.PhotoEngagement, error) {
+	f.since = since
+	return f.engagements, f.err
+}
+
+func (f *fakePhotoRankingRepo) ReplaceAll(rankings []models.PhotoRanking) error {
+	f.rankings = rankings
+	f.replaced = true
+	return nil
+}
+
+func (f *fakePhotoRankingRepo) FindExplore(page models.PageInput) ([]models.Photo, string, error) {
+	return nil, "", nil
+}
+
+func TestExploreScore(t *testing.T) {
+	now := time.Date(2023, 4, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		engagement models.PhotoEngagement
+		want       float64
+	}{
+		{
+			name:       "no engagement",
+			engagement: models.PhotoEngagement{CreatedAt: now.Add(-time.Hour)},
+			want:       0,
+		},
+		{
+			// (4*1 + 1*2 + 1*3) / (2 + 2)^1.5
+			name:       "weighted engagement decayed by age",
+			engagement: models.PhotoEngagement{CreatedAt: now.Add(-2 * time.Hour), Likes: 4, Comments: 1, Commenters: 1},
+			want:       1.125,
+		},
+		{
+			// (2*1) / (0 + 2)^1.5
+			name:       "created now",
+			engagement: models.PhotoEngagement{CreatedAt: now, Likes: 2},
+			want:       2 / math.Pow(2, 1.5),
+		},
+		{
+			name:       "created in the future counts as new",
+			engagement: models.PhotoEngagement{CreatedAt: now.Add(time.Hour), Likes: 2},
+			want:       2 / math.Pow(2, 1.5),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := exploreScore(test.engagement, now); math.Abs(got-test.want) > 1e-9 {
+				t.Errorf("exploreScore() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestExploreScoreDecay(t *testing.T) {
+	now := time.Date(2023, 4, 1, 12, 0, 0, 0, time.UTC)
+
+	newer := exploreScore(models.PhotoEngagement{CreatedAt: now.Add(-time.Hour), Likes: 10}, now)
+	older := exploreScore(models.PhotoEngagement{CreatedAt: now.Add(-24 * time.Hour), Likes: 10}, now)
+	if newer <= older {
+		t.Errorf("exploreScore() of a newer photo = %v, want more than %v of an older one", newer, older)
+	}
+
+	commenters := exploreScore(models.PhotoEngagement{CreatedAt: now, Comments: 2, Commenters: 2}, now)
+	oneCommenter := exploreScore(models.PhotoEngagement{CreatedAt: now, Comments: 2, Commenters: 1}, now)
+	if commenters <= oneCommenter {
+		t.Errorf("exploreScore() with 2 commenters = %v, want more than %v with 1 commenter", commenters, oneCommenter)
+	}
+}
+
+func TestComputeRankings(t *testing.T) {
+	now := time.Date(2023, 4, 1, 12, 0, 0, 0, time.UTC)
+	engagements := []models.PhotoEngagement{
+		{PhotoID: 1, CreatedAt: now.Add(-2 * time.Hour), Likes: 4, Comments: 1, Commenters: 1},
+		{PhotoID: 2, CreatedAt: now.Add(-30 * time.Hour)},
+	}
+	repo := &fakePhotoRankingRepo{engagements: engagements}
+	exploreSvc := NewExploreSvc(repo, nil, helpers.ClockFunc(func() time.Time { return now }), 48*time.Hour)
+
+	ranked, err := exploreSvc.ComputeRankings()
+	if err != nil {
+		t.Fatalf("ComputeRankings() error = %v", err)
+	}
+	if ranked != 2 || len(repo.rankings) != 2 {
+		t.Fatalf("ComputeRankings() ranked %d, replaced %d rankings, want 2", ranked, len(repo.rankings))
+	}
+	if want := now.Add(-48 * time.Hour); !repo.since.Equal(want) {
+		t.Errorf("FindEngagement() since = %v, want %v", repo.since, want)
+	}
+
+	for i, ranking := range repo.rankings {
+		engagement := engagements[i]
+		want := models.PhotoRanking{
+			PhotoID:    engagement.PhotoID,
+			Score:      exploreScore(engagement, now),
+			Likes:      engagement.Likes,
+			Comments:   engagement.Comments,
+			Commenters: engagement.Commenters,
+			ComputedAt: now,
+		}
+		if ranking != want {
+			t.Errorf("ranking %d = %+v, want %+v", i, ranking, want)
+		}
+	}
+}
+
+func TestComputeRankingsError(t *testing.T) {
+	repo := &fakePhotoRankingRepo{err: errors.New("connection refused")}
+	exploreSvc := NewExploreSvc(repo, nil, helpers.ClockFunc(time.Now), time.Hour)
+
+	if _, err := exploreSvc.ComputeRankings(); err == nil {
+		t.Fatal("ComputeRankings() error = nil, want the repository error")
+	}
+	if repo.replaced {
+		t.Error("ComputeRankings() replaced the rankings after an error")
+	}
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"log"
+
+	"github.com/alvinmdj/mygram-api/models"
+	"github.com/alvinmdj/mygram-api/repositories"
+)
+
+type FeedSvcInterface interface {
+	GetFeed(userId uint, page models.PageInput) (photos []models.Photo, nextCursor string, err error)
+	PhotoCreated(photo models.Photo)
+	Followed(followerId uint, followingId uint)
+	Unfollowed(followerId uint, followingId uint)
+}
+
+// FeedSvc builds the home feeds: a new photo is written into the timelines of the followers of its author
+// (fan-out on write), except for authors with more than fanOutThreshold followers whose photos are
+// read from the follows when the feed is loaded (fan-out on read). the choice is recorded per photo
+type FeedSvc struct {
+	timelineRepo    repositories.TimelineRepoInterface
+	commentRepo     repositories.CommentRepoInterface
+	likeRepo        repositories.LikeRepoInterface
+	fanOutThreshold int64
+}
+
+func NewFeedSvc(
+	timelineRepo repositories.TimelineRepoInterface,
+	commentRepo repositories.CommentRepoInterface,
+	likeRepo repositories.LikeRepoInterface,
+	fanOutThreshold int64,
+) FeedSvcInterface {
+	return &FeedSvc{
+		timelineRepo:    timelineRepo,
+		commentRepo:     commentRepo,
+		likeRepo:        likeRepo,
+		fanOutThreshold: fanOutThreshold,
+	}
+}
+
+const (
+	// how many of the latest comments of each photo are shown in the feed
+	feedCommentPreviews = 3
+	// how many of the latest photos of a user are added to the timeline when the user is followed
+	feedBackfillLimit = 50
+)
+
+// GetFeed returns the photos of the users the user follows, newest first, with a preview of their latest comments
+func (f *FeedSvc) GetFeed(userId uint, page models.PageInput) (photos []models.Photo, nextCursor string, err error) {
+	photos, nextCursor, err = f.timelineRepo.FindFeed(userId, page)
+	if err != nil || len(photos) == 0 {
+		return
+	}
+
+	photoIds := make([]uint, 0, len(photos))
+	for _, photo := range photos {
+		photoIds = append(photoIds, photo.ID)
+	}
+
+	comments, err := f.commentRepo.FindLatest(photoIds, userId, feedCommentPreviews)
+	if err != nil {
+		return
+	}
+	liked, err := f.likeRepo.FindLikedPhotoIds(userId, photoIds)
+	if err != nil {
+		return
+	}
+
+	commentsByPhoto := map[uint][]models.Comment{}
+	for _, comment := range comments {
+		commentsByPhoto[comment.PhotoID] = append(commentsByPhoto[comment.PhotoID], comment)
+	}
+	for i := range photos {
+		photos[i].Comments = commentsByPhoto[photos[i].ID]
+		photos[i].LikedByMe = liked[photos[i].ID]
+	}
+	return
+}
+
+// PhotoCreated fans a new photo out to the timelines of the followers of its author,
+// failures are only logged as the photo is created already
+func (f *FeedSvc) PhotoCreated(photo models.Photo) {
+	if _, err := f.timelineRepo.FanOut(photo, f.fanOutThreshold); err != nil {
+		log.Printf("error fanning out photo %d: %v", photo.ID, err)
+	}
+}
+
+// Followed adds the latest photos of a newly followed user to the timeline of the follower
+func (f *FeedSvc) Followed(followerId uint, followingId uint) {
+	if err := f.timelineRepo.Backfill(followerId, followingId, feedBackfillLimit); err != nil {
+		log.Printf("error adding photos of user %d to the timeline of user %d: %v", followingId, followerId, err)
+	}
+}
+
+// Unfollowed removes the photos of an unfollowed user from the timeline of the follower
+func (f *FeedSvc) Unfollowed(followerId uint, followingId uint) {
+	if err := f.timelineRepo.RemoveAuthor(followerId, followingId); err != nil {
+		log.Printf("error removing photos of user %d from the timeline of user %d: %v", followingId, followerId, err)
+	}
+}
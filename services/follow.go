@@ -0,0 +1,179 @@
+package services
+
+import (
+	"github.com/alvinmdj/mygram-api/models"
+	"github.com/alvinmdj/mygram-api/repositories"
+	"gorm.io/gorm"
+)
+
+type FollowSvcInterface interface {
+	Follow(followerId uint, username string) (follow models.FollowOutput, err error)
+	Unfollow(followerId uint, username string) (follow models.FollowOutput, err error)
+	GetFollowers(username string, viewerId uint, page models.PageInput) (follows []models.Follow, nextCursor string, err error)
+	GetFollowing(username string, viewerId uint, page models.PageInput) (follows []models.Follow, nextCursor string, err error)
+	GetRequests(userId uint, page models.PageInput) (follows []models.Follow, nextCursor string, err error)
+	AcceptRequest(userId uint, followerId uint) (err error)
+	DeclineRequest(userId uint, followerId uint) (err error)
+	UpdatePrivacy(userId uint, privacyInput models.UserPrivacyInput) (user models.User, err error)
+}
+
+type FollowSvc struct {
+	followRepo repositories.FollowRepoInterface
+	userRepo   repositories.UserRepoInterface
+	feedSvc    FeedSvcInterface
+}
+
+func NewFollowSvc(followRepo repositories.FollowRepoInterface, userRepo repositories.UserRepoInterface, feedSvc FeedSvcInterface) FollowSvcInterface {
+	return &FollowSvc{
+		followRepo: followRepo,
+		userRepo:   userRepo,
+		feedSvc:    feedSvc,
+	}
+}
+
+// Follow follows a user, following a private user sends a follow request which needs approval
+func (f *FollowSvc) Follow(followerId uint, username string) (follow models.FollowOutput, err error) {
+	user, err := f.userRepo.FindByUsername(username)
+	if err != nil {
+		return
+	}
+	if user.ID == followerId {
+		err = ErrFollowSelf
+		return
+	}
+
+	status := models.FollowStatusAccepted
+	if user.IsPrivate {
+		status = models.FollowStatusPending
+	}
+
+	saved, err := f.followRepo.Save(models.Follow{
+		FollowerID:  followerId,
+		FollowingID: user.ID,
+		Status:      status,
+	})
+	if err != nil {
+		return
+	}
+	if saved.Status == models.FollowStatusAccepted {
+		f.feedSvc.Followed(followerId, user.ID)
+	}
+
+	follow = models.FollowOutput{
+		UserID:   user.ID,
+		Username: user.Username,
+		Status:   saved.Status,
+	}
+	return
+}
+
+// Unfollow unfollows a user or withdraws a follow request
+func (f *FollowSvc) Unfollow(followerId uint, username string) (follow models.FollowOutput, err error) {
+	user, err := f.userRepo.FindByUsername(username)
+	if err != nil {
+		return
+	}
+
+	if _, err = f.followRepo.Delete(followerId, user.ID); err != nil {
+		return
+	}
+	f.feedSvc.Unfollowed(followerId, user.ID)
+
+	follow = models.FollowOutput{
+		UserID:   user.ID,
+		Username: user.Username,
+		Status:   "none",
+	}
+	return
+}
+
+// GetFollowers returns the followers of a user, the followers of a private user
+// are only visible to the user and its followers
+func (f *FollowSvc) GetFollowers(username string, viewerId uint, page models.PageInput) (follows []models.Follow, nextCursor string, err error) {
+	user, err := f.visibleUser(username, viewerId)
+	if err != nil {
+		return
+	}
+
+	follows, nextCursor, err = f.followRepo.FindFollowers(user.ID, models.FollowStatusAccepted, page)
+	return
+}
+
+// GetFollowing returns the users followed by a user, with the same visibility as the followers
+func (f *FollowSvc) GetFollowing(username string, viewerId uint, page models.PageInput) (follows []models.Follow, nextCursor string, err error) {
+	user, err := f.visibleUser(username, viewerId)
+	if err != nil {
+		return
+	}
+
+	follows, nextCursor, err = f.followRepo.FindFollowing(user.ID, page)
+	return
+}
+
+// GetRequests returns the pending follow requests to a user
+func (f *FollowSvc) GetRequests(userId uint, page models.PageInput) (follows []models.Follow, nextCursor string, err error) {
+	follows, nextCursor, err = f.followRepo.FindFollowers(userId, models.FollowStatusPending, page)
+	return
+}
+
+func (f *FollowSvc) AcceptRequest(userId uint, followerId uint) (err error) {
+	if err = f.followRepo.Accept(followerId, userId); err != nil {
+		return
+	}
+
+	f.feedSvc.Followed(followerId, userId)
+	return
+}
+
+// DeclineRequest deletes a pending follow request, accepted followers aren't affected
+func (f *FollowSvc) DeclineRequest(userId uint, followerId uint) (err error) {
+	follow, err := f.followRepo.Find(followerId, userId)
+	if err != nil {
+		return
+	}
+	if follow.Status != models.FollowStatusPending {
+		err = gorm.ErrRecordNotFound
+		return
+	}
+
+	_, err = f.followRepo.Delete(followerId, userId)
+	return
+}
+
+// UpdatePrivacy makes an account private or public, making it public accepts the pending follow requests
+func (f *FollowSvc) UpdatePrivacy(userId uint, privacyInput models.UserPrivacyInput) (user models.User, err error) {
+	user = models.User{
+		Base:      models.Base{ID: userId},
+		IsPrivate: privacyInput.IsPrivate,
+	}
+	if err = f.userRepo.UpdatePrivacy(user); err != nil {
+		return
+	}
+
+	if !privacyInput.IsPrivate {
+		followerIds, err := f.followRepo.AcceptAll(userId)
+		if err != nil {
+			return user, err
+		}
+		for _, followerId := range followerIds {
+			f.feedSvc.Followed(followerId, userId)
+		}
+	}
+
+	user, err = f.userRepo.FindById(userId)
+	return
+}
+
+// visibleUser finds a user whose follows the viewer may see
+func (f *FollowSvc) visibleUser(username string, viewerId uint) (user models.User, err error) {
+	user, err = f.userRepo.FindByUsername(username)
+	if err != nil || !user.IsPrivate || user.ID == viewerId {
+		return
+	}
+
+	following, err := f.followRepo.IsFollowing(viewerId, user.ID)
+	if err == nil && !following {
+		err = ErrPrivateAccount
+	}
+	return
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/alvinmdj/mygram-api/caches"
+	"github.com/alvinmdj/mygram-api/configs"
+	"github.com/alvinmdj/mygram-api/helpers"
+	"github.com/alvinmdj/mygram-api/images"
+	"github.com/alvinmdj/mygram-api/models"
+	"github.com/alvinmdj/mygram-api/repositories"
+	"github.com/alvinmdj/mygram-api/storages"
+)
+
+type ImageSvcInterface interface {
+	Transform(photoId int, transformInput models.ImageTransformInput, params url.Values, ifNoneMatch string) (image TransformedImage, err error)
+	SignedURL(userId uint, role string, photoId int, transformInput models.ImageTransformInput) (imageUrl string, err error)
+}
+
+// TransformedImage is a photo transformed on the fly
+type TransformedImage struct {
+	ETag        string
+	NotModified bool // the client has the current version (If-None-Match), Data is empty
+	ContentType string
+	Data        []byte
+}
+
+// imagePresets are the only transformations other users than the owner & staff can request,
+// so the number of transformations (and cached images) of a photo stays bounded
+var imagePresets = []images.TransformOptions{
+	{Width: 150, Height: 150, Fit: images.FitCover},
+	{Width: 320, Height: 320, Fit: images.FitCover},
+	{Width: 640, Fit: images.FitContain},
+	{Width: 1080, Fit: images.FitContain},
+}
+
+type ImageSvc struct {
+	photoRepo    repositories.PhotoRepoInterface
+	storage      storages.Storage
+	cache        caches.Cache
+	uploadConfig configs.UploadConfig
+}
+
+func NewImageSvc(photoRepo repositories.PhotoRepoInterface, storage storages.Storage, cache caches.Cache, uploadConfig configs.UploadConfig) ImageSvcInterface {
+	return &ImageSvc{
+		photoRepo:    photoRepo,
+		storage:      storage,
+		cache:        cache,
+		uploadConfig: uploadConfig,
+	}
+}
+
+// Transform returns a resized/cropped/re-encoded photo, params are the signed query parameters,
+// nothing is transformed when ifNoneMatch is the etag of the current version
+func (i *ImageSvc) Transform(photoId int, transformInput models.ImageTransformInput, params url.Values, ifNoneMatch string) (image TransformedImage, err error) {
+	// the signature stops clients from requesting arbitrary (expensive) transformations
+	if !helpers.VerifyImageParams(uint(photoId), params) {
+		return image, ErrInvalidImageSignature
+	}
+
+	options, err := transformOptions(transformInput)
+	if err != nil {
+		return
+	}
+
+	photo, err := i.photoRepo.FindById(photoId)
+	if err != nil {
+		return
+	}
+
+	// the etag changes when the photo file is replaced
+	storageKey := photoStorageKey(photo)
+	sum := sha256.Sum256([]byte(storageKey + "?" + transformParams(options).Encode()))
+	image.ETag = `"` + hex.EncodeToString(sum[:16]) + `"`
+	if ifNoneMatch == image.ETag {
+		image.NotModified = true
+		return
+	}
+
+	data, ok := i.cache.Get(image.ETag)
+	if !ok {
+		data, err = i.transform(storageKey, options)
+		if err != nil {
+			return
+		}
+		i.cache.Set(image.ETag, data)
+	}
+
+	image.ContentType = http.DetectContentType(data)
+	image.Data = data
+	return
+}
+
+func (i *ImageSvc) transform(storageKey string, options images.TransformOptions) (data []byte, err error) {
+	original, err := i.storage.Get(context.Background(), storageKey)
+	if err != nil {
+		return
+	}
+	defer original.Close()
+
+	// originals were validated on upload, they aren't larger than the upload limit of any role
+	data, err = io.ReadAll(io.LimitReader(original, i.uploadConfig.MaxBytes()))
+	if err != nil {
+		return
+	}
+
+	data, err = images.Transform(data, options)
+	return
+}
+
+// SignedURL returns the url of a transformed photo, the owner & staff may request any transformation,
+// other users one of the presets
+func (i *ImageSvc) SignedURL(userId uint, role string, photoId int, transformInput models.ImageTransformInput) (imageUrl string, err error) {
+	options, err := transformOptions(transformInput)
+	if err != nil {
+		return
+	}
+
+	// photos of private accounts are only signed for their accepted followers
+	photo, err := i.photoRepo.FindVisibleById(photoId, userId, models.IsStaff(role))
+	if err != nil {
+		return
+	}
+
+	if photo.UserID != userId && !models.IsStaff(role) && !isImagePreset(options) {
+		err = ErrImagePresetRequired
+		return
+	}
+
+	params := transformParams(options)
+	params.Set("sig", helpers.SignImageParams(uint(photoId), params))
+
+	// example url: http://localhost:8080/img/1?fit=cover&h=300&w=300&sig=...
+	imageUrl = fmt.Sprintf("%v/img/%d?%v", appBaseUrl(), photoId, params.Encode())
+	return
+}
+
+// transformOptions validates the transformation input
+func transformOptions(transformInput models.ImageTransformInput) (options images.TransformOptions, err error) {
+	maxSize := helpers.GetEnvInt("IMAGE_TRANSFORM_MAX_SIZE", 2048)
+
+	if transformInput.Width < 0 || transformInput.Width > maxSize || transformInput.Height < 0 || transformInput.Height > maxSize {
+		return options, fmt.Errorf("w and h must be between 0 and %d", maxSize)
+	}
+	if transformInput.Width == 0 && transformInput.Height == 0 {
+		return options, errors.New("w or h is required")
+	}
+
+	options = images.TransformOptions{
+		Width:  transformInput.Width,
+		Height: transformInput.Height,
+		Fit:    transformInput.Fit,
+		Format: transformInput.Format,
+	}
+
+	switch options.Fit {
+	case "":
+		options.Fit = images.FitContain
+	case images.FitContain, images.FitCover:
+	default:
+		return options, errors.New("fit must be one of contain or cover")
+	}
+
+	switch options.Format {
+	case "", images.FormatJPEG, images.FormatPNG:
+	default:
+		return options, errors.New("fmt must be one of jpeg or png")
+	}
+	return
+}
+
+func isImagePreset(options images.TransformOptions) bool {
+	for _, preset := range imagePresets {
+		if options == preset {
+			return true
+		}
+	}
+	return false
+}
+
+// transformParams returns the canonical query parameters of transformation options
+func transformParams(options images.TransformOptions) url.Values {
+	params := url.Values{}
+	if options.Width > 0 {
+		params.Set("w", strconv.Itoa(options.Width))
+	}
+	if options.Height > 0 {
+		params.Set("h", strconv.Itoa(options.Height))
+	}
+	params.Set("fit", options.Fit)
+	if options.Format != "" {
+		params.Set("fmt", options.Format)
+	}
+	return params
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/alvinmdj/mygram-api/images"
+	"github.com/alvinmdj/mygram-api/models"
+)
+
+func TestTransformOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   models.ImageTransformInput
+		want    images.TransformOptions
+		wantErr string
+	}{
+		{
+			name:  "contain by default",
+			input: models.ImageTransformInput{Width: 640},
+			want:  images.TransformOptions{Width: 640, Fit: images.FitContain},
+		},
+		{
+			name:  "cover with format",
+			input: models.ImageTransformInput{Width: 150, Height: 150, Fit: "cover", Format: "png"},
+			want:  images.TransformOptions{Width: 150, Height: 150, Fit: images.FitCover, Format: images.FormatPNG},
+		},
+		{
+			name:    "size required",
+			input:   models.ImageTransformInput{},
+			wantErr: "w or h is required",
+		},
+		{
+			name:    "negative size",
+			input:   models.ImageTransformInput{Width: -1, Height: 100},
+			wantErr: "w and h must be between 0 and 2048",
+		},
+		{
+			name:    "size above the max",
+			input:   models.ImageTransformInput{Height: 2049},
+			wantErr: "w and h must be between 0 and 2048",
+		},
+		{
+			name:    "unknown fit",
+			input:   models.ImageTransformInput{Width: 100, Fit: "fill"},
+			wantErr: "fit must be one of contain or cover",
+		},
+		{
+			name:    "unknown format",
+			input:   models.ImageTransformInput{Width: 100, Format: "webp"},
+			wantErr: "fmt must be one of jpeg or png",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			options, err := transformOptions(test.input)
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					t.Errorf("transformOptions() error = %v, want %v", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil || options != test.want {
+				t.Errorf("transformOptions() = %+v, %v, want %+v", options, err, test.want)
+			}
+		})
+	}
+}
+
+func TestTransformOptionsMaxSize(t *testing.T) {
+	t.Setenv("IMAGE_TRANSFORM_MAX_SIZE", "100")
+
+	if _, err := transformOptions(models.ImageTransformInput{Width: 101}); err == nil {
+		t.Error("transformOptions() error = nil, want the configured max size to apply")
+	}
+}
+
+func TestImagePresets(t *testing.T) {
+	tests := []struct {
+		input  models.ImageTransformInput
+		preset bool
+	}{
+		{models.ImageTransformInput{Width: 150, Height: 150, Fit: "cover"}, true},
+		{models.ImageTransformInput{Width: 1080}, true},
+		{models.ImageTransformInput{Width: 1080, Fit: "contain"}, true},
+		{models.ImageTransformInput{Width: 1080, Format: "png"}, false},
+		{models.ImageTransformInput{Width: 150, Height: 150}, false},
+		{models.ImageTransformInput{Width: 151, Height: 150, Fit: "cover"}, false},
+	}
+
+	for _, test := range tests {
+		options, err := transformOptions(test.input)
+		if err != nil {
+			t.Fatalf("transformOptions(%+v) error = %v", test.input, err)
+		}
+		if got := isImagePreset(options); got != test.preset {
+			t.Errorf("isImagePreset(%+v) = %v, want %v", options, got, test.preset)
+		}
+	}
+}
+
+func TestTransformParams(t *testing.T) {
+	tests := []struct {
+		options images.TransformOptions
+		want    string
+	}{
+		{images.TransformOptions{Width: 640, Fit: images.FitContain}, "fit=contain&w=640"},
+		{images.TransformOptions{Width: 150, Height: 150, Fit: images.FitCover, Format: images.FormatJPEG}, "fit=cover&fmt=jpeg&h=150&w=150"},
+	}
+
+	for _, test := range tests {
+		if got := transformParams(test.options).Encode(); got != test.want {
+			t.Errorf("transformParams(%+v) = %v, want %v", test.options, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"github.com/alvinmdj/mygram-api/models"
+	"github.com/alvinmdj/mygram-api/repositories"
+)
+
+type LikeSvcInterface interface {
+	GetAll(photoId int, page models.PageInput) (likes []models.Like, nextCursor string, err error)
+	Like(photoId int, userId uint) (like models.LikeOutput, err error)
+	Unlike(photoId int, userId uint) (like models.LikeOutput, err error)
+	RepairCounts() (repaired int64, err error)
+}
+
+type LikeSvc struct {
+	likeRepo repositories.LikeRepoInterface
+}
+
+func NewLikeSvc(likeRepo repositories.LikeRepoInterface) LikeSvcInterface {
+	return &LikeSvc{
+		likeRepo: likeRepo,
+	}
+}
+
+func (l *LikeSvc) GetAll(photoId int, page models.PageInput) (likes []models.Like, nextCursor string, err error) {
+	likes, nextCursor, err = l.likeRepo.FindAll(photoId, page)
+	return
+}
+
+func (l *LikeSvc) Like(photoId int, userId uint) (like models.LikeOutput, err error) {
+	likeCount, err := l.likeRepo.Save(userId, uint(photoId))
+	if err != nil {
+		return
+	}
+
+	like = models.LikeOutput{
+		PhotoID:   uint(photoId),
+		LikeCount: likeCount,
+		LikedByMe: true,
+	}
+	return
+}
+
+func (l *LikeSvc) Unlike(photoId int, userId uint) (like models.LikeOutput, err error) {
+	likeCount, err := l.likeRepo.Delete(userId, uint(photoId))
+	if err != nil {
+		return
+	}
+
+	like = models.LikeOutput{
+		PhotoID:   uint(photoId),
+		LikeCount: likeCount,
+		LikedByMe: false,
+	}
+	return
+}
+
+// RepairCounts recomputes the denormalized like counts of the photos, e.g. after likes were changed by hand
+func (l *LikeSvc) RepairCounts() (repaired int64, err error) {
+	repaired, err = l.likeRepo.RepairCounts()
+	return
+}
@@ -1,127 +1,231 @@
 package services
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"log"
-	"mime/multipart"
+	"mime"
+	"net/url"
+	"path"
+	"strings"
+	"time"
 
+	"github.com/alvinmdj/mygram-api/configs"
 	"github.com/alvinmdj/mygram-api/helpers"
+	"github.com/alvinmdj/mygram-api/images"
 	"github.com/alvinmdj/mygram-api/models"
 	"github.com/alvinmdj/mygram-api/repositories"
+	"github.com/alvinmdj/mygram-api/storages"
 	"github.com/asaskevich/govalidator"
+	"github.com/google/uuid"
 )
 
 type PhotoSvcInterface interface {
-	GetAll() (photos []models.Photo, err error)
-	GetOneById(id int) (photo models.Photo, err error)
-	Create(photoInput models.PhotoCreateInput, photoFileHeader *multipart.FileHeader) (photo models.Photo, err error)
-	Update(photoInput models.PhotoUpdateInput, photoFileHeader *multipart.FileHeader) (photo models.Photo, err error)
-	Delete(id int) (err error)
+	GetAll(page models.PageInput, viewerId uint, includePrivate bool) (photos []models.Photo, nextCursor string, err error)
+	GetOneById(id int, viewerId uint, includePrivate bool) (photo models.Photo, err error)
+	Create(photoInput models.PhotoCreateInput, photoFile io.Reader) (photo models.Photo, err error)
+	Update(photoInput models.PhotoUpdateInput, photoFile io.Reader) (photo models.Photo, err error)
+	Delete(id int, userId uint) (err error)
+	Restore(id int, viewerId uint) (photo models.Photo, err error)
+	GetVersions(id int) (versions []models.PhotoVersion, err error)
+	Revert(id int, version int, viewerId uint) (photo models.Photo, err error)
+	UpdateSettings(settingsInput models.PhotoSettingsInput) (photo models.Photo, err error)
+	PresignUpload(userId uint, uploadInput models.PhotoUploadInput) (upload models.PhotoUploadOutput, err error)
+	CreateFromUpload(photoInput models.PhotoCreateInput, uploadKey string) (photo models.Photo, err error)
 }
 
 type PhotoSvc struct {
-	photoRepo repositories.PhotoRepoInterface
+	photoRepo         repositories.PhotoRepoInterface
+	userRepo          repositories.UserRepoInterface
+	likeRepo          repositories.LikeRepoInterface
+	feedSvc           FeedSvcInterface
+	storage           storages.Storage
+	storageCleanupSvc StorageCleanupSvcInterface
+	uploadConfig      configs.UploadConfig
+	versionLimit      int // how many previous versions of a photo are kept
 }
 
-func NewPhotoSvc(photoRepo repositories.PhotoRepoInterface) PhotoSvcInterface {
+func NewPhotoSvc(
+	photoRepo repositories.PhotoRepoInterface,
+	userRepo repositories.UserRepoInterface,
+	likeRepo repositories.LikeRepoInterface,
+	feedSvc FeedSvcInterface,
+	storage storages.Storage,
+	storageCleanupSvc StorageCleanupSvcInterface,
+	uploadConfig configs.UploadConfig,
+	versionLimit int,
+) PhotoSvcInterface {
 	return &PhotoSvc{
-		photoRepo: photoRepo,
+		photoRepo:         photoRepo,
+		userRepo:          userRepo,
+		likeRepo:          likeRepo,
+		feedSvc:           feedSvc,
+		storage:           storage,
+		storageCleanupSvc: storageCleanupSvc,
+		uploadConfig:      uploadConfig,
+		versionLimit:      versionLimit,
 	}
 }
 
-func (p *PhotoSvc) GetAll() (photos []models.Photo, err error) {
-	photos, err = p.photoRepo.FindAll()
+func (p *PhotoSvc) GetAll(page models.PageInput, viewerId uint, includePrivate bool) (photos []models.Photo, nextCursor string, err error) {
+	photos, nextCursor, err = p.photoRepo.FindAll(page, viewerId, includePrivate)
+	if err != nil {
+		return
+	}
+
+	err = p.setLikedByMe(viewerId, photos)
 	return
 }
 
-func (p *PhotoSvc) GetOneById(id int) (photo models.Photo, err error) {
-	photo, err = p.photoRepo.FindById(id)
+func (p *PhotoSvc) GetOneById(id int, viewerId uint, includePrivate bool) (photo models.Photo, err error) {
+	photo, err = p.photoRepo.FindVisibleById(id, viewerId, includePrivate)
+	if err != nil {
+		return
+	}
+
+	photos := []models.Photo{photo}
+	err = p.setLikedByMe(viewerId, photos)
+	return photos[0], err
+}
+
+// setLikedByMe sets whether the viewer liked the photos
+func (p *PhotoSvc) setLikedByMe(viewerId uint, photos []models.Photo) (err error) {
+	photoIds := make([]uint, 0, len(photos))
+	for _, photo := range photos {
+		photoIds = append(photoIds, photo.ID)
+	}
+
+	liked, err := p.likeRepo.FindLikedPhotoIds(viewerId, photoIds)
+	if err != nil {
+		return
+	}
+	for i := range photos {
+		photos[i].LikedByMe = liked[photos[i].ID]
+	}
 	return
 }
 
-func (p *PhotoSvc) Create(photoInput models.PhotoCreateInput, photoFileHeader *multipart.FileHeader) (photo models.Photo, err error) {
-	// validate other input before upload file to cloudinary
+func (p *PhotoSvc) Create(photoInput models.PhotoCreateInput, photoFile io.Reader) (photo models.Photo, err error) {
+	// validate other input before upload file to storage
 	photoInput.PhotoURL = "placeholder"
 	_, err = govalidator.ValidateStruct(photoInput)
 	if err != nil {
 		return
 	}
 
-	// open the file and get its content
-	photoFile, err := photoFileHeader.Open()
-	if err != nil {
-		log.Printf("error opening file: %v", err)
+	// don't process a file which can't fit into the quota anyway
+	limits := p.uploadConfig.For(photoInput.Role)
+	if err = p.checkQuota(photoInput.UserID, photoInput.FileSize, limits.StorageQuota); err != nil {
 		return
 	}
-	defer photoFile.Close()
 
-	// upload file to cloudinary
-	photoUrl, err := helpers.UploadToCloudinary(photoFile)
+	// upload file & its variants to storage
+	uploaded, err := p.upload(photoFile, limits)
 	if err != nil {
 		return
 	}
 
+	// the stored size is only known after processing, the files are removed again when over quota
+	if err = p.addStorageUsed(photoInput.UserID, uploaded.size(), limits.StorageQuota); err != nil {
+		p.storageCleanupSvc.DeleteFiles(uploaded.storageKeys())
+		return
+	}
+
 	photo = models.Photo{
-		Title:    photoInput.Title,
-		Caption:  photoInput.Caption,
-		UserID:   photoInput.UserID,
-		PhotoURL: photoUrl,
+		Title:      photoInput.Title,
+		Caption:    photoInput.Caption,
+		UserID:     photoInput.UserID,
+		PhotoURL:   p.storage.URL(uploaded.storageKey),
+		StorageKey: uploaded.storageKey,
+		FileSize:   uploaded.fileSize,
+		Variants:   uploaded.variants,
+	}
+	if photoInput.KeepMetadata {
+		setPhotoMetadata(&photo, uploaded.metadata)
 	}
 
 	photo, err = p.photoRepo.Save(photo)
+	if err != nil {
+		// don't leave the uploaded files behind
+		p.storageCleanupSvc.DeleteFiles(uploaded.storageKeys())
+		p.addStorageUsed(photoInput.UserID, -uploaded.size(), 0)
+		return
+	}
+
+	// add the photo to the home feeds of the followers
+	p.feedSvc.PhotoCreated(photo)
 	return
 }
 
-func (p *PhotoSvc) Update(photoInput models.PhotoUpdateInput, photoFileHeader *multipart.FileHeader) (photo models.Photo, err error) {
-	// get photo from db to get the photo URL for deletion
+// Update updates a photo, its previous state is saved as a new version. Replaced files are kept
+// with the version and count towards the quota of the owner until the version is pruned
+func (p *PhotoSvc) Update(photoInput models.PhotoUpdateInput, photoFile io.Reader) (photo models.Photo, err error) {
+	// get the current photo from db, it becomes the latest version
 	photo, err = p.photoRepo.FindById(int(photoInput.ID))
 	if err != nil {
 		return
 	}
+	version := newPhotoVersion(photo)
 
 	// if user uploaded a new photo
-	if photoFileHeader != nil {
-		// get the old photo for deletion
-		oldPhotoUrl := photo.PhotoURL
+	if photoFile != nil {
+		// the owner is charged, staff may replace the files of other users
+		ownerId, ownerLimits := photo.UserID, p.uploadConfig.For(photo.User.Role)
 
-		// validate other input before upload file to cloudinary
+		// validate other input before upload file to storage
 		photoInput.PhotoURL = "placeholder"
 		_, err = govalidator.ValidateStruct(photoInput)
 		if err != nil {
 			return
 		}
 
-		// open the file and get its content
-		photoFile, err := photoFileHeader.Open()
+		if err = p.checkQuota(ownerId, photoInput.FileSize, ownerLimits.StorageQuota); err != nil {
+			return
+		}
+
+		// upload new photo & its variants to storage
+		uploaded, err := p.upload(photoFile, p.uploadConfig.For(photoInput.Role))
 		if err != nil {
-			log.Printf("error opening file: %v", err)
 			return photo, err
 		}
-		defer photoFile.Close()
 
-		// upload new photo to cloudinary
-		photoUrl, err := helpers.UploadToCloudinary(photoFile)
-		if err != nil {
+		// the replaced files are kept with the version, the new files count against the quota
+		if err = p.addStorageUsed(ownerId, uploaded.size(), ownerLimits.StorageQuota); err != nil {
+			p.storageCleanupSvc.DeleteFiles(uploaded.storageKeys())
 			return photo, err
 		}
 
 		// set the photo model for db
 		photo = models.Photo{
-			Base:     models.Base{ID: photoInput.ID},
-			Title:    photoInput.Title,
-			Caption:  photoInput.Caption,
-			UserID:   photoInput.UserID,
-			PhotoURL: photoUrl, // new photo url
+			Base:       models.Base{ID: photoInput.ID},
+			Title:      photoInput.Title,
+			Caption:    photoInput.Caption,
+			UserID:     ownerId,
+			PhotoURL:   p.storage.URL(uploaded.storageKey), // new photo url
+			StorageKey: uploaded.storageKey,
+			FileSize:   uploaded.fileSize,
+			Variants:   uploaded.variants,
+			// settings aren't part of the update input, keep them
+			CommentPolicy: photo.CommentPolicy,
+		}
+		// metadata of the old file is replaced
+		if photoInput.KeepMetadata {
+			setPhotoMetadata(&photo, uploaded.metadata)
 		}
 
-		// update data in db
-		photo, err = p.photoRepo.Update(photo)
+		// update data in db, the variants of the old file are replaced
+		photo, err = p.photoRepo.UpdateWithVariants(photo, version)
 		if err != nil {
+			p.storageCleanupSvc.DeleteFiles(uploaded.storageKeys())
+			p.addStorageUsed(ownerId, -uploaded.size(), 0)
 			return photo, err
 		}
 
-		// delete old photo from cloudinary
-		err = helpers.DestroyFromCloudinary(oldPhotoUrl)
-		return photo, err
+		p.pruneVersions(photo.ID, ownerId)
+		return photo, nil
 	}
 
 	// if no new photo uploaded, use old photo url & overwrite the other data
@@ -130,27 +234,462 @@ func (p *PhotoSvc) Update(photoInput models.PhotoUpdateInput, photoFileHeader *m
 		Title:    photoInput.Title,
 		Caption:  photoInput.Caption,
 		PhotoURL: photo.PhotoURL, // old photo
-		UserID:   photoInput.UserID,
+		UserID:   photo.UserID,   // staff may update photos of other users, the owner stays
+		// keep the old file, its variants & its metadata
+		StorageKey:  version.StorageKey, // set for photos uploaded before storage keys existed, the version refers to it as well
+		FileSize:    photo.FileSize,
+		Variants:    photo.Variants,
+		TakenAt:     photo.TakenAt,
+		Orientation: photo.Orientation,
+		CameraModel: photo.CameraModel,
+		// settings aren't part of the update input, keep them
+		CommentPolicy: photo.CommentPolicy,
+	}
+
+	photo, err = p.photoRepo.Update(photo, version)
+	if err != nil {
+		return
 	}
 
-	photo, err = p.photoRepo.Update(photo)
+	p.pruneVersions(photo.ID, photo.UserID)
 	return
 }
 
-func (p *PhotoSvc) Delete(id int) (err error) {
-	// get photo from db to get the photo URL for deletion
+// GetVersions returns the previous versions of a photo, the latest version first
+func (p *PhotoSvc) GetVersions(id int) (versions []models.PhotoVersion, err error) {
+	if _, err = p.photoRepo.FindById(id); err != nil {
+		return
+	}
+
+	versions, err = p.photoRepo.FindVersions(id)
+	return
+}
+
+// Revert restores the title, caption, file & metadata of a previous version of a photo,
+// the current state is saved as a new version so a revert can be undone as well
+func (p *PhotoSvc) Revert(id int, version int, viewerId uint) (photo models.Photo, err error) {
+	current, err := p.photoRepo.FindById(id)
+	if err != nil {
+		return
+	}
+	photoVersion, err := p.photoRepo.FindVersion(id, version)
+	if err != nil {
+		return
+	}
+
+	// the files of the version are already stored & counted towards the quota
+	photo = models.Photo{
+		Base:        models.Base{ID: current.ID},
+		Title:       photoVersion.Title,
+		Caption:     photoVersion.Caption,
+		PhotoURL:    photoVersion.PhotoURL,
+		UserID:      current.UserID,
+		StorageKey:  photoVersion.StorageKey,
+		FileSize:    photoVersion.FileSize,
+		TakenAt:     photoVersion.TakenAt,
+		Orientation: photoVersion.Orientation,
+		CameraModel: photoVersion.CameraModel,
+		// settings aren't versioned, keep them
+		CommentPolicy: current.CommentPolicy,
+	}
+	for _, variant := range photoVersion.Variants {
+		photo.Variants = append(photo.Variants, models.PhotoVariant{
+			Size:       variant.Size,
+			Width:      variant.Width,
+			Height:     variant.Height,
+			StorageKey: variant.StorageKey,
+			URL:        variant.URL,
+			FileSize:   variant.FileSize,
+		})
+	}
+
+	if _, err = p.photoRepo.UpdateWithVariants(photo, newPhotoVersion(current)); err != nil {
+		return
+	}
+	p.pruneVersions(current.ID, current.UserID)
+
+	photo, err = p.GetOneById(id, viewerId, true)
+	return
+}
+
+// pruneVersions deletes the versions of a photo over the version limit, the files of the deleted versions
+// are deleted as well unless the photo or another version still refers to them (e.g. after a revert).
+// failures are only logged, the versions are pruned again on the next update
+func (p *PhotoSvc) pruneVersions(photoId uint, ownerId uint) {
+	pruned, err := p.photoRepo.PruneVersions(photoId, p.versionLimit)
+	if err != nil {
+		log.Printf("error pruning versions of photo %d: %v", photoId, err)
+		return
+	}
+
+	files := map[string]int64{}
+	for _, version := range pruned {
+		for storageKey, size := range versionStorageFiles(version) {
+			files[storageKey] = size
+		}
+	}
+
+	var storageKeys []string
+	var freed int64
+	for storageKey, size := range files {
+		if storageKey == "" {
+			continue
+		}
+		inUse, err := p.photoRepo.StorageKeyInUse(storageKey)
+		if err != nil {
+			log.Printf("error checking references of %s: %v", storageKey, err)
+			continue
+		}
+		if !inUse {
+			storageKeys = append(storageKeys, storageKey)
+			freed += size
+		}
+	}
+
+	// failed deletions are retried in the background
+	p.storageCleanupSvc.DeleteFiles(storageKeys)
+	if err = p.addStorageUsed(ownerId, -freed, 0); err != nil {
+		log.Printf("error freeing storage of user %d: %v", ownerId, err)
+	}
+}
+
+// Delete moves a photo to the trash, the photo & its files are deleted for good by the trash purge job
+// after the retention period, until then its files count towards the quota of the owner
+func (p *PhotoSvc) Delete(id int, userId uint) (err error) {
 	photo, err := p.photoRepo.FindById(id)
 	if err != nil {
 		return
 	}
 
-	// delete photo from cloudinary
-	err = helpers.DestroyFromCloudinary(photo.PhotoURL)
+	err = p.photoRepo.Delete(photo, userId)
+	return
+}
+
+// Restore moves a photo out of the trash
+func (p *PhotoSvc) Restore(id int, viewerId uint) (photo models.Photo, err error) {
+	if err = p.photoRepo.Restore(models.Photo{Base: models.Base{ID: uint(id)}}); err != nil {
+		return
+	}
+
+	photo, err = p.GetOneById(id, viewerId, true)
+	return
+}
+
+func (p *PhotoSvc) UpdateSettings(settingsInput models.PhotoSettingsInput) (photo models.Photo, err error) {
+	if _, err = govalidator.ValidateStruct(settingsInput); err != nil {
+		return
+	}
+
+	photo, err = p.photoRepo.FindById(int(settingsInput.ID))
+	if err != nil {
+		return
+	}
+
+	photo.CommentPolicy = settingsInput.CommentPolicy
+	err = p.photoRepo.UpdateCommentPolicy(photo)
+	return
+}
+
+// PresignUpload returns a presigned url to upload a photo file directly to the storage,
+// the file is staged under a key of the user until the photo is created with CreateFromUpload
+func (p *PhotoSvc) PresignUpload(userId uint, uploadInput models.PhotoUploadInput) (upload models.PhotoUploadOutput, err error) {
+	if _, err = govalidator.ValidateStruct(uploadInput); err != nil {
+		return
+	}
+	limits := p.uploadConfig.For(uploadInput.Role)
+	if err = checkUpload(limits, uploadInput.Size, uploadInput.ContentType); err != nil {
+		return
+	}
+
+	presigner, ok := p.storage.(storages.Presigner)
+	if !ok {
+		return upload, storages.ErrPresignNotSupported
+	}
+
+	// the extension of the validated content type is part of the key, e.g. staging/1/4f8e...-9c1d.jpg
+	uploadKey := fmt.Sprintf("%v%v%v", stagingPrefix(userId), uuid.New().String(), images.Ext(uploadInput.ContentType))
+	expires := helpers.GetEnvDuration("PRESIGNED_UPLOAD_TTL", 15*time.Minute)
+	presigned, err := presigner.PresignPut(context.Background(), uploadKey, uploadInput.ContentType, uploadInput.Size, expires)
+	if err != nil {
+		return
+	}
+
+	upload = models.PhotoUploadOutput{
+		UploadKey: uploadKey,
+		URL:       presigned.URL,
+		Method:    presigned.Method,
+		Headers:   presigned.Headers,
+		ExpiresAt: presigned.ExpiresAt,
+	}
+	return
+}
+
+// CreateFromUpload creates a photo from a file uploaded with a presigned url, the staged file
+// goes through the same pipeline as multipart uploads and is deleted once the photo is created
+func (p *PhotoSvc) CreateFromUpload(photoInput models.PhotoCreateInput, uploadKey string) (photo models.Photo, err error) {
+	// validate other input before reading the file
+	photoInput.PhotoURL = "placeholder"
+	if _, err = govalidator.ValidateStruct(photoInput); err != nil {
+		return
+	}
+
+	// users can only create photos from their own uploads
+	if !strings.HasPrefix(uploadKey, stagingPrefix(photoInput.UserID)) || strings.Contains(uploadKey, "..") {
+		return photo, ErrUploadNotFound
+	}
+
+	// check size & type before reading the file
+	info, err := p.storage.Stat(context.Background(), uploadKey)
+	if errors.Is(err, storages.ErrNotFound) {
+		return photo, ErrUploadNotFound
+	}
+	if err != nil {
+		return
+	}
+	// storages which don't keep the (signed) content type know it from the extension of the key,
+	// the content is checked anyway
+	contentType := info.ContentType
+	if contentType == "" || contentType == "application/octet-stream" {
+		contentType = mime.TypeByExtension(path.Ext(uploadKey))
+	}
+	limits := p.uploadConfig.For(photoInput.Role)
+	if err = checkUpload(limits, info.Size, contentType); err != nil {
+		p.storageCleanupSvc.DeleteFiles([]string{uploadKey})
+		return
+	}
+
+	photoFile, err := p.storage.Get(context.Background(), uploadKey)
 	if err != nil {
 		return
 	}
+	defer photoFile.Close()
 
-	// delete photo from db
-	err = p.photoRepo.Delete(photo)
+	photoInput.FileSize = info.Size
+	photo, err = p.Create(photoInput, photoFile)
+
+	// invalid files can't become a photo either, on other errors the client may retry
+	var imageErr *images.Error
+	if err == nil || errors.As(err, &imageErr) {
+		p.storageCleanupSvc.DeleteFiles([]string{uploadKey})
+	}
 	return
 }
+
+// checkUpload checks the announced size & type of a file before it is read
+func checkUpload(limits configs.UploadLimits, size int64, contentType string) error {
+	if size > limits.MaxBytes {
+		return &images.Error{Code: images.CodeTooLarge, Message: fmt.Sprintf("file is larger than %d bytes", limits.MaxBytes)}
+	}
+	if !limits.Allows(contentType) {
+		return &images.Error{Code: images.CodeUnsupportedType, Message: "file type must be one of " + strings.Join(limits.AllowedTypes, ", ")}
+	}
+	return nil
+}
+
+// stagingPrefix is the storage prefix of the direct uploads of a user
+func stagingPrefix(userId uint) string {
+	return fmt.Sprintf("%v%d/", storages.StagingPrefix, userId)
+}
+
+// uploadedPhoto is a photo file stored by upload
+type uploadedPhoto struct {
+	storageKey string
+	fileSize   int64
+	metadata   images.Metadata
+	variants   []models.PhotoVariant
+}
+
+// size returns the bytes of the stored file & its variants
+func (u uploadedPhoto) size() (size int64) {
+	size = u.fileSize
+	for _, variant := range u.variants {
+		size += variant.FileSize
+	}
+	return
+}
+
+func (u uploadedPhoto) storageKeys() (keys []string) {
+	keys = append(keys, u.storageKey)
+	for _, variant := range u.variants {
+		keys = append(keys, variant.StorageKey)
+	}
+	return
+}
+
+// upload validates an uploaded photo file, removes its metadata, generates its variants and stores
+// everything under a new random key, returns the stored files & the metadata fields which may be kept
+func (p *PhotoSvc) upload(photoFile io.Reader, limits configs.UploadLimits) (uploaded uploadedPhoto, err error) {
+	// the file name & content type sent by the client aren't trusted, the content is checked
+	data, info, err := images.Read(photoFile, limits.ImageLimits())
+	if err != nil {
+		log.Printf("invalid photo file: %v", err)
+		return
+	}
+	if !limits.Allows(info.ContentType) {
+		err = &images.Error{Code: images.CodeUnsupportedType, Message: "file type must be one of " + strings.Join(limits.AllowedTypes, ", ")}
+		return
+	}
+
+	// GPS coordinates, serial numbers, etc. must never be published, orientation is applied before stripping
+	data, info, uploaded.metadata, err = images.Process(data, info)
+	if err != nil {
+		log.Printf("error processing photo file: %v", err)
+		return
+	}
+
+	variants, err := images.Variants(data, info, images.VariantSizes)
+	if err != nil {
+		log.Printf("error generating photo variants: %v", err)
+		return
+	}
+
+	// example keys: photos/4f8e...-9c1d.png, photos/4f8e...-9c1d_640.png
+	name := "photos/" + uuid.New().String()
+	uploaded.storageKey = name + info.Ext
+	uploaded.fileSize = int64(len(data))
+	err = p.storage.Put(context.Background(), uploaded.storageKey, bytes.NewReader(data), int64(len(data)), info.ContentType)
+	if err != nil {
+		return
+	}
+
+	for _, variant := range variants {
+		storageKey := fmt.Sprintf("%v_%d%v", name, variant.Size, variant.Ext)
+		err = p.storage.Put(context.Background(), storageKey, bytes.NewReader(variant.Data), int64(len(variant.Data)), variant.ContentType)
+		if err != nil {
+			// don't leave the already stored files behind
+			p.storageCleanupSvc.DeleteFiles(uploaded.storageKeys())
+			return
+		}
+
+		uploaded.variants = append(uploaded.variants, models.PhotoVariant{
+			Size:       variant.Size,
+			Width:      variant.Width,
+			Height:     variant.Height,
+			StorageKey: storageKey,
+			URL:        p.storage.URL(storageKey),
+			FileSize:   int64(len(variant.Data)),
+		})
+	}
+	return
+}
+
+// addStorageUsed updates the storage used by a user, an increase over the quota fails with ErrQuotaExceeded
+func (p *PhotoSvc) addStorageUsed(userId uint, delta int64, quota int64) (err error) {
+	if delta == 0 {
+		return
+	}
+	ok, err := p.userRepo.AddStorageUsed(userId, delta, quota)
+	if err == nil && !ok && delta > 0 {
+		err = ErrQuotaExceeded
+	}
+	return
+}
+
+// checkQuota checks the announced size of a file against the quota of a user before the file is processed,
+// the stored size (with variants) is only known afterwards and checked again by addStorageUsed
+func (p *PhotoSvc) checkQuota(userId uint, size int64, defaultQuota int64) (err error) {
+	if size <= 0 {
+		return
+	}
+
+	user, err := p.userRepo.FindById(userId)
+	if err != nil {
+		return
+	}
+
+	quota := defaultQuota
+	if user.StorageQuota != nil {
+		quota = *user.StorageQuota
+	}
+	if user.StorageUsed+size > quota {
+		err = ErrQuotaExceeded
+	}
+	return
+}
+
+// photoStorageSize returns the bytes of the stored files of a photo, its variants & its loaded versions
+func photoStorageSize(photo models.Photo) (size int64) {
+	for _, fileSize := range photoStorageFiles(photo) {
+		size += fileSize
+	}
+	return
+}
+
+// setPhotoMetadata stores the metadata fields the user opted to keep
+func setPhotoMetadata(photo *models.Photo, metadata images.Metadata) {
+	photo.TakenAt = metadata.TakenAt
+	photo.Orientation = metadata.Orientation
+	photo.CameraModel = metadata.CameraModel
+}
+
+// photoStorageKeys returns the storage keys of a photo, its variants & its loaded versions
+func photoStorageKeys(photo models.Photo) (keys []string) {
+	for storageKey := range photoStorageFiles(photo) {
+		keys = append(keys, storageKey)
+	}
+	return
+}
+
+// photoStorageFiles returns the bytes of the stored files of a photo, its variants & its loaded versions
+// by storage key, files which versions share with the photo or with each other are listed once
+func photoStorageFiles(photo models.Photo) (files map[string]int64) {
+	files = map[string]int64{photoStorageKey(photo): photo.FileSize}
+	for _, variant := range photo.Variants {
+		files[variant.StorageKey] = variant.FileSize
+	}
+	for _, version := range photo.Versions {
+		for storageKey, size := range versionStorageFiles(version) {
+			files[storageKey] = size
+		}
+	}
+	return
+}
+
+// versionStorageFiles returns the bytes of the stored files of a photo version & its variants by storage key
+func versionStorageFiles(version models.PhotoVersion) (files map[string]int64) {
+	files = map[string]int64{version.StorageKey: version.FileSize}
+	for _, variant := range version.Variants {
+		files[variant.StorageKey] = variant.FileSize
+	}
+	return
+}
+
+// newPhotoVersion returns a version with the current state of a photo
+func newPhotoVersion(photo models.Photo) (version models.PhotoVersion) {
+	version = models.PhotoVersion{
+		Title:       photo.Title,
+		Caption:     photo.Caption,
+		PhotoURL:    photo.PhotoURL,
+		StorageKey:  photoStorageKey(photo),
+		FileSize:    photo.FileSize,
+		TakenAt:     photo.TakenAt,
+		Orientation: photo.Orientation,
+		CameraModel: photo.CameraModel,
+	}
+	for _, variant := range photo.Variants {
+		version.Variants = append(version.Variants, models.PhotoVersionVariant{
+			Size:       variant.Size,
+			Width:      variant.Width,
+			Height:     variant.Height,
+			StorageKey: variant.StorageKey,
+			FileSize:   variant.FileSize,
+			URL:        variant.URL,
+		})
+	}
+	return
+}
+
+// photoStorageKey returns the storage key of a photo,
+// photos uploaded before storage keys existed live in cloudinary under photos/<file-name>
+func photoStorageKey(photo models.Photo) string {
+	if photo.StorageKey != "" {
+		return photo.StorageKey
+	}
+
+	// example url: https://res.cloudinary.com/xxx/image/upload/yyy/photos/file-name.png
+	photoUrl, err := url.Parse(photo.PhotoURL)
+	if err != nil {
+		return ""
+	}
+	return "photos/" + path.Base(photoUrl.Path)
+}
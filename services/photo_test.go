@@ -0,0 +1,242 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/alvinmdj/mygram-api/configs"
+	"github.com/alvinmdj/mygram-api/images"
+	"github.com/alvinmdj/mygram-api/models"
+	"github.com/alvinmdj/mygram-api/repositories"
+	"gorm.io/gorm"
+)
+
+// fakePhotoRepo keeps the photos & their versions in memory, the other methods aren't used by the tests
+type fakePhotoRepo struct {
+	repositories.PhotoRepoInterface
+	photos   map[uint]models.Photo
+	versions map[uint][]models.PhotoVersion // oldest first
+}
+
+func newFakePhotoRepo(photos ...models.Photo) *fakePhotoRepo {
+	repo := &fakePhotoRepo{photos: map[uint]models.Photo{}, versions: map[uint][]models.PhotoVersion{}}
+	for _, photo := range photos {
+		repo.photos[photo.ID] = photo
+	}
+	return repo
+}
+
+func (f *fakePhotoRepo) FindById(id int) (models.Photo, error) {
+	photo, ok := f.photos[uint(id)]
+	if !ok || photo.DeletedAt.Valid {
+		return photo, gorm.ErrRecordNotFound
+	}
+	return photo, nil
+}
+
+func (f *fakePhotoRepo) Update(photo models.Photo, version models.PhotoVersion) (models.Photo, error) {
+	version.PhotoID = photo.ID
+	version.Version = 1
+	if versions := f.versions[photo.ID]; len(versions) > 0 {
+		version.Version = versions[len(versions)-1].Version + 1
+	}
+	f.versions[photo.ID] = append(f.versions[photo.ID], version)
+	f.photos[photo.ID] = photo
+	return photo, nil
+}
+
+func (f *fakePhotoRepo) UpdateWithVariants(photo models.Photo, version models.PhotoVersion) (models.Photo, error) {
+	return f.Update(photo, version)
+}
+
+func (f *fakePhotoRepo) FindVersion(photoId int, version int) (models.PhotoVersion, error) {
+	for _, photoVersion := range f.versions[uint(photoId)] {
+		if photoVersion.Version == version {
+			return photoVersion, nil
+		}
+	}
+	return models.PhotoVersion{}, gorm.ErrRecordNotFound
+}
+
+func (f *fakePhotoRepo) PruneVersions(photoId uint, keep int) (pruned []models.PhotoVersion, err error) {
+	versions := f.versions[photoId]
+	if len(versions) <= keep {
+		return
+	}
+	pruned = versions[:len(versions)-keep]
+	f.versions[photoId] = versions[len(versions)-keep:]
+	return
+}
+
+func (f *fakePhotoRepo) StorageKeyInUse(storageKey string) (bool, error) {
+	for _, photo := range f.photos {
+		if _, ok := photoStorageFiles(models.Photo{StorageKey: photo.StorageKey, Variants: photo.Variants, Versions: f.versions[photo.ID]})[storageKey]; ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// fakeStorageCleanupSvc records the deleted files
+type fakeStorageCleanupSvc struct {
+	StorageCleanupSvcInterface
+	deleted []string
+}
+
+func (f *fakeStorageCleanupSvc) DeleteFiles(storageKeys []string) {
+	f.deleted = append(f.deleted, storageKeys...)
+}
+
+func TestPhotoUpdateByStaffKeepsOwner(t *testing.T) {
+	photoRepo := newFakePhotoRepo(models.Photo{Base: models.Base{ID: 1}, Title: "title", Caption: "caption", PhotoURL: "url", StorageKey: "photo.jpg", UserID: 1})
+	photoSvc := &PhotoSvc{photoRepo: photoRepo, storageCleanupSvc: &fakeStorageCleanupSvc{}, versionLimit: 10}
+
+	// a moderator (user 9) edits the photo of user 1
+	photo, err := photoSvc.Update(models.PhotoUpdateInput{ID: 1, Title: "new title", Caption: "new caption", UserID: 9, Role: models.RoleModerator}, nil)
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if photo.UserID != 1 || photoRepo.photos[1].UserID != 1 {
+		t.Errorf("Update() by staff returned owner %d, stored owner %d, want 1", photo.UserID, photoRepo.photos[1].UserID)
+	}
+	if photo.Title != "new title" {
+		t.Errorf("Update() title = %v", photo.Title)
+	}
+}
+
+// versionNumbers returns the numbers of the kept versions of a photo, oldest first
+func (f *fakePhotoRepo) versionNumbers(photoId uint) (numbers []int) {
+	for _, version := range f.versions[photoId] {
+		numbers = append(numbers, version.Version)
+	}
+	return
+}
+
+func TestPhotoUpdateVersionLimit(t *testing.T) {
+	photoRepo := newFakePhotoRepo(models.Photo{Base: models.Base{ID: 1}, Title: "title", Caption: "caption", PhotoURL: "url", StorageKey: "photo.jpg", FileSize: 100, UserID: 1})
+	userRepo := &fakeUserRepo{users: map[uint]models.User{1: {Base: models.Base{ID: 1}, StorageUsed: 100}}}
+	storageCleanupSvc := &fakeStorageCleanupSvc{}
+	photoSvc := &PhotoSvc{photoRepo: photoRepo, userRepo: userRepo, storageCleanupSvc: storageCleanupSvc, versionLimit: 3}
+
+	for i := 1; i <= 5; i++ {
+		title := fmt.Sprintf("title %d", i)
+		if _, err := photoSvc.Update(models.PhotoUpdateInput{ID: 1, Title: title, Caption: "caption", UserID: 1}, nil); err != nil {
+			t.Fatalf("Update() %d error = %v", i, err)
+		}
+	}
+
+	// every update adds a version, only the latest ones are kept & their numbers aren't reused
+	if got := photoRepo.versionNumbers(1); fmt.Sprint(got) != "[3 4 5]" {
+		t.Errorf("versions after 5 updates = %v, want [3 4 5]", got)
+	}
+	if title := photoRepo.versions[1][0].Title; title != "title 2" {
+		t.Errorf("oldest kept version title = %v, want title 2", title)
+	}
+
+	// the pruned versions refer to the file of the photo, which stays
+	if len(storageCleanupSvc.deleted) != 0 {
+		t.Errorf("files deleted by pruning = %v, want none", storageCleanupSvc.deleted)
+	}
+	if used := userRepo.users[1].StorageUsed; used != 100 {
+		t.Errorf("storage used = %d, want 100", used)
+	}
+}
+
+func TestPhotoRevert(t *testing.T) {
+	photoRepo := newFakePhotoRepo(models.Photo{
+		Base: models.Base{ID: 1}, Title: "third", Caption: "caption", StorageKey: "v3.jpg", FileSize: 100, UserID: 1,
+		Variants: []models.PhotoVariant{{Size: 150, StorageKey: "v3_150.jpg", FileSize: 10}},
+	})
+	photoRepo.versions[1] = []models.PhotoVersion{
+		{PhotoID: 1, Version: 1, Title: "first", Caption: "caption", StorageKey: "v1.jpg", FileSize: 50},
+		{
+			PhotoID: 1, Version: 2, Title: "second", Caption: "caption", StorageKey: "v2.jpg", FileSize: 70,
+			Variants: []models.PhotoVersionVariant{{Size: 150, StorageKey: "v2_150.jpg", FileSize: 7}},
+		},
+	}
+	userRepo := &fakeUserRepo{users: map[uint]models.User{1: {Base: models.Base{ID: 1}, StorageUsed: 237}}}
+	storageCleanupSvc := &fakeStorageCleanupSvc{}
+	photoSvc := &PhotoSvc{
+		photoRepo: photoRepo, userRepo: userRepo, likeRepo: &fakeLikeRepo{},
+		storageCleanupSvc: storageCleanupSvc, versionLimit: 2,
+	}
+
+	photo, err := photoSvc.Revert(1, 2, 1)
+	if err != nil {
+		t.Fatalf("Revert() error = %v", err)
+	}
+	if photo.Title != "second" || photo.StorageKey != "v2.jpg" || len(photo.Variants) != 1 || photo.Variants[0].StorageKey != "v2_150.jpg" {
+		t.Errorf("Revert() = %+v, want the title, file & variants of version 2", photo)
+	}
+
+	// the current state becomes version 3, version 1 is over the limit & its file isn't used anymore
+	if got := photoRepo.versionNumbers(1); fmt.Sprint(got) != "[2 3]" {
+		t.Errorf("versions after Revert() = %v, want [2 3]", got)
+	}
+	if version := photoRepo.versions[1][1]; version.StorageKey != "v3.jpg" || len(version.Variants) != 1 {
+		t.Errorf("version 3 = %+v, want the file & variants of the reverted photo", version)
+	}
+	if got := storageCleanupSvc.deleted; len(got) != 1 || got[0] != "v1.jpg" {
+		t.Errorf("files deleted by pruning = %v, want [v1.jpg]", got)
+	}
+	if used := userRepo.users[1].StorageUsed; used != 187 {
+		t.Errorf("storage used after Revert() = %d, want 187", used)
+	}
+
+	// undo the revert, version 2 is pruned but the new version 4 still refers to its files
+	storageCleanupSvc.deleted = nil
+	if photo, err = photoSvc.Revert(1, 3, 1); err != nil {
+		t.Fatalf("Revert() error = %v", err)
+	}
+	if photo.Title != "third" || photo.StorageKey != "v3.jpg" {
+		t.Errorf("Revert() = %+v, want the title & file of version 3", photo)
+	}
+	if got := photoRepo.versionNumbers(1); fmt.Sprint(got) != "[3 4]" {
+		t.Errorf("versions after the second Revert() = %v, want [3 4]", got)
+	}
+	if len(storageCleanupSvc.deleted) != 0 {
+		t.Errorf("files deleted by pruning = %v, want none", storageCleanupSvc.deleted)
+	}
+	if used := userRepo.users[1].StorageUsed; used != 187 {
+		t.Errorf("storage used after the second Revert() = %d, want 187", used)
+	}
+
+	if _, err = photoSvc.Revert(1, 1, 1); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Revert() to a pruned version error = %v, want %v", err, gorm.ErrRecordNotFound)
+	}
+}
+
+func TestCheckUpload(t *testing.T) {
+	limits := configs.UploadLimits{MaxBytes: 1000, AllowedTypes: []string{"image/jpeg", "image/png"}}
+
+	tests := []struct {
+		name        string
+		size        int64
+		contentType string
+		code        string // expected images.Error code, empty if the upload is allowed
+	}{
+		{"allowed", 1000, "image/jpeg", ""},
+		{"too large", 1001, "image/jpeg", images.CodeTooLarge},
+		{"type not allowed", 10, "image/webp", images.CodeUnsupportedType},
+		{"not an image", 10, "text/html", images.CodeUnsupportedType},
+		{"type missing", 10, "", images.CodeUnsupportedType},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := checkUpload(limits, test.size, test.contentType)
+			if test.code == "" {
+				if err != nil {
+					t.Errorf("checkUpload() error = %v", err)
+				}
+				return
+			}
+
+			var imageErr *images.Error
+			if !errors.As(err, &imageErr) || imageErr.Code != test.code {
+				t.Errorf("checkUpload() error = %v, want code %v", err, test.code)
+			}
+		})
+	}
+}
@@ -6,7 +6,7 @@ import (
 )
 
 type SocialMediaSvcInterface interface {
-	GetAll() (socialMedias []models.SocialMedia, err error)
+	GetAll(page models.PageInput) (socialMedias []models.SocialMedia, nextCursor string, err error)
 	GetOneById(id int) (socialMedia models.SocialMedia, err error)
 	Create(socialMediaInput models.SocialMediaCreateInput) (socialMedia models.SocialMedia, err error)
 	Update(socialMediaInput models.SocialMediaUpdateInput) (socialMedia models.SocialMedia, err error)
@@ -23,8 +23,8 @@ func NewSocialMediaSvc(socialMediaRepo repositories.SocialMediaRepoInterface) So
 	}
 }
 
-func (s *SocialMediaSvc) GetAll() (socialMedias []models.SocialMedia, err error) {
-	socialMedias, err = s.socialMediaRepo.FindAll()
+func (s *SocialMediaSvc) GetAll(page models.PageInput) (socialMedias []models.SocialMedia, nextCursor string, err error) {
+	socialMedias, nextCursor, err = s.socialMediaRepo.FindAll(page)
 	return
 }
 
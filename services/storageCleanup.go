@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/alvinmdj/mygram-api/repositories"
+	"github.com/alvinmdj/mygram-api/storages"
+)
+
+type StorageCleanupSvcInterface interface {
+	DeleteFiles(storageKeys []string)
+	ProcessPending() (deleted int, err error)
+	StartWorker(interval time.Duration)
+	Reconcile(prefixes []string, olderThan time.Duration, dryRun bool) (orphans []storages.ObjectInfo, err error)
+}
+
+// StorageCleanupSvc keeps the storage in sync with the database: files which can't be deleted right away
+// are queued and retried, files no photo refers to anymore can be found and deleted with Reconcile
+type StorageCleanupSvc struct {
+	pendingDeletionRepo repositories.PendingDeletionRepoInterface
+	photoRepo           repositories.PhotoRepoInterface
+	storage             storages.Storage
+}
+
+func NewStorageCleanupSvc(
+	pendingDeletionRepo repositories.PendingDeletionRepoInterface,
+	photoRepo repositories.PhotoRepoInterface,
+	storage storages.Storage,
+) StorageCleanupSvcInterface {
+	return &StorageCleanupSvc{
+		pendingDeletionRepo: pendingDeletionRepo,
+		photoRepo:           photoRepo,
+		storage:             storage,
+	}
+}
+
+// how many queued deletions are processed per run, and the longest delay between two attempts
+const (
+	pendingDeletionBatch    = 100
+	pendingDeletionMaxDelay = 6 * time.Hour
+)
+
+// DeleteFiles deletes files from storage, the files which fail to delete are queued for the worker.
+// callers go on either way, the database is the source of truth
+func (s *StorageCleanupSvc) DeleteFiles(storageKeys []string) {
+	var failed []string
+	for _, storageKey := range storageKeys {
+		if storageKey == "" {
+			continue
+		}
+		if err := s.storage.Delete(context.Background(), storageKey); err != nil && !errors.Is(err, storages.ErrNotFound) {
+			failed = append(failed, storageKey)
+		}
+	}
+
+	// nothing is lost if queueing fails as well, Reconcile finds the files
+	if err := s.pendingDeletionRepo.Save(failed); err != nil {
+		log.Printf("error queueing deletion of %v: %v", failed, err)
+	}
+}
+
+// ProcessPending retries the queued deletions which are due, failures are retried with an exponential backoff
+func (s *StorageCleanupSvc) ProcessPending() (deleted int, err error) {
+	now := time.Now()
+	deletions, err := s.pendingDeletionRepo.FindDue(now, pendingDeletionBatch)
+	if err != nil {
+		return
+	}
+
+	for _, deletion := range deletions {
+		// a queued file may be referenced again (e.g. restored), it must not be deleted then
+		inUse, err := s.photoRepo.StorageKeyInUse(deletion.StorageKey)
+		if err != nil {
+			return deleted, err
+		}
+
+		if !inUse {
+			err = s.storage.Delete(context.Background(), deletion.StorageKey)
+		}
+		if err != nil && !errors.Is(err, storages.ErrNotFound) {
+			deletion.Attempts++
+			deletion.NextAttemptAt = now.Add(retryDelay(deletion.Attempts))
+			deletion.LastError = err.Error()
+			if err := s.pendingDeletionRepo.UpdateAttempt(deletion); err != nil {
+				return deleted, err
+			}
+			continue
+		}
+
+		if err := s.pendingDeletionRepo.Delete(deletion); err != nil {
+			return deleted, err
+		}
+		if !inUse {
+			deleted++
+		}
+	}
+	return
+}
+
+// StartWorker processes the queued deletions every interval in the background
+func (s *StorageCleanupSvc) StartWorker(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			deleted, err := s.ProcessPending()
+			if err != nil {
+				log.Printf("error processing pending deletions: %v", err)
+			}
+			if deleted > 0 {
+				log.Printf("deleted %d pending files from storage", deleted)
+			}
+		}
+	}()
+}
+
+// Reconcile lists the stored files under the prefixes and deletes the ones no photo refers to,
+// files younger than olderThan are skipped as their photo may still be in the making
+func (s *StorageCleanupSvc) Reconcile(prefixes []string, olderThan time.Duration, dryRun bool) (orphans []storages.ObjectInfo, err error) {
+	photos, err := s.photoRepo.FindStorageRefs()
+	if err != nil {
+		return
+	}
+	referenced := map[string]bool{}
+	for _, photo := range photos {
+		for _, storageKey := range photoStorageKeys(photo) {
+			referenced[storageKey] = true
+		}
+	}
+
+	before := time.Now().Add(-olderThan)
+	for _, prefix := range prefixes {
+		err = s.storage.List(context.Background(), strings.TrimSpace(prefix), func(info storages.ObjectInfo) error {
+			if !referenced[info.Key] && info.LastModified.Before(before) {
+				orphans = append(orphans, info)
+			}
+			return nil
+		})
+		if err != nil {
+			return
+		}
+	}
+
+	if dryRun {
+		return
+	}
+
+	storageKeys := make([]string, 0, len(orphans))
+	for _, orphan := range orphans {
+		storageKeys = append(storageKeys, orphan.Key)
+	}
+	s.DeleteFiles(storageKeys)
+	return
+}
+
+// retryDelay doubles the delay after every failed attempt: 1m, 2m, 4m, ... up to pendingDeletionMaxDelay
+func retryDelay(attempts int) time.Duration {
+	delay := time.Minute
+	for i := 1; i < attempts && delay < pendingDeletionMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > pendingDeletionMaxDelay {
+		delay = pendingDeletionMaxDelay
+	}
+	return delay
+}
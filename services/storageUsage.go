@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/alvinmdj/mygram-api/repositories"
+	"github.com/alvinmdj/mygram-api/storages"
+)
+
+type StorageUsageSvcInterface interface {
+	Backfill() (photos int, users int, err error)
+}
+
+// StorageUsageSvc fills in the storage usage of photos stored before file sizes were recorded
+type StorageUsageSvc struct {
+	photoRepo repositories.PhotoRepoInterface
+	userRepo  repositories.UserRepoInterface
+	storage   storages.Storage
+}
+
+func NewStorageUsageSvc(
+	photoRepo repositories.PhotoRepoInterface,
+	userRepo repositories.UserRepoInterface,
+	storage storages.Storage,
+) StorageUsageSvcInterface {
+	return &StorageUsageSvc{
+		photoRepo: photoRepo,
+		userRepo:  userRepo,
+		storage:   storage,
+	}
+}
+
+// Backfill stores the missing file sizes of photos, variants & versions (read from the storage)
+// and recomputes the storage used by every user, trashed photos count until they are purged
+func (s *StorageUsageSvc) Backfill() (photos int, users int, err error) {
+	refs, err := s.photoRepo.FindStorageRefs()
+	if err != nil {
+		return
+	}
+
+	// versions share files with their photo, every file is looked up once
+	sizes := map[string]int64{}
+	used := map[uint]int64{}
+	for _, photo := range refs {
+		filled := false
+		fill := func(storageKey string, fileSize *int64) {
+			if *fileSize > 0 || err != nil {
+				return
+			}
+			size, ok := sizes[storageKey]
+			if !ok {
+				size, err = s.fileSize(storageKey)
+				sizes[storageKey] = size
+			}
+			if size > 0 {
+				*fileSize = size
+				filled = true
+			}
+		}
+
+		fill(photoStorageKey(photo), &photo.FileSize)
+		for i := range photo.Variants {
+			fill(photo.Variants[i].StorageKey, &photo.Variants[i].FileSize)
+		}
+		for i := range photo.Versions {
+			fill(photo.Versions[i].StorageKey, &photo.Versions[i].FileSize)
+			for j := range photo.Versions[i].Variants {
+				fill(photo.Versions[i].Variants[j].StorageKey, &photo.Versions[i].Variants[j].FileSize)
+			}
+		}
+		if err != nil {
+			return
+		}
+
+		if filled {
+			if err = s.photoRepo.UpdateFileSizes(photo); err != nil {
+				return
+			}
+			photos++
+		}
+		used[photo.UserID] += photoStorageSize(photo)
+	}
+
+	err = s.userRepo.SetStorageUsed(used)
+	return photos, len(used), err
+}
+
+// fileSize returns the size of a stored file, missing files don't use any storage
+func (s *StorageUsageSvc) fileSize(storageKey string) (size int64, err error) {
+	info, err := s.storage.Stat(context.Background(), storageKey)
+	if errors.Is(err, storages.ErrNotFound) {
+		log.Printf("stored file '%v' doesn't exist", storageKey)
+		return 0, nil
+	}
+	return info.Size, err
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"log"
+	"time"
+
+	"github.com/alvinmdj/mygram-api/models"
+	"github.com/alvinmdj/mygram-api/repositories"
+)
+
+type TrashSvcInterface interface {
+	GetTrash(userId uint) (trash models.TrashOutput, err error)
+	PurgeExpired() (photos int, comments int64, err error)
+	StartPurgeJob(interval time.Duration)
+}
+
+// TrashSvc lists the deleted (trashed) photos & comments of users and deletes them for good after the retention period
+type TrashSvc struct {
+	photoRepo         repositories.PhotoRepoInterface
+	commentRepo       repositories.CommentRepoInterface
+	userRepo          repositories.UserRepoInterface
+	storageCleanupSvc StorageCleanupSvcInterface
+	retention         time.Duration
+}
+
+func NewTrashSvc(
+	photoRepo repositories.PhotoRepoInterface,
+	commentRepo repositories.CommentRepoInterface,
+	userRepo repositories.UserRepoInterface,
+	storageCleanupSvc StorageCleanupSvcInterface,
+	retention time.Duration,
+) TrashSvcInterface {
+	return &TrashSvc{
+		photoRepo:         photoRepo,
+		commentRepo:       commentRepo,
+		userRepo:          userRepo,
+		storageCleanupSvc: storageCleanupSvc,
+		retention:         retention,
+	}
+}
+
+// how many expired photos are purged per query
+const trashPurgeBatch = 100
+
+func (t *TrashSvc) GetTrash(userId uint) (trash models.TrashOutput, err error) {
+	photos, err := t.photoRepo.FindTrashed(userId)
+	if err != nil {
+		return
+	}
+	comments, err := t.commentRepo.FindTrashed(userId)
+	if err != nil {
+		return
+	}
+
+	trash.Photos = []models.TrashPhotoOutput{}
+	for _, photo := range photos {
+		trash.Photos = append(trash.Photos, models.TrashPhotoOutput{
+			Base:      photo.Base,
+			Title:     photo.Title,
+			Caption:   photo.Caption,
+			PhotoURL:  photo.PhotoURL,
+			DeletedAt: photo.DeletedAt.Time,
+			PurgeAt:   photo.DeletedAt.Time.Add(t.retention),
+		})
+	}
+	trash.Comments = []models.TrashCommentOutput{}
+	for _, comment := range comments {
+		trash.Comments = append(trash.Comments, models.TrashCommentOutput{
+			Base:      comment.Base,
+			Message:   comment.Message,
+			PhotoID:   comment.PhotoID,
+			DeletedAt: comment.DeletedAt.Time,
+			PurgeAt:   comment.DeletedAt.Time.Add(t.retention),
+		})
+	}
+	return
+}
+
+// PurgeExpired deletes the photos & comments which are in the trash for longer than the retention period,
+// including the stored files of the photos, and frees up the storage quota of their owners
+func (t *TrashSvc) PurgeExpired() (photos int, comments int64, err error) {
+	before := time.Now().Add(-t.retention)
+
+	for {
+		expired, err := t.photoRepo.FindDeletedBefore(before, trashPurgeBatch)
+		if err != nil {
+			return photos, comments, err
+		}
+
+		for _, photo := range expired {
+			if err = t.photoRepo.Purge(photo); err != nil {
+				return photos, comments, err
+			}
+			photos++
+
+			// the row is gone, failed deletions of the files are retried in the background
+			t.storageCleanupSvc.DeleteFiles(photoStorageKeys(photo))
+			if _, err = t.userRepo.AddStorageUsed(photo.UserID, -photoStorageSize(photo), 0); err != nil {
+				log.Printf("error freeing storage of user %d: %v", photo.UserID, err)
+			}
+		}
+
+		if len(expired) < trashPurgeBatch {
+			break
+		}
+	}
+
+	comments, err = t.commentRepo.PurgeDeletedBefore(before)
+	return
+}
+
+// StartPurgeJob purges the expired trash every interval in the background
+func (t *TrashSvc) StartPurgeJob(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			photos, comments, err := t.PurgeExpired()
+			if err != nil {
+				log.Printf("error purging trash: %v", err)
+			}
+			if photos > 0 || comments > 0 {
+				log.Printf("purged %d photos and %d comments from the trash", photos, comments)
+			}
+		}
+	}()
+}
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/alvinmdj/mygram-api/models"
+	"github.com/alvinmdj/mygram-api/policies"
+	"github.com/alvinmdj/mygram-api/repositories"
+	"gorm.io/gorm"
+)
+
+func (f *fakePhotoRepo) FindVisibleById(id int, viewerId uint, includePrivate bool) (models.Photo, error) {
+	return f.FindById(id)
+}
+
+func (f *fakePhotoRepo) Delete(photo models.Photo, deletedBy uint) error {
+	photo.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	photo.DeletedBy = &deletedBy
+	f.photos[photo.ID] = photo
+	return nil
+}
+
+func (f *fakePhotoRepo) Restore(photo models.Photo) error {
+	stored, ok := f.photos[photo.ID]
+	if !ok || !stored.DeletedAt.Valid {
+		return gorm.ErrRecordNotFound
+	}
+	stored.DeletedAt, stored.DeletedBy = gorm.DeletedAt{}, nil
+	f.photos[photo.ID] = stored
+	return nil
+}
+
+func (f *fakePhotoRepo) FindTrashed(userId uint) (photos []models.Photo, err error) {
+	for _, photo := range f.photos {
+		if photo.UserID == userId && photo.DeletedAt.Valid {
+			photos = append(photos, photo)
+		}
+	}
+	sort.Slice(photos, func(i, j int) bool { return photos[i].DeletedAt.Time.After(photos[j].DeletedAt.Time) })
+	return
+}
+
+func (f *fakePhotoRepo) FindDeletedBefore(before time.Time, limit int) (photos []models.Photo, err error) {
+	for _, photo := range f.photos {
+		if photo.DeletedAt.Valid && photo.DeletedAt.Time.Before(before) && len(photos) < limit {
+			photos = append(photos, photo)
+		}
+	}
+	return
+}
+
+func (f *fakePhotoRepo) Purge(photo models.Photo) error {
+	delete(f.photos, photo.ID)
+	delete(f.versions, photo.ID)
+	return nil
+}
+
+func (f *fakeCommentRepo) FindTrashed(userId uint) (comments []models.Comment, err error) {
+	for _, comment := range f.comments {
+		if comment.UserID == userId && comment.DeletedAt.Valid {
+			comments = append(comments, comment)
+		}
+	}
+	return
+}
+
+func (f *fakeCommentRepo) PurgeDeletedBefore(before time.Time) (purged int64, err error) {
+	for id, comment := range f.comments {
+		if comment.DeletedAt.Valid && comment.DeletedAt.Time.Before(before) {
+			delete(f.comments, id)
+			purged++
+		}
+	}
+	return
+}
+
+// fakeLikeRepo has no likes
+type fakeLikeRepo struct {
+	repositories.LikeRepoInterface
+}
+
+func (f *fakeLikeRepo) FindLikedPhotoIds(userId uint, photoIds []uint) (map[uint]bool, error) {
+	return map[uint]bool{}, nil
+}
+
+// photoRestorePolicy is the policy of the photo restore route
+var photoRestorePolicy = policies.New(
+	policies.ResourcePhoto, policies.ActionRestore,
+	policies.Deleter(), policies.Role(models.RoleModerator, models.RoleAdmin),
+)
+
+func TestPhotoRestore(t *testing.T) {
+	const owner, other, moderator, admin = 1, 2, 3, 4
+
+	tests := []struct {
+		name      string
+		deletedBy uint
+		subject   policies.Subject
+		allowed   bool
+	}{
+		{"owner restores their photo", owner, policies.Subject{UserID: owner, Role: models.RoleUser}, true},
+		{"other user", owner, policies.Subject{UserID: other, Role: models.RoleUser}, false},
+		{"staff restores a photo the owner trashed", owner, policies.Subject{UserID: moderator, Role: models.RoleModerator}, true},
+		{"owner, trashed by a moderator", moderator, policies.Subject{UserID: owner, Role: models.RoleUser}, false},
+		{"moderator restores a photo they trashed", moderator, policies.Subject{UserID: moderator, Role: models.RoleModerator}, true},
+		{"admin, trashed by a moderator", moderator, policies.Subject{UserID: admin, Role: models.RoleAdmin}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			photoRepo := newFakePhotoRepo(models.Photo{Base: models.Base{ID: 1}, Title: "title", StorageKey: "photo.jpg", UserID: owner})
+			photoSvc := &PhotoSvc{photoRepo: photoRepo, likeRepo: &fakeLikeRepo{}}
+
+			if err := photoSvc.Delete(1, test.deletedBy); err != nil {
+				t.Fatalf("Delete() error = %v", err)
+			}
+
+			// loaded like the authorization middleware does
+			trashed := photoRepo.photos[1]
+			resource := policies.Resource{Type: policies.ResourcePhoto, ID: trashed.ID, OwnerID: trashed.UserID, DeletedBy: *trashed.DeletedBy}
+			decision := photoRestorePolicy.Evaluate(test.subject, resource)
+			if decision.Allowed != test.allowed {
+				t.Fatalf("Evaluate() = %+v, want allowed %v", decision, test.allowed)
+			}
+			if !decision.Allowed {
+				return
+			}
+
+			photo, err := photoSvc.Restore(1, test.subject.UserID)
+			if err != nil {
+				t.Fatalf("Restore() error = %v", err)
+			}
+			if photo.DeletedAt.Valid || photo.DeletedBy != nil || photo.UserID != owner {
+				t.Errorf("Restore() = %+v, want the photo of user %d out of the trash", photo, owner)
+			}
+			if _, err := photoSvc.Restore(1, test.subject.UserID); !errors.Is(err, gorm.ErrRecordNotFound) {
+				t.Errorf("Restore() of a photo which isn't in the trash error = %v, want %v", err, gorm.ErrRecordNotFound)
+			}
+		})
+	}
+}
+
+func TestTrashRetention(t *testing.T) {
+	const retention = 30 * 24 * time.Hour
+	now := time.Now()
+	expiredAt, recentAt := now.Add(-retention-time.Hour), now.Add(-time.Hour)
+
+	photoRepo := newFakePhotoRepo(
+		models.Photo{
+			Base: models.Base{ID: 1}, StorageKey: "expired.jpg", FileSize: 100, UserID: 1,
+			Variants:  []models.PhotoVariant{{StorageKey: "expired_150.jpg", FileSize: 10}},
+			DeletedAt: gorm.DeletedAt{Time: expiredAt, Valid: true},
+		},
+		models.Photo{
+			Base: models.Base{ID: 2}, StorageKey: "recent.jpg", FileSize: 200, UserID: 1,
+			DeletedAt: gorm.DeletedAt{Time: recentAt, Valid: true},
+		},
+		models.Photo{Base: models.Base{ID: 3}, StorageKey: "kept.jpg", FileSize: 300, UserID: 1},
+	)
+	commentRepo := &fakeCommentRepo{comments: map[uint]models.Comment{
+		1: {Base: models.Base{ID: 1}, UserID: 1, DeletedAt: gorm.DeletedAt{Time: expiredAt, Valid: true}},
+		2: {Base: models.Base{ID: 2}, UserID: 1, DeletedAt: gorm.DeletedAt{Time: recentAt, Valid: true}},
+	}}
+	userRepo := &fakeUserRepo{users: map[uint]models.User{1: {Base: models.Base{ID: 1}, StorageUsed: 610}}}
+	storageCleanupSvc := &fakeStorageCleanupSvc{}
+	trashSvc := NewTrashSvc(photoRepo, commentRepo, userRepo, storageCleanupSvc, retention)
+	photoSvc := &PhotoSvc{photoRepo: photoRepo, likeRepo: &fakeLikeRepo{}}
+
+	trash, err := trashSvc.GetTrash(1)
+	if err != nil {
+		t.Fatalf("GetTrash() error = %v", err)
+	}
+	if len(trash.Photos) != 2 || len(trash.Comments) != 2 {
+		t.Fatalf("GetTrash() = %d photos & %d comments, want 2 & 2", len(trash.Photos), len(trash.Comments))
+	}
+	if photo := trash.Photos[1]; photo.ID != 1 || !photo.PurgeAt.Equal(expiredAt.Add(retention)) {
+		t.Errorf("GetTrash() photo = %+v, want photo 1 purged at %v", photo, expiredAt.Add(retention))
+	}
+
+	photos, comments, err := trashSvc.PurgeExpired()
+	if err != nil {
+		t.Fatalf("PurgeExpired() error = %v", err)
+	}
+	if photos != 1 || comments != 1 {
+		t.Errorf("PurgeExpired() = %d photos & %d comments, want 1 & 1", photos, comments)
+	}
+	sort.Strings(storageCleanupSvc.deleted)
+	if got := storageCleanupSvc.deleted; len(got) != 2 || got[0] != "expired.jpg" || got[1] != "expired_150.jpg" {
+		t.Errorf("PurgeExpired() deleted files %v, want [expired.jpg expired_150.jpg]", got)
+	}
+	if used := userRepo.users[1].StorageUsed; used != 500 {
+		t.Errorf("storage used after PurgeExpired() = %d, want 500", used)
+	}
+
+	// purged photos are gone for good, the others can still be restored
+	if _, err := photoSvc.Restore(1, 1); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Restore() of a purged photo error = %v, want %v", err, gorm.ErrRecordNotFound)
+	}
+	if _, err := photoSvc.Restore(2, 1); err != nil {
+		t.Errorf("Restore() of a photo within the retention period error = %v", err)
+	}
+	if _, err := photoSvc.Restore(3, 1); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Restore() of a photo which isn't in the trash error = %v, want %v", err, gorm.ErrRecordNotFound)
+	}
+}
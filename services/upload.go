@@ -0,0 +1,119 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"log"
+	"strconv"
+
+	"github.com/alvinmdj/mygram-api/configs"
+	"github.com/alvinmdj/mygram-api/images"
+	"github.com/alvinmdj/mygram-api/models"
+	"github.com/alvinmdj/mygram-api/uploads"
+	"github.com/asaskevich/govalidator"
+)
+
+type UploadSvcInterface interface {
+	MaxSize() int64
+	Create(userId uint, role string, length int64, metadata map[string]string) (upload uploads.Upload, err error)
+	Get(userId uint, uploadId string) (upload uploads.Upload, err error)
+	Write(userId uint, role string, uploadId string, offset int64, chunk io.Reader) (upload uploads.Upload, err error)
+	Delete(userId uint, uploadId string) (err error)
+}
+
+type UploadSvc struct {
+	uploadStore  *uploads.FileStore
+	photoSvc     PhotoSvcInterface
+	uploadConfig configs.UploadConfig
+}
+
+func NewUploadSvc(uploadStore *uploads.FileStore, photoSvc PhotoSvcInterface, uploadConfig configs.UploadConfig) UploadSvcInterface {
+	return &UploadSvc{
+		uploadStore:  uploadStore,
+		photoSvc:     photoSvc,
+		uploadConfig: uploadConfig,
+	}
+}
+
+// MaxSize is the largest upload size of any role, the limit of the user is checked by Create
+func (u *UploadSvc) MaxSize() int64 {
+	return u.uploadConfig.MaxBytes()
+}
+
+// Create starts a resumable photo upload, metadata holds the photo input (title, caption, keep_metadata)
+func (u *UploadSvc) Create(userId uint, role string, length int64, metadata map[string]string) (upload uploads.Upload, err error) {
+	if length <= 0 {
+		return upload, errors.New("upload length is required")
+	}
+	if length > u.uploadConfig.For(role).MaxBytes {
+		return upload, ErrUploadTooLarge
+	}
+
+	// validate the photo input now so the client doesn't upload the whole file for nothing
+	photoInput := uploadPhotoInput(userId, role, metadata)
+	photoInput.PhotoURL = "placeholder"
+	if _, err = govalidator.ValidateStruct(photoInput); err != nil {
+		return
+	}
+
+	upload, err = u.uploadStore.Create(userId, length, metadata)
+	return
+}
+
+func (u *UploadSvc) Get(userId uint, uploadId string) (upload uploads.Upload, err error) {
+	upload, err = u.uploadStore.Get(uploadId)
+	if err == nil && upload.UserID != userId {
+		// uploads of other users don't exist
+		return uploads.Upload{}, uploads.ErrNotFound
+	}
+	return
+}
+
+// Write appends a chunk to an upload, the photo is created as soon as the upload is complete
+func (u *UploadSvc) Write(userId uint, role string, uploadId string, offset int64, chunk io.Reader) (upload uploads.Upload, err error) {
+	if _, err = u.Get(userId, uploadId); err != nil {
+		return
+	}
+
+	upload, err = u.uploadStore.Write(uploadId, offset, chunk, func(upload uploads.Upload, data io.Reader) (uint, error) {
+		return u.finalize(upload, role, data)
+	})
+
+	// uploads which can't become a photo are deleted, on other errors the client may retry
+	var imageErr *images.Error
+	if errors.As(err, &imageErr) {
+		u.uploadStore.Delete(uploadId)
+	}
+	return
+}
+
+// finalize feeds a complete upload into the photo pipeline (validation, metadata, variants, storage)
+func (u *UploadSvc) finalize(upload uploads.Upload, role string, data io.Reader) (photoId uint, err error) {
+	photoInput := uploadPhotoInput(upload.UserID, role, upload.Metadata)
+	photoInput.FileSize = upload.Length
+	photo, err := u.photoSvc.Create(photoInput, data)
+	if err != nil {
+		log.Printf("error creating photo from upload %v: %v", upload.ID, err)
+		return
+	}
+	return photo.ID, nil
+}
+
+func (u *UploadSvc) Delete(userId uint, uploadId string) (err error) {
+	if _, err = u.Get(userId, uploadId); err != nil {
+		return
+	}
+	err = u.uploadStore.Delete(uploadId)
+	return
+}
+
+func uploadPhotoInput(userId uint, role string, metadata map[string]string) models.PhotoCreateInput {
+	keepMetadata, _ := strconv.ParseBool(metadata["keep_metadata"])
+	return models.PhotoCreateInput{
+		Title:        metadata["title"],
+		Caption:      metadata["caption"],
+		UserID:       userId,
+		Role:         role,
+		KeepMetadata: keepMetadata,
+	}
+}
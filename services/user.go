@@ -2,24 +2,72 @@ package services
 
 import (
 	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+	"time"
 
+	"github.com/alvinmdj/mygram-api/configs"
 	"github.com/alvinmdj/mygram-api/helpers"
+	"github.com/alvinmdj/mygram-api/mailers"
 	"github.com/alvinmdj/mygram-api/models"
 	"github.com/alvinmdj/mygram-api/repositories"
+	"github.com/asaskevich/govalidator"
+	"github.com/google/uuid"
 )
 
 type UserSvcInterface interface {
 	Register(userInput models.UserRegisterInput) (user models.User, err error)
-	Login(userInput models.UserLoginInput) (token string, err error)
+	Login(userInput models.UserLoginInput) (tokens models.UserLoginOutput, err error)
+	Refresh(refreshInput models.UserRefreshInput) (tokens models.UserLoginOutput, err error)
+	Logout(userId uint, jti string, expiresAt time.Time, logoutInput models.UserLogoutInput) (err error)
+	LogoutAll(userId uint) (err error)
+	GetById(id uint) (user models.User, err error)
+	GetByUsername(username string) (user models.User, err error)
+	Update(userInput models.UserUpdateInput) (user models.User, err error)
+	Delete(id uint) (err error)
+	ChangePassword(userId uint, passwordInput models.UserChangePasswordInput) (err error)
+	ForgotPassword(forgotInput models.UserForgotPasswordInput) (err error)
+	ResetPassword(resetInput models.UserResetPasswordInput) (err error)
+	VerifyEmail(token string) (err error)
+	ResendVerification(resendInput models.UserResendVerificationInput) (err error)
+	GetAll() (users []models.User, err error)
+	UpdateRole(adminId uint, userId uint, roleInput models.UserRoleUpdateInput) (user models.User, err error)
+	Block(adminId uint, userId uint) (user models.User, err error)
+	Unblock(userId uint) (user models.User, err error)
+	GetUsage(userId uint) (usage models.UserUsageOutput, err error)
+	UpdateStorageQuota(userId uint, quotaInput models.UserQuotaUpdateInput) (user models.User, err error)
 }
 
 type UserSvc struct {
-	userRepo repositories.UserRepoInterface
+	userRepo            repositories.UserRepoInterface
+	refreshTokenRepo    repositories.RefreshTokenRepoInterface
+	tokenRevocationRepo repositories.TokenRevocationRepoInterface
+	userTokenRepo       repositories.UserTokenRepoInterface
+	mailer              mailers.Mailer
+	uploadConfig        configs.UploadConfig
+	storageCleanupSvc   StorageCleanupSvcInterface
 }
 
-func NewUserSvc(userRepo repositories.UserRepoInterface) UserSvcInterface {
+func NewUserSvc(
+	userRepo repositories.UserRepoInterface,
+	refreshTokenRepo repositories.RefreshTokenRepoInterface,
+	tokenRevocationRepo repositories.TokenRevocationRepoInterface,
+	userTokenRepo repositories.UserTokenRepoInterface,
+	mailer mailers.Mailer,
+	uploadConfig configs.UploadConfig,
+	storageCleanupSvc StorageCleanupSvcInterface,
+) UserSvcInterface {
 	return &UserSvc{
-		userRepo: userRepo,
+		userRepo:            userRepo,
+		refreshTokenRepo:    refreshTokenRepo,
+		tokenRevocationRepo: tokenRevocationRepo,
+		userTokenRepo:       userTokenRepo,
+		mailer:              mailer,
+		uploadConfig:        uploadConfig,
+		storageCleanupSvc:   storageCleanupSvc,
 	}
 }
 
@@ -32,10 +80,19 @@ func (u *UserSvc) Register(userInput models.UserRegisterInput) (user models.User
 	}
 
 	user, err = u.userRepo.Save(user)
+	if err != nil {
+		err = uniqueViolationError(err)
+		return
+	}
+
+	// the account is created either way, the user can ask for a new email
+	if err := u.sendVerificationEmail(user); err != nil {
+		log.Printf("error sending verification email to user %d: %v", user.ID, err)
+	}
 	return
 }
 
-func (u *UserSvc) Login(userInput models.UserLoginInput) (token string, err error) {
+func (u *UserSvc) Login(userInput models.UserLoginInput) (tokens models.UserLoginOutput, err error) {
 	user := models.User{
 		Email:    userInput.Email,
 		Password: userInput.Password,
@@ -52,6 +109,493 @@ func (u *UserSvc) Login(userInput models.UserLoginInput) (token string, err erro
 		return
 	}
 
-	token = helpers.GenerateToken(user.ID, user.Email)
+	if user.BlockedAt != nil {
+		err = ErrUserBlocked
+		return
+	}
+
+	// unverified users can sign in (but not create content) unless disabled by config
+	if user.VerifiedAt == nil && !helpers.GetEnvBool("ALLOW_UNVERIFIED_LOGIN", true) {
+		err = ErrEmailNotVerified
+		return
+	}
+
+	// every login starts a new refresh token family
+	tokens, err = u.issueTokens(user, uuid.New().String())
+	return
+}
+
+func (u *UserSvc) Refresh(refreshInput models.UserRefreshInput) (tokens models.UserLoginOutput, err error) {
+	errInvalid := errors.New("invalid or expired refresh token")
+
+	if _, err = govalidator.ValidateStruct(refreshInput); err != nil {
+		return
+	}
+
+	refreshToken, err := u.refreshTokenRepo.FindByHash(helpers.HashToken(refreshInput.RefreshToken))
+	if err != nil {
+		err = errInvalid
+		return
+	}
+
+	// a rotated token is presented again: it may have been stolen,
+	// so revoke every token of the family and force a new sign in
+	if refreshToken.RevokedAt != nil {
+		if err = u.refreshTokenRepo.RevokeFamily(refreshToken.FamilyID); err != nil {
+			return
+		}
+		err = errors.New("refresh token reuse detected, sign in again")
+		return
+	}
+
+	if time.Now().After(refreshToken.ExpiresAt) {
+		err = errInvalid
+		return
+	}
+
+	// rotate: revoke the presented token, only one concurrent request can win
+	revoked, err := u.refreshTokenRepo.Revoke(refreshToken)
+	if err != nil {
+		return
+	}
+	if !revoked {
+		if err = u.refreshTokenRepo.RevokeFamily(refreshToken.FamilyID); err != nil {
+			return
+		}
+		err = errors.New("refresh token reuse detected, sign in again")
+		return
+	}
+
+	user, err := u.userRepo.FindById(refreshToken.UserID)
+	if err != nil {
+		err = errInvalid
+		return
+	}
+	if user.BlockedAt != nil {
+		err = ErrUserBlocked
+		return
+	}
+
+	tokens, err = u.issueTokens(user, refreshToken.FamilyID)
+	return
+}
+
+func (u *UserSvc) Logout(userId uint, jti string, expiresAt time.Time, logoutInput models.UserLogoutInput) (err error) {
+	// revoke the access token used for this request until it expires
+	if err = u.tokenRevocationRepo.RevokeToken(jti, expiresAt); err != nil {
+		return
+	}
+
+	if logoutInput.RefreshToken == "" {
+		return
+	}
+
+	// revoke the session's refresh token family, ignore tokens of other users
+	refreshToken, err := u.refreshTokenRepo.FindByHash(helpers.HashToken(logoutInput.RefreshToken))
+	if err != nil || refreshToken.UserID != userId {
+		return nil
+	}
+	err = u.refreshTokenRepo.RevokeFamily(refreshToken.FamilyID)
+	return
+}
+
+func (u *UserSvc) LogoutAll(userId uint) (err error) {
+	if err = u.refreshTokenRepo.RevokeAllByUserId(userId); err != nil {
+		return
+	}
+
+	// iat claims have a precision of seconds, so every token issued up to this second is revoked
+	err = u.tokenRevocationRepo.RevokeUserTokens(userId, time.Now().Truncate(time.Second))
+	return
+}
+
+func (u *UserSvc) GetById(id uint) (user models.User, err error) {
+	user, err = u.userRepo.FindById(id)
+	return
+}
+
+func (u *UserSvc) GetByUsername(username string) (user models.User, err error) {
+	user, err = u.userRepo.FindByUsername(username)
+	return
+}
+
+func (u *UserSvc) Update(userInput models.UserUpdateInput) (user models.User, err error) {
+	current, err := u.userRepo.FindById(userInput.ID)
+	if err != nil {
+		return
+	}
+
+	user = models.User{
+		Base:     models.Base{ID: userInput.ID},
+		Username: userInput.Username,
+		Email:    userInput.Email,
+		Age:      userInput.Age,
+	}
+
+	user, err = u.userRepo.Update(user)
+	if err != nil {
+		err = uniqueViolationError(err)
+		return
+	}
+
+	// the new email has to be verified again, links sent to the old one are no longer valid
+	if user.Email != current.Email {
+		if err = u.userTokenRepo.DeleteUnusedByUserId(models.UserTokenEmailVerification, user.ID); err != nil {
+			return
+		}
+		if err := u.sendVerificationEmail(user); err != nil {
+			log.Printf("error sending verification email to user %d: %v", user.ID, err)
+		}
+	}
+	return
+}
+
+func (u *UserSvc) Delete(id uint) (err error) {
+	user, err := u.userRepo.FindById(id)
+	if err != nil {
+		return
+	}
+
+	// the photos, comments & social medias of the user are deleted with the account
+	photos, err := u.userRepo.Delete(user)
+	if err != nil {
+		return
+	}
+
+	// the rows are gone, failed deletions of the files are retried in the background
+	for _, photo := range photos {
+		u.storageCleanupSvc.DeleteFiles(photoStorageKeys(photo))
+	}
+
+	// the account is gone, so are its sessions
+	err = u.tokenRevocationRepo.RevokeUserTokens(id, time.Now().Truncate(time.Second))
+	return
+}
+
+func (u *UserSvc) ChangePassword(userId uint, passwordInput models.UserChangePasswordInput) (err error) {
+	if _, err = govalidator.ValidateStruct(passwordInput); err != nil {
+		return
+	}
+
+	user, err := u.userRepo.FindById(userId)
+	if err != nil {
+		return
+	}
+
+	if isEqual := helpers.CompareHash([]byte(user.Password), []byte(passwordInput.CurrentPassword)); !isEqual {
+		err = errors.New("current password is incorrect")
+		return
+	}
+
+	err = u.setPassword(user, passwordInput.NewPassword)
+	return
+}
+
+func (u *UserSvc) ForgotPassword(forgotInput models.UserForgotPasswordInput) (err error) {
+	if _, err = govalidator.ValidateStruct(forgotInput); err != nil {
+		return
+	}
+
+	// don't tell the client whether the email is registered
+	user, err := u.userRepo.FindByEmail(models.User{Email: forgotInput.Email})
+	if err != nil {
+		return nil
+	}
+
+	ttl := helpers.GetEnvDuration("PASSWORD_RESET_TTL", time.Hour)
+	token, err := u.createUserToken(user, models.UserTokenPasswordReset, ttl)
+	if err != nil {
+		return
+	}
+
+	err = u.mailer.Send(mailers.Mail{
+		To:      user.Email,
+		Subject: "Reset your MyGram password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nUse the link below to reset your password, it expires in %s:\n%s\n\n"+
+				"If you didn't request a password reset, you can ignore this email.",
+			user.Username, ttl, appUrl("/reset-password", token),
+		),
+	})
+	return
+}
+
+func (u *UserSvc) ResetPassword(resetInput models.UserResetPasswordInput) (err error) {
+	if _, err = govalidator.ValidateStruct(resetInput); err != nil {
+		return
+	}
+
+	userToken, err := u.consumeUserToken(models.UserTokenPasswordReset, resetInput.Token)
+	if err != nil {
+		return
+	}
+
+	user, err := u.userRepo.FindById(userToken.UserID)
+	if err != nil {
+		return
+	}
+
+	if err = u.setPassword(user, resetInput.NewPassword); err != nil {
+		return
+	}
+
+	// whoever requested the reset may not be the one logged in, end every session
+	err = u.LogoutAll(user.ID)
 	return
 }
+
+func (u *UserSvc) VerifyEmail(token string) (err error) {
+	userToken, err := u.consumeUserToken(models.UserTokenEmailVerification, token)
+	if err != nil {
+		return
+	}
+
+	err = u.userRepo.MarkVerified(models.User{Base: models.Base{ID: userToken.UserID}})
+	return
+}
+
+func (u *UserSvc) ResendVerification(resendInput models.UserResendVerificationInput) (err error) {
+	if _, err = govalidator.ValidateStruct(resendInput); err != nil {
+		return
+	}
+
+	// don't tell the client whether the email is registered or already verified
+	user, err := u.userRepo.FindByEmail(models.User{Email: resendInput.Email})
+	if err != nil || user.VerifiedAt != nil {
+		return nil
+	}
+
+	// only the latest verification link is valid
+	if err = u.userTokenRepo.DeleteUnusedByUserId(models.UserTokenEmailVerification, user.ID); err != nil {
+		return
+	}
+
+	err = u.sendVerificationEmail(user)
+	return
+}
+
+func (u *UserSvc) sendVerificationEmail(user models.User) (err error) {
+	ttl := helpers.GetEnvDuration("EMAIL_VERIFICATION_TTL", 24*time.Hour)
+	token, err := u.createUserToken(user, models.UserTokenEmailVerification, ttl)
+	if err != nil {
+		return
+	}
+
+	err = u.mailer.Send(mailers.Mail{
+		To:      user.Email,
+		Subject: "Verify your MyGram email",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nWelcome to MyGram! Use the link below to verify your email, it expires in %s:\n%s",
+			user.Username, ttl, appUrl("/api/v1/users/verify", token),
+		),
+	})
+	return
+}
+
+// setPassword hashes and stores a new password and invalidates outstanding reset tokens
+func (u *UserSvc) setPassword(user models.User, password string) (err error) {
+	user.Password, err = helpers.HashPassword(password)
+	if err != nil {
+		return
+	}
+
+	if err = u.userRepo.UpdatePassword(user); err != nil {
+		return
+	}
+
+	err = u.userTokenRepo.DeleteUnusedByUserId(models.UserTokenPasswordReset, user.ID)
+	return
+}
+
+// createUserToken stores a new single-use token and returns its plain value
+func (u *UserSvc) createUserToken(user models.User, purpose string, ttl time.Duration) (token string, err error) {
+	token, err = helpers.GenerateOpaqueToken()
+	if err != nil {
+		return
+	}
+
+	userToken := models.UserToken{
+		Purpose:   purpose,
+		TokenHash: helpers.HashToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+		UserID:    user.ID,
+	}
+	_, err = u.userTokenRepo.Save(userToken)
+	return
+}
+
+// consumeUserToken checks and marks a single-use token as used
+func (u *UserSvc) consumeUserToken(purpose string, token string) (userToken models.UserToken, err error) {
+	errInvalid := errors.New("invalid or expired token")
+
+	userToken, err = u.userTokenRepo.FindByHash(purpose, helpers.HashToken(token))
+	if err != nil || userToken.UsedAt != nil || time.Now().After(userToken.ExpiresAt) {
+		err = errInvalid
+		return
+	}
+
+	used, err := u.userTokenRepo.MarkUsed(userToken)
+	if err != nil {
+		return
+	}
+	if !used {
+		err = errInvalid
+	}
+	return
+}
+
+// appUrl builds a link to the client application with the token as query param
+func appUrl(path string, token string) string {
+	return appBaseUrl() + path + "?token=" + url.QueryEscape(token)
+}
+
+func appBaseUrl() string {
+	baseUrl := os.Getenv("APP_URL")
+	if baseUrl == "" {
+		baseUrl = "http://localhost:8080"
+	}
+	return strings.TrimSuffix(baseUrl, "/")
+}
+
+func (u *UserSvc) GetAll() (users []models.User, err error) {
+	users, err = u.userRepo.FindAll()
+	return
+}
+
+func (u *UserSvc) UpdateRole(adminId uint, userId uint, roleInput models.UserRoleUpdateInput) (user models.User, err error) {
+	if _, err = govalidator.ValidateStruct(roleInput); err != nil {
+		return
+	}
+
+	// prevent admins from locking themselves out
+	if adminId == userId {
+		err = ErrSelfManagement
+		return
+	}
+
+	user, err = u.userRepo.FindById(userId)
+	if err != nil {
+		return
+	}
+
+	user.Role = roleInput.Role
+	if err = u.userRepo.UpdateRole(user); err != nil {
+		return
+	}
+
+	// the role is carried in the access token, force a new sign in so it takes effect
+	err = u.LogoutAll(user.ID)
+	return
+}
+
+func (u *UserSvc) Block(adminId uint, userId uint) (user models.User, err error) {
+	if adminId == userId {
+		err = ErrSelfManagement
+		return
+	}
+
+	user, err = u.userRepo.FindById(userId)
+	if err != nil || user.BlockedAt != nil {
+		return
+	}
+
+	now := time.Now()
+	user.BlockedAt = &now
+	if err = u.userRepo.UpdateBlockedAt(user); err != nil {
+		return
+	}
+
+	err = u.LogoutAll(user.ID)
+	return
+}
+
+func (u *UserSvc) Unblock(userId uint) (user models.User, err error) {
+	user, err = u.userRepo.FindById(userId)
+	if err != nil {
+		return
+	}
+
+	user.BlockedAt = nil
+	err = u.userRepo.UpdateBlockedAt(user)
+	return
+}
+
+// uniqueViolationError converts unique index violations on users into readable errors
+func uniqueViolationError(err error) error {
+	switch constraint, _ := helpers.IsUniqueViolation(err); constraint {
+	case "idx_users_username":
+		return ErrUsernameTaken
+	case "idx_users_email":
+		return ErrEmailTaken
+	}
+	return err
+}
+
+// issueTokens creates a new access token and stores a new refresh token in the given family
+func (u *UserSvc) issueTokens(user models.User, familyId string) (tokens models.UserLoginOutput, err error) {
+	refreshTokenValue, err := helpers.GenerateOpaqueToken()
+	if err != nil {
+		return
+	}
+
+	refreshToken := models.RefreshToken{
+		TokenHash: helpers.HashToken(refreshTokenValue),
+		FamilyID:  familyId,
+		ExpiresAt: time.Now().Add(helpers.RefreshTokenTTL()),
+		UserID:    user.ID,
+	}
+	if _, err = u.refreshTokenRepo.Save(refreshToken); err != nil {
+		return
+	}
+
+	accessToken, expiresAt := helpers.GenerateToken(user.ID, user.Email, user.Role)
+
+	tokens = models.UserLoginOutput{
+		Token:        accessToken,
+		RefreshToken: refreshTokenValue,
+		ExpiresIn:    int64(time.Until(expiresAt).Seconds()),
+	}
+	return
+}
+
+// GetUsage returns the storage used by the photos of a user and the quota of the user
+func (u *UserSvc) GetUsage(userId uint) (usage models.UserUsageOutput, err error) {
+	user, err := u.userRepo.FindById(userId)
+	if err != nil {
+		return
+	}
+
+	usage = models.UserUsageOutput{
+		StorageUsed:  user.StorageUsed,
+		StorageQuota: u.storageQuota(user),
+	}
+	if usage.StorageQuota > usage.StorageUsed {
+		usage.StorageAvailable = usage.StorageQuota - usage.StorageUsed
+	}
+	return
+}
+
+// UpdateStorageQuota sets the storage quota of a user, photos stored before aren't affected by a lower quota
+func (u *UserSvc) UpdateStorageQuota(userId uint, quotaInput models.UserQuotaUpdateInput) (user models.User, err error) {
+	if quotaInput.StorageQuota != nil && *quotaInput.StorageQuota < 0 {
+		err = errors.New("storage quota can't be negative")
+		return
+	}
+
+	user, err = u.userRepo.FindById(userId)
+	if err != nil {
+		return
+	}
+
+	user.StorageQuota = quotaInput.StorageQuota
+	err = u.userRepo.UpdateStorageQuota(user)
+	return
+}
+
+// storageQuota returns the quota of a user, their own or the default quota of their role
+func (u *UserSvc) storageQuota(user models.User) int64 {
+	if user.StorageQuota != nil {
+		return *user.StorageQuota
+	}
+	return u.uploadConfig.For(user.Role).StorageQuota
+}
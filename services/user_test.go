@@ -0,0 +1,191 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alvinmdj/mygram-api/helpers"
+	"github.com/alvinmdj/mygram-api/models"
+	"github.com/alvinmdj/mygram-api/repositories"
+	"gorm.io/gorm"
+)
+
+// fakeRefreshTokenRepo keeps the refresh tokens in memory
+type fakeRefreshTokenRepo struct {
+	tokens []models.RefreshToken
+}
+
+func (f *fakeRefreshTokenRepo) Save(refreshToken models.RefreshToken) (models.RefreshToken, error) {
+	refreshToken.ID = uint(len(f.tokens) + 1)
+	f.tokens = append(f.tokens, refreshToken)
+	return refreshToken, nil
+}
+
+func (f *fakeRefreshTokenRepo) FindByHash(tokenHash string) (models.RefreshToken, error) {
+	for _, token := range f.tokens {
+		if token.TokenHash == tokenHash {
+			return token, nil
+		}
+	}
+	return models.RefreshToken{}, gorm.ErrRecordNotFound
+}
+
+func (f *fakeRefreshTokenRepo) Revoke(refreshToken models.RefreshToken) (bool, error) {
+	for i := range f.tokens {
+		if f.tokens[i].ID == refreshToken.ID && f.tokens[i].RevokedAt == nil {
+			now := time.Now()
+			f.tokens[i].RevokedAt = &now
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeRefreshTokenRepo) RevokeFamily(familyId string) error {
+	f.revokeWhere(func(token models.RefreshToken) bool { return token.FamilyID == familyId })
+	return nil
+}
+
+func (f *fakeRefreshTokenRepo) RevokeAllByUserId(userId uint) error {
+	f.revokeWhere(func(token models.RefreshToken) bool { return token.UserID == userId })
+	return nil
+}
+
+func (f *fakeRefreshTokenRepo) revokeWhere(match func(token models.RefreshToken) bool) {
+	now := time.Now()
+	for i := range f.tokens {
+		if match(f.tokens[i]) && f.tokens[i].RevokedAt == nil {
+			f.tokens[i].RevokedAt = &now
+		}
+	}
+}
+
+// active returns the tokens of a family which aren't revoked
+func (f *fakeRefreshTokenRepo) active(familyId string) (active int) {
+	for _, token := range f.tokens {
+		if token.FamilyID == familyId && token.RevokedAt == nil {
+			active++
+		}
+	}
+	return
+}
+
+// fakeUserRepo finds the users of a map, the other methods aren't used by the tests
+type fakeUserRepo struct {
+	repositories.UserRepoInterface
+	users map[uint]models.User
+}
+
+func (f *fakeUserRepo) FindById(id uint) (models.User, error) {
+	user, ok := f.users[id]
+	if !ok {
+		return user, gorm.ErrRecordNotFound
+	}
+	return user, nil
+}
+
+func (f *fakeUserRepo) AddStorageUsed(userId uint, delta int64, defaultQuota int64) (bool, error) {
+	user := f.users[userId]
+	user.StorageUsed += delta
+	f.users[userId] = user
+	return true, nil
+}
+
+// newRefreshTestSvc returns a user service with one signed in user and the refresh token of the session
+func newRefreshTestSvc(t *testing.T) (*UserSvc, *fakeRefreshTokenRepo, string) {
+	t.Setenv("JWT_SECRET", "test secret")
+
+	refreshTokenRepo := &fakeRefreshTokenRepo{}
+	userSvc := &UserSvc{
+		userRepo:         &fakeUserRepo{users: map[uint]models.User{1: {Base: models.Base{ID: 1}, Email: "user@example.com", Role: models.RoleUser}}},
+		refreshTokenRepo: refreshTokenRepo,
+	}
+
+	tokens, err := userSvc.issueTokens(models.User{Base: models.Base{ID: 1}, Email: "user@example.com"}, "family")
+	if err != nil {
+		t.Fatalf("issueTokens() error = %v", err)
+	}
+	return userSvc, refreshTokenRepo, tokens.RefreshToken
+}
+
+func TestRefreshRotation(t *testing.T) {
+	userSvc, refreshTokenRepo, refreshToken := newRefreshTestSvc(t)
+
+	tokens, err := userSvc.Refresh(models.UserRefreshInput{RefreshToken: refreshToken})
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if tokens.Token == "" || tokens.RefreshToken == "" || tokens.RefreshToken == refreshToken {
+		t.Fatalf("Refresh() = %+v, want a new access & refresh token", tokens)
+	}
+
+	rotated, _ := refreshTokenRepo.FindByHash(helpers.HashToken(refreshToken))
+	if rotated.RevokedAt == nil {
+		t.Error("Refresh() didn't revoke the presented token")
+	}
+	issued, _ := refreshTokenRepo.FindByHash(helpers.HashToken(tokens.RefreshToken))
+	if issued.FamilyID != "family" || issued.RevokedAt != nil {
+		t.Errorf("Refresh() issued %+v, want an active token of the same family", issued)
+	}
+
+	// the new token rotates again
+	if _, err := userSvc.Refresh(models.UserRefreshInput{RefreshToken: tokens.RefreshToken}); err != nil {
+		t.Errorf("Refresh() with the rotated token error = %v", err)
+	}
+}
+
+func TestRefreshReuseRevokesFamily(t *testing.T) {
+	userSvc, refreshTokenRepo, refreshToken := newRefreshTestSvc(t)
+
+	// a second session of the user isn't affected by the reuse
+	if _, err := userSvc.issueTokens(models.User{Base: models.Base{ID: 1}}, "other family"); err != nil {
+		t.Fatal(err)
+	}
+
+	tokens, err := userSvc.Refresh(models.UserRefreshInput{RefreshToken: refreshToken})
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	// the old token is presented again, e.g. by an attacker who stole it
+	if _, err := userSvc.Refresh(models.UserRefreshInput{RefreshToken: refreshToken}); err == nil {
+		t.Fatal("Refresh() with a rotated token error = nil, want reuse detected")
+	}
+	if active := refreshTokenRepo.active("family"); active != 0 {
+		t.Errorf("%d tokens of the family are still active after a reuse, want 0", active)
+	}
+	if _, err := userSvc.Refresh(models.UserRefreshInput{RefreshToken: tokens.RefreshToken}); err == nil {
+		t.Error("Refresh() with the latest token of a revoked family error = nil")
+	}
+	if active := refreshTokenRepo.active("other family"); active != 1 {
+		t.Errorf("%d tokens of the other family are active, want 1", active)
+	}
+}
+
+func TestRefreshInvalid(t *testing.T) {
+	userSvc, refreshTokenRepo, refreshToken := newRefreshTestSvc(t)
+
+	if _, err := userSvc.Refresh(models.UserRefreshInput{RefreshToken: "unknown"}); err == nil {
+		t.Error("Refresh() with an unknown token error = nil")
+	}
+
+	refreshTokenRepo.tokens[0].ExpiresAt = time.Now().Add(-time.Second)
+	_, err := userSvc.Refresh(models.UserRefreshInput{RefreshToken: refreshToken})
+	if err == nil || err.Error() != "invalid or expired refresh token" {
+		t.Errorf("Refresh() with an expired token error = %v", err)
+	}
+	if refreshTokenRepo.tokens[0].RevokedAt != nil {
+		t.Error("Refresh() rotated an expired token")
+	}
+}
+
+func TestRefreshBlockedUser(t *testing.T) {
+	userSvc, _, refreshToken := newRefreshTestSvc(t)
+
+	blockedAt := time.Now()
+	userSvc.userRepo.(*fakeUserRepo).users[1] = models.User{Base: models.Base{ID: 1}, BlockedAt: &blockedAt}
+	if _, err := userSvc.Refresh(models.UserRefreshInput{RefreshToken: refreshToken}); !errors.Is(err, ErrUserBlocked) {
+		t.Errorf("Refresh() of a blocked user error = %v, want %v", err, ErrUserBlocked)
+	}
+}
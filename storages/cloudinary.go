@@ -0,0 +1,153 @@
+package storages
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/cloudinary/cloudinary-go/v2"
+	"github.com/cloudinary/cloudinary-go/v2/api"
+	"github.com/cloudinary/cloudinary-go/v2/api/admin"
+	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
+)
+
+type CloudinaryStorage struct {
+	cld *cloudinary.Cloudinary
+}
+
+func NewCloudinaryStorage(cloudName, apiKey, apiSecret string) (Storage, error) {
+	cld, err := cloudinary.NewFromParams(cloudName, apiKey, apiSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CloudinaryStorage{
+		cld: cld,
+	}, nil
+}
+
+// publicId returns the cloudinary public id of a key: folder-name/file-name without extension,
+// cloudinary stores the format separately
+func publicId(key string) string {
+	return strings.TrimSuffix(key, path.Ext(key))
+}
+
+func (cs *CloudinaryStorage) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) (err error) {
+	resp, err := cs.cld.Upload.Upload(ctx, body, uploader.UploadParams{
+		PublicID: publicId(key),
+	})
+	if err == nil && resp.Error.Message != "" {
+		err = errors.New(resp.Error.Message)
+	}
+	if err != nil {
+		log.Printf("error uploading file to cloudinary: %v", err.Error())
+	}
+	return
+}
+
+// Get downloads the original file from its public delivery url
+func (cs *CloudinaryStorage) Get(ctx context.Context, key string) (body io.ReadCloser, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, cs.URL(key), nil)
+	if err != nil {
+		return
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("cloudinary get '%v': %v", key, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+func (cs *CloudinaryStorage) Delete(ctx context.Context, key string) (err error) {
+	resp, err := cs.cld.Upload.Destroy(ctx, uploader.DestroyParams{
+		PublicID: publicId(key),
+	})
+	if err == nil && resp.Error.Message != "" {
+		err = errors.New(resp.Error.Message)
+	}
+	if err != nil {
+		log.Printf("error trying to delete file '%v' from cloudinary: %v", key, err.Error())
+	}
+	return
+}
+
+func (cs *CloudinaryStorage) URL(key string) string {
+	// example url: https://res.cloudinary.com/xxx/image/upload/photos/file-name.png
+	image, err := cs.cld.Image(key)
+	if err != nil {
+		return ""
+	}
+	url, _ := image.String()
+	return url
+}
+
+func (cs *CloudinaryStorage) Stat(ctx context.Context, key string) (info ObjectInfo, err error) {
+	resp, err := cs.cld.Admin.Asset(ctx, admin.AssetParams{
+		PublicID: publicId(key),
+	})
+	if err != nil {
+		return
+	}
+	if resp.Error.Message != "" {
+		return info, ErrNotFound
+	}
+
+	info = ObjectInfo{
+		Key:          key,
+		Size:         int64(resp.Bytes),
+		ContentType:  "image/" + resp.Format,
+		LastModified: resp.CreatedAt,
+	}
+	return
+}
+
+// List lists the uploaded images page by page, keys are built from the public id & format
+func (cs *CloudinaryStorage) List(ctx context.Context, prefix string, fn func(info ObjectInfo) error) (err error) {
+	nextCursor := ""
+	for {
+		resp, err := cs.cld.Admin.Assets(ctx, admin.AssetsParams{
+			AssetType:    api.Image,
+			DeliveryType: "upload",
+			Prefix:       prefix,
+			MaxResults:   500,
+			NextCursor:   nextCursor,
+		})
+		if err != nil {
+			return err
+		}
+		if resp.Error.Message != "" {
+			return errors.New(resp.Error.Message)
+		}
+
+		for _, asset := range resp.Assets {
+			err = fn(ObjectInfo{
+				Key:          asset.PublicID + "." + asset.Format,
+				Size:         int64(asset.Bytes),
+				ContentType:  "image/" + asset.Format,
+				LastModified: asset.CreatedAt,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if resp.NextCursor == "" {
+			return nil
+		}
+		nextCursor = resp.NextCursor
+	}
+}
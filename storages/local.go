@@ -0,0 +1,229 @@
+package storages
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alvinmdj/mygram-api/helpers"
+)
+
+// LocalStorage stores files on the local filesystem, they are served by the app under URLPrefix
+type LocalStorage struct {
+	Dir       string
+	URLPrefix string
+}
+
+func NewLocalStorage(dir string, urlPrefix string) (Storage, error) {
+	if dir == "" {
+		dir = "data"
+	}
+	if urlPrefix == "" {
+		urlPrefix = "/uploads"
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &LocalStorage{
+		Dir:       dir,
+		URLPrefix: strings.TrimSuffix(urlPrefix, "/"),
+	}, nil
+}
+
+// path returns the file path of a key, keys can't escape the storage directory
+func (l *LocalStorage) path(key string) (string, error) {
+	cleanKey := path.Clean("/" + key)
+	if cleanKey == "/" || strings.Contains(key, "..") {
+		return "", errors.New("invalid storage key")
+	}
+	return filepath.Join(l.Dir, filepath.FromSlash(cleanKey)), nil
+}
+
+func (l *LocalStorage) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) (err error) {
+	filePath, err := l.path(key)
+	if err != nil {
+		return
+	}
+
+	if err = os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return
+	}
+
+	// write to a temporary file first so readers never see partial files
+	tmpFile, err := os.CreateTemp(filepath.Dir(filePath), ".upload-*")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err = io.Copy(tmpFile, body); err != nil {
+		tmpFile.Close()
+		log.Printf("error writing file '%v': %v", key, err.Error())
+		return
+	}
+	if err = tmpFile.Close(); err != nil {
+		return
+	}
+
+	err = os.Rename(tmpFile.Name(), filePath)
+	return
+}
+
+func (l *LocalStorage) Get(ctx context.Context, key string) (body io.ReadCloser, err error) {
+	filePath, err := l.path(key)
+	if err != nil {
+		return
+	}
+
+	body, err = os.Open(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return
+}
+
+func (l *LocalStorage) Delete(ctx context.Context, key string) (err error) {
+	filePath, err := l.path(key)
+	if err != nil {
+		return
+	}
+
+	err = os.Remove(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		log.Printf("error trying to delete file '%v': %v", key, err.Error())
+	}
+	return
+}
+
+func (l *LocalStorage) URL(key string) string {
+	return l.URLPrefix + "/" + strings.TrimPrefix(key, "/")
+}
+
+func (l *LocalStorage) Stat(ctx context.Context, key string) (info ObjectInfo, err error) {
+	filePath, err := l.path(key)
+	if err != nil {
+		return
+	}
+
+	fileInfo, err := os.Stat(filePath)
+	if errors.Is(err, os.ErrNotExist) || (err == nil && fileInfo.IsDir()) {
+		return info, ErrNotFound
+	}
+	if err != nil {
+		return
+	}
+
+	info = ObjectInfo{
+		Key:          key,
+		Size:         fileInfo.Size(),
+		ContentType:  mime.TypeByExtension(filepath.Ext(key)),
+		LastModified: fileInfo.ModTime(),
+	}
+	return
+}
+
+func (l *LocalStorage) List(ctx context.Context, prefix string, fn func(info ObjectInfo) error) (err error) {
+	err = filepath.WalkDir(l.Dir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		// skip unfinished uploads (see Put)
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".upload-") {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(l.Dir, filePath)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(relPath)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		fileInfo, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		return fn(ObjectInfo{
+			Key:          key,
+			Size:         fileInfo.Size(),
+			ContentType:  mime.TypeByExtension(path.Ext(key)),
+			LastModified: fileInfo.ModTime(),
+		})
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return
+}
+
+// presignSignature signs a direct upload: the key, its size, content type & expiry
+func presignSignature(key string, contentType string, size int64, expires int64) string {
+	mac := hmac.New(sha256.New, helpers.SigningKey("LOCAL_STORAGE_SIGNING_KEY", "local storage upload"))
+	mac.Write([]byte(fmt.Sprintf("PUT\n%v\n%v\n%d\n%d", key, contentType, size, expires)))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// PresignPut returns a signed url of the app itself (see VerifyPresignedPut), the local storage
+// lives on the app server so the file still goes through it
+func (l *LocalStorage) PresignPut(ctx context.Context, key string, contentType string, size int64, expires time.Duration) (upload PresignedUpload, err error) {
+	if _, err = l.path(key); err != nil {
+		return
+	}
+
+	expiresAt := time.Now().Add(expires)
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expiresAt.Unix(), 10))
+	query.Set("sig", presignSignature(key, contentType, size, expiresAt.Unix()))
+
+	baseUrl := os.Getenv("APP_URL")
+	if baseUrl == "" {
+		baseUrl = "http://localhost:8080"
+	}
+
+	upload = PresignedUpload{
+		URL:    strings.TrimSuffix(baseUrl, "/") + l.URL(key) + "?" + query.Encode(),
+		Method: http.MethodPut,
+		Headers: map[string]string{
+			"Content-Type":   contentType,
+			"Content-Length": strconv.FormatInt(size, 10),
+		},
+		ExpiresAt: expiresAt,
+	}
+	return
+}
+
+// VerifyPresignedPut checks a direct upload request made with a url of PresignPut
+func (l *LocalStorage) VerifyPresignedPut(key string, query url.Values, contentType string, size int64) error {
+	expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return errors.New("upload url has expired")
+	}
+
+	expected := presignSignature(key, contentType, size, expires)
+	if !hmac.Equal([]byte(query.Get("sig")), []byte(expected)) {
+		return errors.New("invalid upload signature, the content type & length must match the requested upload")
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package storages
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrPresignNotSupported = errors.New("the storage backend doesn't support direct uploads")
+
+// PresignedUpload is a request the client sends to upload a file directly to the storage
+type PresignedUpload struct {
+	URL       string
+	Method    string
+	Headers   map[string]string // headers the client must send as is
+	ExpiresAt time.Time
+}
+
+// Presigner is implemented by storages which accept direct (presigned) uploads from clients
+type Presigner interface {
+	PresignPut(ctx context.Context, key string, contentType string, size int64, expires time.Duration) (upload PresignedUpload, err error)
+}
@@ -0,0 +1,406 @@
+package storages
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// S3Config configures an S3 compatible storage (AWS S3, MinIO, ...), buckets are addressed path-style
+type S3Config struct {
+	Endpoint  string // e.g. https://s3.ap-southeast-1.amazonaws.com or http://localhost:9000
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PublicURL string // base url of public objects, defaults to endpoint/bucket
+}
+
+type S3Storage struct {
+	config   S3Config
+	endpoint *url.URL
+	client   *http.Client
+}
+
+func NewS3Storage(config S3Config) (Storage, error) {
+	if config.Endpoint == "" || config.Bucket == "" || config.AccessKey == "" || config.SecretKey == "" {
+		return nil, errors.New("S3_ENDPOINT, S3_BUCKET, S3_ACCESS_KEY and S3_SECRET_KEY are required")
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+
+	endpoint, err := url.Parse(strings.TrimSuffix(config.Endpoint, "/"))
+	if err != nil {
+		return nil, err
+	}
+
+	if config.PublicURL == "" {
+		config.PublicURL = endpoint.String() + "/" + config.Bucket
+	}
+	config.PublicURL = strings.TrimSuffix(config.PublicURL, "/")
+
+	return &S3Storage{
+		config:   config,
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: 5 * time.Minute},
+	}, nil
+}
+
+// objectPath returns the escaped path of an object: /bucket/key
+func (s *S3Storage) objectPath(key string) string {
+	segments := strings.Split(strings.TrimPrefix(key, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = uriEncode(segment)
+	}
+	return "/" + uriEncode(s.config.Bucket) + "/" + strings.Join(segments, "/")
+}
+
+func (s *S3Storage) newRequest(ctx context.Context, method string, key string, body io.Reader) (req *http.Request, err error) {
+	objectUrl := *s.endpoint
+	objectUrl.RawPath = s.objectPath(key)
+	objectUrl.Path, _ = url.PathUnescape(objectUrl.RawPath)
+
+	req, err = http.NewRequestWithContext(ctx, method, objectUrl.String(), body)
+	return
+}
+
+func (s *S3Storage) do(req *http.Request) (resp *http.Response, err error) {
+	s.sign(req, time.Now().UTC())
+
+	resp, err = s.client.Do(req)
+	if err != nil {
+		return
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("s3 %v %v: %v %s", req.Method, req.URL.Path, resp.Status, message)
+	}
+	return
+}
+
+func (s *S3Storage) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) (err error) {
+	req, err := s.newRequest(ctx, http.MethodPut, key, body)
+	if err != nil {
+		return
+	}
+	req.ContentLength = size
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		log.Printf("error uploading file '%v' to s3: %v", key, err.Error())
+		return
+	}
+	resp.Body.Close()
+	return
+}
+
+func (s *S3Storage) Get(ctx context.Context, key string) (body io.ReadCloser, err error) {
+	req, err := s.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return
+	}
+	return resp.Body, nil
+}
+
+func (s *S3Storage) Delete(ctx context.Context, key string) (err error) {
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return
+	}
+
+	resp, err := s.do(req)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		log.Printf("error trying to delete file '%v' from s3: %v", key, err.Error())
+		return
+	}
+	resp.Body.Close()
+	return
+}
+
+func (s *S3Storage) URL(key string) string {
+	return s.config.PublicURL + "/" + strings.TrimPrefix(key, "/")
+}
+
+func (s *S3Storage) Stat(ctx context.Context, key string) (info ObjectInfo, err error) {
+	req, err := s.newRequest(ctx, http.MethodHead, key, nil)
+	if err != nil {
+		return
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+
+	size, _ := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+	lastModified, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+
+	info = ObjectInfo{
+		Key:          key,
+		Size:         size,
+		ContentType:  resp.Header.Get("Content-Type"),
+		LastModified: lastModified,
+	}
+	return
+}
+
+// listResult is the response of ListObjectsV2
+type listResult struct {
+	Contents []struct {
+		Key          string
+		Size         int64
+		LastModified time.Time
+	}
+	IsTruncated           bool
+	NextContinuationToken string
+}
+
+// List lists the objects page by page (ListObjectsV2)
+func (s *S3Storage) List(ctx context.Context, prefix string, fn func(info ObjectInfo) error) (err error) {
+	continuationToken := ""
+	for {
+		req, err := s.newRequest(ctx, http.MethodGet, "", nil)
+		if err != nil {
+			return err
+		}
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", prefix)
+		if continuationToken != "" {
+			query.Set("continuation-token", continuationToken)
+		}
+		req.URL.RawQuery = canonicalQuery(query)
+
+		resp, err := s.do(req)
+		if err != nil {
+			return err
+		}
+		var result listResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		for _, object := range result.Contents {
+			err = fn(ObjectInfo{
+				Key:          object.Key,
+				Size:         object.Size,
+				LastModified: object.LastModified,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return nil
+		}
+		continuationToken = result.NextContinuationToken
+	}
+}
+
+// PresignPut returns a presigned PUT url (query string signature), the content type & length are signed
+// so the client can't upload another size or type than requested
+func (s *S3Storage) PresignPut(ctx context.Context, key string, contentType string, size int64, expires time.Duration) (upload PresignedUpload, err error) {
+	req, err := s.newRequest(ctx, http.MethodPut, key, nil)
+	if err != nil {
+		return
+	}
+
+	now := time.Now().UTC()
+	headers := map[string]string{
+		"content-length": strconv.FormatInt(size, 10),
+		"content-type":   contentType,
+	}
+	s.presign(req, headers, expires, now)
+
+	upload = PresignedUpload{
+		URL:    req.URL.String(),
+		Method: http.MethodPut,
+		Headers: map[string]string{
+			"Content-Type":   contentType,
+			"Content-Length": headers["content-length"],
+		},
+		ExpiresAt: now.Add(expires),
+	}
+	return
+}
+
+// presign adds a query string signature to the request, the host & given headers are signed
+func (s *S3Storage) presign(req *http.Request, headers map[string]string, expires time.Duration, now time.Time) {
+	signed := map[string]string{"host": req.URL.Host}
+	for name, value := range headers {
+		signed[strings.ToLower(name)] = strings.TrimSpace(value)
+	}
+	canonicalHeaders, signedHeaders := canonicalHeaders(signed)
+
+	_, scope := s.signature(now, "")
+	query := req.URL.Query()
+	query.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	query.Set("X-Amz-Credential", s.config.AccessKey+"/"+scope)
+	query.Set("X-Amz-Date", now.Format(amzDateFormat))
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expires.Seconds())))
+	query.Set("X-Amz-SignedHeaders", signedHeaders)
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(query),
+		canonicalHeaders,
+		signedHeaders,
+		unsignedPayload,
+	}, "\n")
+	signature, _ := s.signature(now, canonicalRequest)
+
+	// the query must be encoded like the canonical query, url.Values.Encode escapes spaces as "+"
+	req.URL.RawQuery = canonicalQuery(query) + "&X-Amz-Signature=" + signature
+}
+
+const (
+	amzDateFormat   = "20060102T150405Z"
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+)
+
+// sign adds an AWS signature version 4 authorization header to the request,
+// the payload isn't hashed (unless x-amz-content-sha256 is already set) so request bodies can be streamed
+func (s *S3Storage) sign(req *http.Request, now time.Time) {
+	req.Header.Set("X-Amz-Date", now.Format(amzDateFormat))
+	payloadHash := req.Header.Get("X-Amz-Content-Sha256")
+	if payloadHash == "" {
+		payloadHash = unsignedPayload
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+
+	// sign host & every x-amz-* / content-type header
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lowerName := strings.ToLower(name)
+		if strings.HasPrefix(lowerName, "x-amz-") || lowerName == "content-type" {
+			headers[lowerName] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	canonicalHeaders, signedHeaders := canonicalHeaders(headers)
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	signature, scope := s.signature(now, canonicalRequest)
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%v/%v, SignedHeaders=%v, Signature=%v",
+		s.config.AccessKey, scope, signedHeaders, signature,
+	))
+}
+
+// signature returns the signature of a canonical request and its credential scope
+func (s *S3Storage) signature(now time.Time, canonicalRequest string) (signature string, scope string) {
+	date := now.Format("20060102")
+	scope = date + "/" + s.config.Region + "/s3/aws4_request"
+
+	hashedRequest := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		now.Format(amzDateFormat),
+		scope,
+		hex.EncodeToString(hashedRequest[:]),
+	}, "\n")
+
+	key := hmacSha256([]byte("AWS4"+s.config.SecretKey), date)
+	key = hmacSha256(key, s.config.Region)
+	key = hmacSha256(key, "s3")
+	key = hmacSha256(key, "aws4_request")
+
+	signature = hex.EncodeToString(hmacSha256(key, stringToSign))
+	return
+}
+
+func hmacSha256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalHeaders returns the sorted "name:value" lines & the signed header list of lowercase headers
+func canonicalHeaders(headers map[string]string) (canonical string, signed string) {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var lines strings.Builder
+	for _, name := range names {
+		lines.WriteString(name + ":" + headers[name] + "\n")
+	}
+	return lines.String(), strings.Join(names, ";")
+}
+
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := []string{}
+	for _, key := range keys {
+		values := append([]string{}, query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, uriEncode(key)+"="+uriEncode(value))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode escapes everything except the unreserved characters, as required by signature v4
+func uriEncode(value string) string {
+	var encoded strings.Builder
+	for _, b := range []byte(value) {
+		if (b >= 'A' && b <= 'Z') || (b >= 'a' && b <= 'z') || (b >= '0' && b <= '9') ||
+			b == '-' || b == '_' || b == '.' || b == '~' {
+			encoded.WriteByte(b)
+		} else {
+			fmt.Fprintf(&encoded, "%%%02X", b)
+		}
+	}
+	return encoded.String()
+}
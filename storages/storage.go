@@ -0,0 +1,73 @@
+package storages
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"time"
+)
+
+var ErrNotFound = errors.New("object doesn't exist")
+
+// StagingPrefix is the key prefix of direct uploads, they aren't validated yet and must never be served
+const StagingPrefix = "staging/"
+
+// ObjectInfo describes a stored object
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	LastModified time.Time
+}
+
+// Storage stores uploaded files (e.g. photos) by key, keys look like "photos/<uuid>.png"
+type Storage interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) (err error)
+	Get(ctx context.Context, key string) (body io.ReadCloser, err error)
+	Delete(ctx context.Context, key string) (err error)
+	URL(key string) string
+	Stat(ctx context.Context, key string) (info ObjectInfo, err error)
+	// List calls fn for every object with a key starting with prefix, stops at the first error
+	List(ctx context.Context, prefix string, fn func(info ObjectInfo) error) (err error)
+}
+
+var storage Storage
+
+// StartStorage creates the storage backend chosen by STORAGE_DRIVER: "local", "s3" or "cloudinary" (default)
+func StartStorage() {
+	var err error
+
+	switch driver := os.Getenv("STORAGE_DRIVER"); driver {
+	case "local":
+		storage, err = NewLocalStorage(os.Getenv("LOCAL_STORAGE_DIR"), os.Getenv("LOCAL_STORAGE_URL"))
+	case "s3":
+		storage, err = NewS3Storage(S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			PublicURL: os.Getenv("S3_PUBLIC_URL"),
+		})
+	case "", "cloudinary":
+		storage, err = NewCloudinaryStorage(
+			os.Getenv("CLOUDINARY_CLOUD_NAME"),
+			os.Getenv("CLOUDINARY_API_KEY"),
+			os.Getenv("CLOUDINARY_API_SECRET"),
+		)
+	default:
+		log.Fatalf("unknown storage driver '%s'", driver)
+	}
+
+	if err != nil {
+		log.Fatal("error connecting to storage:", err.Error())
+	}
+
+	log.Println("storage connected successfully")
+}
+
+func GetStorage() Storage {
+	return storage
+}
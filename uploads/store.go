@@ -0,0 +1,254 @@
+package uploads
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNotFound       = errors.New("upload doesn't exist or has expired")
+	ErrOffsetMismatch = errors.New("upload offset doesn't match the current offset")
+	ErrTooLarge       = errors.New("chunk exceeds the upload length")
+	ErrLocked         = errors.New("upload is being written by another request")
+)
+
+// Upload is a resumable upload in progress
+type Upload struct {
+	ID        string            `json:"id"`
+	UserID    uint              `json:"user_id"`
+	Length    int64             `json:"length"`
+	Offset    int64             `json:"offset"`
+	Metadata  map[string]string `json:"metadata"`
+	PhotoID   uint              `json:"photo_id,omitempty"` // set once the upload is turned into a photo
+	CreatedAt time.Time         `json:"created_at"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+func (u Upload) Complete() bool {
+	return u.Offset == u.Length
+}
+
+// FileStore keeps uploads in a temporary directory: <id>.json (info) & <id>.bin (data),
+// uploads which aren't written to before they expire are garbage collected
+type FileStore struct {
+	dir   string
+	ttl   time.Duration
+	locks sync.Map // upload id -> *sync.Mutex
+}
+
+func NewFileStore(dir string, ttl time.Duration) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir, ttl: ttl}, nil
+}
+
+func (f *FileStore) infoPath(id string) string {
+	return filepath.Join(f.dir, id+".json")
+}
+
+func (f *FileStore) dataPath(id string) string {
+	return filepath.Join(f.dir, id+".bin")
+}
+
+// lock locks an upload for writing, concurrent writes to the same upload are rejected
+func (f *FileStore) lock(id string) (unlock func(), err error) {
+	value, _ := f.locks.LoadOrStore(id, &sync.Mutex{})
+	mu := value.(*sync.Mutex)
+	if !mu.TryLock() {
+		return nil, ErrLocked
+	}
+	return mu.Unlock, nil
+}
+
+func (f *FileStore) Create(userId uint, length int64, metadata map[string]string) (upload Upload, err error) {
+	now := time.Now()
+	upload = Upload{
+		ID:        uuid.New().String(),
+		UserID:    userId,
+		Length:    length,
+		Metadata:  metadata,
+		CreatedAt: now,
+		ExpiresAt: now.Add(f.ttl),
+	}
+
+	dataFile, err := os.OpenFile(f.dataPath(upload.ID), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return
+	}
+	dataFile.Close()
+
+	err = f.save(upload)
+	return
+}
+
+func (f *FileStore) Get(id string) (upload Upload, err error) {
+	// ids are generated uuids, anything else can't be a file of the store
+	if _, err = uuid.Parse(id); err != nil {
+		return upload, ErrNotFound
+	}
+
+	data, err := os.ReadFile(f.infoPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return upload, ErrNotFound
+	}
+	if err != nil {
+		return
+	}
+
+	// an unreadable info file means the upload can't be resumed
+	if err = json.Unmarshal(data, &upload); err != nil {
+		return upload, ErrNotFound
+	}
+	if time.Now().After(upload.ExpiresAt) {
+		return upload, ErrNotFound
+	}
+	return
+}
+
+// Write appends a chunk at offset, which must be the current offset of the upload. Once the upload is
+// complete, complete is called with its data (while the upload is still locked) to turn it into a photo.
+func (f *FileStore) Write(id string, offset int64, chunk io.Reader, complete func(upload Upload, data io.Reader) (photoId uint, err error)) (upload Upload, err error) {
+	unlock, err := f.lock(id)
+	if err != nil {
+		return
+	}
+	defer unlock()
+
+	upload, err = f.Get(id)
+	if err != nil {
+		return
+	}
+	if offset != upload.Offset {
+		return upload, ErrOffsetMismatch
+	}
+	if upload.PhotoID != 0 {
+		// finished already, nothing more can be written
+		return
+	}
+
+	dataFile, err := os.OpenFile(f.dataPath(id), os.O_RDWR, 0o600)
+	if err != nil {
+		return
+	}
+	defer dataFile.Close()
+
+	if _, err = dataFile.Seek(upload.Offset, io.SeekStart); err != nil {
+		return
+	}
+
+	// read one more byte than allowed to detect chunks that are too large,
+	// whatever was received is kept so an interrupted chunk can be resumed
+	written, copyErr := io.Copy(dataFile, io.LimitReader(chunk, upload.Length-upload.Offset+1))
+	if written > upload.Length-upload.Offset {
+		dataFile.Truncate(upload.Offset)
+		return upload, ErrTooLarge
+	}
+
+	upload.Offset += written
+	upload.ExpiresAt = time.Now().Add(f.ttl)
+	if err = f.save(upload); err != nil {
+		return
+	}
+	if copyErr != nil || !upload.Complete() {
+		return upload, copyErr
+	}
+
+	if _, err = dataFile.Seek(0, io.SeekStart); err != nil {
+		return
+	}
+	photoId, err := complete(upload, dataFile)
+	if err != nil {
+		return
+	}
+
+	// the upload info is kept until it expires so clients can look up the photo
+	upload.PhotoID = photoId
+	if err = f.save(upload); err != nil {
+		return
+	}
+	os.Remove(f.dataPath(id))
+	return
+}
+
+func (f *FileStore) Delete(id string) (err error) {
+	if _, err = uuid.Parse(id); err != nil {
+		return ErrNotFound
+	}
+
+	unlock, err := f.lock(id)
+	if err != nil {
+		return
+	}
+	defer unlock()
+
+	os.Remove(f.dataPath(id))
+	err = os.Remove(f.infoPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		err = ErrNotFound
+	}
+	f.locks.Delete(id)
+	return
+}
+
+// save writes the upload info to a temporary file first so readers never see partial files
+func (f *FileStore) save(upload Upload) (err error) {
+	data, err := json.Marshal(upload)
+	if err != nil {
+		return
+	}
+
+	tmpPath := f.infoPath(upload.ID) + ".tmp"
+	if err = os.WriteFile(tmpPath, data, 0o600); err != nil {
+		return
+	}
+	return os.Rename(tmpPath, f.infoPath(upload.ID))
+}
+
+// CollectGarbage deletes expired (abandoned) uploads, returns the number of deleted uploads
+func (f *FileStore) CollectGarbage() (deleted int) {
+	files, err := os.ReadDir(f.dir)
+	if err != nil {
+		log.Printf("error reading upload directory: %v", err)
+		return
+	}
+
+	for _, file := range files {
+		if id, isInfo := strings.CutSuffix(file.Name(), ".json"); isInfo {
+			if _, err := f.Get(id); errors.Is(err, ErrNotFound) && f.Delete(id) == nil {
+				deleted++
+			}
+			continue
+		}
+
+		// data or temporary files left without info by a crash
+		id, _, _ := strings.Cut(file.Name(), ".")
+		if _, err := os.Stat(f.infoPath(id)); !errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if info, err := file.Info(); err == nil && time.Since(info.ModTime()) > f.ttl {
+			os.Remove(filepath.Join(f.dir, file.Name()))
+		}
+	}
+	return
+}
+
+// StartGC collects garbage every interval in the background
+func (f *FileStore) StartGC(interval time.Duration) {
+	go func() {
+		for range time.Tick(interval) {
+			if deleted := f.CollectGarbage(); deleted > 0 {
+				log.Printf("deleted %d abandoned uploads", deleted)
+			}
+		}
+	}()
+}